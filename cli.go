@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/spritesheet-viewer/viewer"
+)
+
+// runHeadlessExport slices the sheet at path and writes every sprite into dir,
+// upscaled by scale and named according to names, without opening a window.
+// Existing files are only replaced or skipped when asked to; otherwise the
+// collisions are listed and nothing is written. It returns the process exit
+// code.
+func runHeadlessExport(path, dir string, margin, gridSize, scale int32, names string, overwrite, skip bool) int {
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "usage: spritesheet-viewer -export DIR [-grid N] [-margin N] [-scale N] [-names sprite|grid|index] SHEET")
+		return 2
+	}
+	if scale < 1 {
+		fmt.Fprintln(os.Stderr, "-scale must be at least 1")
+		return 2
+	}
+	naming, ok := map[string]viewer.ExportNaming{
+		"sprite": viewer.NameBySprite,
+		"grid":   viewer.NameByGrid,
+		"index":  viewer.NameByIndex,
+	}[names]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "-names must be sprite, grid or index, not %q\n", names)
+		return 2
+	}
+	if overwrite && skip {
+		fmt.Fprintln(os.Stderr, "--overwrite and --skip-existing cannot be combined")
+		return 2
+	}
+
+	rl.SetTraceLogLevel(rl.LogWarning)
+	result, err := viewer.ExportSheet(path, dir, viewer.ExportOptions{
+		Margin:       margin,
+		GridSize:     gridSize,
+		Scale:        scale,
+		Overwrite:    overwrite,
+		SkipExisting: skip,
+		Naming:       naming,
+	})
+	var collisions *viewer.CollisionError
+	if errors.As(err, &collisions) {
+		fmt.Fprintf(os.Stderr, "%d files already exist (use --overwrite or --skip-existing):\n", len(collisions.Paths))
+		for _, c := range collisions.Paths {
+			fmt.Fprintf(os.Stderr, "  %s\n", c)
+		}
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Exported %d sprites to %s (%d skipped)\n", result.Written, dir, result.Skipped)
+	return 0
+}
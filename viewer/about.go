@@ -0,0 +1,137 @@
+package viewer
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Modules whose versions the About panel lists.
+const (
+	raylibModule = "github.com/gen2brain/raylib-go/raylib"
+	beamModule   = "github.com/ztkent/beam"
+)
+
+// glVersions names the OpenGL versions rlgl reports.
+var glVersions = map[int32]string{
+	1: "OpenGL 1.1",
+	2: "OpenGL 2.1",
+	3: "OpenGL 3.3",
+	4: "OpenGL 4.3",
+	5: "OpenGL ES 2.0",
+	6: "OpenGL ES 3.0",
+}
+
+// appVersion returns the version the viewer was built as: the one the host
+// passed in, such as one set with -ldflags, or else the module and version
+// control information Go embeds in the binary.
+func (s *UIState) appVersion() string {
+	if s.version != "" {
+		return s.version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+			version += " (" + setting.Value[:12] + ")"
+		}
+	}
+	return version
+}
+
+// moduleVersion returns the version of the module at path the binary was
+// built with.
+func moduleVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != path {
+			continue
+		}
+		if dep.Replace != nil {
+			return dep.Replace.Version + " (replaced)"
+		}
+		return dep.Version
+	}
+	return "unknown"
+}
+
+// buildInfo describes the build and the graphics it runs on, one line each.
+// The lines are in English whatever the UI language, for whoever reads them
+// in a bug report.
+func (s *UIState) buildInfo() []string {
+	gl, ok := glVersions[rl.GetVersion()]
+	if !ok {
+		gl = fmt.Sprintf("OpenGL version %d", rl.GetVersion())
+	}
+	return []string{
+		"Version: " + s.appVersion(),
+		fmt.Sprintf("Go: %s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH),
+		"raylib-go: " + moduleVersion(raylibModule),
+		"beam: " + moduleVersion(beamModule),
+		"Graphics: " + gl,
+	}
+}
+
+// diagnostics returns the build info with the current file and settings, as
+// copied for a bug report.
+func (s *UIState) diagnostics() string {
+	lines := s.buildInfo()
+	lines = append(lines,
+		"File: "+s.currentFile,
+		"Settings: "+s.currentPreset().summary(),
+		fmt.Sprintf("Alpha threshold: %d, preview downscale: %dx, mipmaps: %t", s.alphaThreshold, s.previewDownscale, s.mipmaps),
+	)
+	if s.lastError != nil {
+		lines = append(lines, "Last error: "+s.lastError.text)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// copyDiagnostics puts the diagnostics on the clipboard.
+func (s *UIState) copyDiagnostics() {
+	rl.SetClipboardText(s.diagnostics())
+	s.notify(msgDiagnosticsCopied)
+}
+
+// renderAbout draws the About panel.
+func (s *UIState) renderAbout(cfg Config) rl.Rectangle {
+	lines := s.buildInfo()
+	width := int32(360)
+	for _, line := range lines {
+		width = max(width, measureText(line, 10)+20)
+	}
+	panel := rl.Rectangle{
+		X:      float32(cfg.width-width) / 2,
+		Y:      float32(cfg.headerHeight + 30),
+		Width:  float32(width),
+		Height: float32(45 + len(lines)*18 + 40),
+	}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(tr(msgAbout), int32(panel.X)+10, int32(panel.Y)+10, 15, s.theme.Text)
+
+	for i, line := range lines {
+		drawText(line, int32(panel.X)+10, int32(panel.Y)+40+int32(i)*18, 10, s.theme.Text)
+	}
+
+	buttonY := panel.Y + panel.Height - 35
+	closeWidth := buttonWidth(tr(msgClose), 90)
+	closeX := panel.X + panel.Width - 10 - closeWidth
+	copyWidth := buttonWidth(tr(msgCopyDiagnostics), 90)
+	if drawButton(rl.Rectangle{X: closeX - 10 - copyWidth, Y: buttonY, Width: copyWidth, Height: 25}, tr(msgCopyDiagnostics)) {
+		s.copyDiagnostics()
+	}
+	if drawButton(rl.Rectangle{X: closeX, Y: buttonY, Width: closeWidth, Height: 25}, tr(msgClose)) {
+		s.showAbout = false
+	}
+	return panel
+}
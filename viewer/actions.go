@@ -0,0 +1,199 @@
+package viewer
+
+import (
+	"path/filepath"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// binding is a keyboard shortcut. Modifiers must match exactly, so Ctrl+Z and
+// Ctrl+Shift+Z can be bound to different actions.
+type binding struct {
+	key   int32
+	ctrl  bool
+	shift bool
+}
+
+// keyNames gives the names of keys used in bindings that aren't printable
+// characters.
+var keyNames = map[int32]msgID{
+	rl.KeySpace: msgSpace,
+}
+
+// String returns the shortcut as shown to the user, such as "Ctrl+Shift+Z".
+func (b binding) String() string {
+	var parts []string
+	if b.ctrl {
+		parts = append(parts, tr(msgCtrl))
+	}
+	if b.shift {
+		parts = append(parts, tr(msgShift))
+	}
+	name := string(rune(b.key))
+	if id, ok := keyNames[b.key]; ok {
+		name = tr(id)
+	}
+	return strings.Join(append(parts, name), "+")
+}
+
+// pressed reports whether the shortcut was pressed this frame.
+func (b binding) pressed() bool {
+	ctrl := rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl)
+	shift := rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift)
+	return rl.IsKeyPressed(b.key) && ctrl == b.ctrl && shift == b.shift
+}
+
+// action is a user-facing command. The same list drives keyboard shortcuts
+// and the command palette, so the two can't drift apart.
+type action struct {
+	name     msgID
+	bindings []binding
+	run      func(s *UIState)
+}
+
+// shortcut returns the first key binding of the action, or "" if it has none.
+func (a action) shortcut() string {
+	if len(a.bindings) == 0 {
+		return ""
+	}
+	return a.bindings[0].String()
+}
+
+// actions is the registry of every command the viewer offers.
+var actions = []action{
+	{name: msgActOpen, bindings: []binding{{key: rl.KeyO, ctrl: true}}, run: func(s *UIState) {
+		if file := openFileDialog(); file != "" {
+			s.openChosenFile(file)
+		}
+	}},
+	{name: msgActNewTab, bindings: []binding{{key: rl.KeyT, ctrl: true}}, run: (*UIState).openInNewTab},
+	{name: msgActCloseTab, bindings: []binding{{key: rl.KeyW, ctrl: true}}, run: (*UIState).closeTab},
+	{name: msgActWatchFolder, run: (*UIState).chooseWatchFolder},
+	{name: msgActStopWatch, run: (*UIState).stopWatch},
+	{name: msgActSaveFolderDefaults, run: (*UIState).saveFolderDefaults},
+	{name: msgActEditSheet, bindings: []binding{{key: rl.KeyE, ctrl: true, shift: true}}, run: (*UIState).editSheet},
+	{name: msgActEditSprite, bindings: []binding{{key: rl.KeyE, shift: true}}, run: (*UIState).editSprite},
+	{name: msgActRenameSprites, bindings: []binding{{key: rl.KeyR, shift: true}}, run: (*UIState).openRename},
+	{name: msgActReload, run: func(s *UIState) {
+		if s.currentFile == "" {
+			s.notify(msgNoSheetToReload)
+			return
+		}
+		if s.reload() {
+			s.notify(msgReloaded, filepath.Base(s.currentFile))
+		}
+	}},
+	{name: msgActSave, bindings: []binding{{key: rl.KeyS, ctrl: true}}, run: func(s *UIState) {
+		if s.currentFile == "" {
+			s.notify(msgNothingToSave)
+			return
+		}
+		s.save()
+	}},
+	{name: msgActExportSprites, bindings: []binding{{key: rl.KeyE, ctrl: true}}, run: (*UIState).exportSprites},
+	{name: msgActExportAtlas, bindings: []binding{{key: rl.KeyJ, ctrl: true}}, run: (*UIState).exportAtlas},
+	{name: msgActExportFontStrip, run: (*UIState).exportFontStrip},
+	{name: msgActExportStrip, run: (*UIState).exportStrip},
+	{name: msgActExportContact, run: (*UIState).exportContactSheet},
+	{name: msgActExportGIFs, run: (*UIState).exportAnimations},
+	{name: msgActExportSubSheet, run: (*UIState).openSubSheet},
+	{name: msgActCompare, bindings: []binding{{key: rl.KeyD, ctrl: true}}, run: func(s *UIState) {
+		if s.sheet == nil {
+			s.notify(msgCompareNeedsSheet)
+			return
+		}
+		if file := openFileDialog(); file != "" {
+			s.compareWith(file)
+		}
+	}},
+	{name: msgActCloseCompare, run: (*UIState).closeDiff},
+	{name: msgActLoadPalette, run: (*UIState).choosePalette},
+	{name: msgActRecolorBefore, bindings: []binding{{key: rl.KeyB, shift: true}}, run: (*UIState).toggleRecolorBefore},
+	{name: msgActCloseRecolor, run: (*UIState).closeRecolor},
+	{name: msgActUndo, bindings: []binding{{key: rl.KeyZ, ctrl: true}}, run: (*UIState).undo},
+	{name: msgActRedo, bindings: []binding{{key: rl.KeyZ, ctrl: true, shift: true}, {key: rl.KeyY, ctrl: true}}, run: (*UIState).redo},
+	{name: msgActCheckUsages, run: (*UIState).checkUsagesInFile},
+	{name: msgActCheckUsagesClipboard, run: (*UIState).checkUsagesInClipboard},
+	{name: msgActCellCheck, run: (*UIState).toggleCellCheck},
+	{name: msgActMeasureGrid, bindings: []binding{{key: rl.KeyM}}, run: (*UIState).toggleMeasureGrid},
+	{name: msgActLockSlicing, run: (*UIState).toggleSlicingLock},
+	{name: msgActAbout, bindings: []binding{{key: rl.KeyF12}}, run: func(s *UIState) { s.showAbout = !s.showAbout }},
+	{name: msgActPerfOverlay, bindings: []binding{{key: rl.KeyF3}}, run: func(s *UIState) { s.showPerf = !s.showPerf }},
+	{name: msgActSheetInfo, bindings: []binding{{key: rl.KeyI, ctrl: true}}, run: (*UIState).toggleReport},
+	{name: msgActContentSizes, bindings: []binding{{key: rl.KeyH, ctrl: true}}, run: (*UIState).toggleHistogram},
+	{name: msgActShowAll, run: (*UIState).clearFilter},
+	{name: msgActToggleSettings, run: func(s *UIState) { s.showSettings = !s.showSettings }},
+	{name: msgActInspector, bindings: []binding{{key: rl.KeyI}}, run: (*UIState).toggleInspector},
+	{name: msgActToggleAnimation, bindings: []binding{{key: rl.KeyP}}, run: (*UIState).toggleAnimation},
+	{name: msgActPlayPause, bindings: []binding{{key: rl.KeySpace}}, run: func(s *UIState) {
+		if s.anim.visible {
+			s.anim.playing = !s.anim.playing
+		}
+	}},
+	{name: msgActPrevFrame, bindings: []binding{{key: rl.KeyComma}}, run: func(s *UIState) { s.withAnimation(func(a *animation) { a.scrub(-1) }) }},
+	{name: msgActNextFrame, bindings: []binding{{key: rl.KeyPeriod}}, run: func(s *UIState) { s.withAnimation(func(a *animation) { a.scrub(1) }) }},
+	{name: msgActLoopIn, bindings: []binding{{key: rl.KeyLeftBracket}}, run: func(s *UIState) { s.withAnimation((*animation).setLoopIn) }},
+	{name: msgActLoopOut, bindings: []binding{{key: rl.KeyRightBracket}}, run: func(s *UIState) { s.withAnimation((*animation).setLoopOut) }},
+	{name: msgActClearLoop, bindings: []binding{{key: rl.KeyBackSlash}}, run: func(s *UIState) { s.withAnimation((*animation).clearLoop) }},
+	{name: msgActStripView, bindings: []binding{{key: rl.KeyV}}, run: func(s *UIState) {
+		if s.viewMode == gridView {
+			s.viewMode = stripView
+		} else {
+			s.viewMode = gridView
+		}
+	}},
+	{name: msgActNativeView, bindings: []binding{{key: rl.KeyN}}, run: (*UIState).toggleNativeView},
+	{name: msgActSheetView, bindings: []binding{{key: rl.KeyG}}, run: func(s *UIState) {
+		if s.viewMode == sheetView {
+			s.viewMode = gridView
+		} else {
+			s.viewMode = sheetView
+		}
+	}},
+	{name: msgActZebra, run: func(s *UIState) { s.zebra = !s.zebra }},
+	{name: msgActLabelOverlay, bindings: []binding{{key: rl.KeyL}}, run: func(s *UIState) { s.labelOverlay = !s.labelOverlay }},
+	{name: msgActTrueSize, run: func(s *UIState) { s.trueSize = !s.trueSize }},
+	{name: msgActSnapRows, run: func(s *UIState) { s.snapRows = !s.snapRows }},
+	{name: msgActGroupPrefix, run: func(s *UIState) { s.setGroupByPrefix(!s.groupPrefix) }},
+	{name: msgActRightToLeft, run: (*UIState).toggleRightToLeft},
+	{name: msgActPin, bindings: []binding{{key: rl.KeyK}}, run: (*UIState).togglePins},
+	{name: msgActHighContrast, run: func(s *UIState) { s.setTheme(!s.highContrast, s.colorblind) }},
+	{name: msgActColorblind, run: func(s *UIState) { s.setTheme(s.highContrast, !s.colorblind) }},
+	{name: msgActCopyRects, bindings: []binding{{key: rl.KeyC, ctrl: true, shift: true}}, run: (*UIState).copySpriteRects},
+	{name: msgActCopyIndices, run: (*UIState).copySpriteIndices},
+	{name: msgActGuessBackground, run: (*UIState).guessBackground},
+	{name: msgActSilhouettes, run: func(s *UIState) { s.silhouette = !s.silhouette }},
+	{name: msgActHexCoords, run: func(s *UIState) { s.hexCoords = !s.hexCoords }},
+	{name: msgActExportNaming, run: func(s *UIState) {
+		s.cycleExportNaming()
+		s.notify(msgExportNamingIs, s.exportNamingLabel())
+	}},
+	{name: msgActPreviewDownscale, run: (*UIState).cyclePreviewDownscale},
+	{name: msgActMipmaps, run: (*UIState).toggleMipmaps},
+	{name: msgActGammaCorrection, run: (*UIState).toggleGammaCorrection},
+	{name: msgActCopyImage, bindings: []binding{{key: rl.KeyC, ctrl: true}}, run: (*UIState).copySpriteImage},
+	{name: msgActResetOrder, run: (*UIState).resetOrder},
+	{name: msgActStorePresetA, run: func(s *UIState) { s.storePreset(0) }},
+	{name: msgActStorePresetB, run: func(s *UIState) { s.storePreset(1) }},
+	{name: msgActTogglePreset, bindings: []binding{{key: rl.KeyB}}, run: (*UIState).togglePreset},
+	{name: msgActPalette, bindings: []binding{{key: rl.KeyP, ctrl: true}}, run: (*UIState).togglePalette},
+}
+
+// runShortcuts runs the actions whose shortcuts were pressed this frame.
+// Shortcuts without Ctrl are plain keys, which are left alone while a widget
+// has the keyboard.
+func (s *UIState) runShortcuts() {
+	for _, a := range actions {
+		for _, b := range a.bindings {
+			if !b.ctrl && s.widgets.focused() {
+				continue
+			}
+			if b.pressed() {
+				a.run(s)
+				break
+			}
+		}
+	}
+	s.tabShortcuts()
+}
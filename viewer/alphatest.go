@@ -0,0 +1,39 @@
+package viewer
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// alphaTestShader paints every partially transparent pixel opaque magenta so
+// stray semi-transparent pixels at cell edges stand out. Fully transparent
+// and fully opaque pixels are drawn unchanged.
+const alphaTestShader = `#version 330
+in vec2 fragTexCoord;
+in vec4 fragColor;
+uniform sampler2D texture0;
+uniform vec4 colDiffuse;
+out vec4 finalColor;
+
+void main() {
+    vec4 texel = texture(texture0, fragTexCoord);
+    if (texel.a > 0.0 && texel.a < 1.0) {
+        finalColor = vec4(1.0, 0.0, 1.0, 1.0);
+    } else {
+        finalColor = texel * colDiffuse * fragColor;
+    }
+}
+`
+
+// thumbnailShader returns the shader used to draw thumbnails in the current
+// debug mode, loading it on first use. It reports false when thumbnails
+// should be drawn normally.
+func (s *UIState) thumbnailShader() (rl.Shader, bool) {
+	if s.silhouette {
+		return s.silhouetteShaderFor()
+	}
+	if !s.alphaTest {
+		return rl.Shader{}, false
+	}
+	if s.alphaShader.ID == 0 {
+		s.alphaShader = rl.LoadShaderFromMemory("", alphaTestShader)
+	}
+	return s.alphaShader, s.alphaShader.ID != 0
+}
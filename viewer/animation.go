@@ -0,0 +1,421 @@
+package viewer
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// defaultAnimFPS is the initial playback rate of the animation preview.
+const defaultAnimFPS int32 = 8
+
+// playDirection is the order the animation preview steps through its range.
+type playDirection int
+
+const (
+	playForward playDirection = iota
+	playReverse
+	// playPingPong runs forward to the end of the range and back.
+	playPingPong
+)
+
+// animation is the state of the animation preview, which plays a range of
+// sprites in display order. tag names the Aseprite tag the range was taken
+// from, if any.
+type animation struct {
+	visible   bool
+	playing   bool
+	start     int32
+	end       int32
+	fps       int32
+	frame     int32
+	elapsed   float32
+	direction playDirection
+	backwards bool
+	tag       string
+	// setAll is the duration the timeline's "set all" applies, and page
+	// the page of frames it shows.
+	setAll int32
+	page   int32
+	// loopIn and loopOut mark a part of the range to play on its own, or
+	// are noMarker. A loop with only one marker runs to the range's end or
+	// from its start.
+	loopIn  int32
+	loopOut int32
+	// rate measures how fast playback actually advances.
+	rate playRate
+}
+
+// rateWindow is how long, in seconds, playRate counts frames before it
+// updates its reading.
+const rateWindow = 1.0
+
+// playRate measures the frames the preview actually advances per second, by
+// the wall clock, which drifts from the intended rate when rendering can't
+// keep up.
+type playRate struct {
+	since  float64
+	frames int32
+	// fps is the last reading, or 0 before the first one.
+	fps float32
+}
+
+// count adds the frames advanced at time now, taking a reading once a window
+// has passed. A stopped preview starts over.
+func (r *playRate) count(frames int32, now float64, playing bool) {
+	if !playing {
+		*r = playRate{}
+		return
+	}
+	if r.since == 0 {
+		r.since = now
+		return
+	}
+	r.frames += frames
+	if now-r.since >= rateWindow {
+		r.fps = float32(float64(r.frames) / (now - r.since))
+		r.since, r.frames = now, 0
+	}
+}
+
+// noMarker is the value of a loop marker that isn't set.
+const noMarker int32 = -1
+
+// length returns the number of frames in the range.
+func (a *animation) length() int32 {
+	return a.end - a.start + 1
+}
+
+// reset selects every one of the n sprites and rewinds playback.
+func (a *animation) reset(n int32) {
+	a.setRange(0, max(n-1, 0))
+}
+
+// setRange plays the frames from start to end, forward, and rewinds. Loop
+// markers are cleared, as they belong to the old range.
+func (a *animation) setRange(start, end int32) {
+	a.start, a.end = start, end
+	a.frame, a.elapsed = start, 0
+	a.direction, a.backwards, a.tag = playForward, false, ""
+	a.clearLoop()
+}
+
+// clampRange keeps the range, loop markers and current frame within n
+// sprites. Markers that fall outside the range are cleared.
+func (a *animation) clampRange(n int32) {
+	last := max(n-1, 0)
+	a.start = min(a.start, last)
+	a.end = min(a.end, last)
+	if a.loopIn > a.end {
+		a.loopIn = noMarker
+	}
+	if a.loopOut > a.end {
+		a.loopOut = noMarker
+	}
+	if a.frame < a.start || a.frame > a.end {
+		a.frame = a.start
+	}
+}
+
+// looping reports whether a loop marker is set.
+func (a *animation) looping() bool {
+	return a.loopIn != noMarker || a.loopOut != noMarker
+}
+
+// playRange returns the first and last frame playback runs over: the loop
+// if there is one, otherwise the whole range.
+func (a *animation) playRange() (first, last int32) {
+	first, last = a.start, a.end
+	if a.loopIn != noMarker {
+		first = a.loopIn
+	}
+	if a.loopOut != noMarker {
+		last = a.loopOut
+	}
+	return first, last
+}
+
+// setLoopIn starts the loop at the current frame. An out marker before it
+// is cleared.
+func (a *animation) setLoopIn() {
+	a.loopIn = a.frame
+	if a.loopOut != noMarker && a.loopOut < a.frame {
+		a.loopOut = noMarker
+	}
+}
+
+// setLoopOut ends the loop at the current frame. An in marker after it is
+// cleared.
+func (a *animation) setLoopOut() {
+	a.loopOut = a.frame
+	if a.loopIn != noMarker && a.loopIn > a.frame {
+		a.loopIn = noMarker
+	}
+}
+
+// clearLoop removes the loop markers, so the whole range plays again.
+func (a *animation) clearLoop() {
+	a.loopIn, a.loopOut = noMarker, noMarker
+}
+
+// scrub pauses playback and moves by delta frames within the range,
+// wrapping around at its ends.
+func (a *animation) scrub(delta int32) {
+	a.playing, a.elapsed = false, 0
+	n := a.length()
+	a.frame = a.start + ((a.frame-a.start+delta)%n+n)%n
+}
+
+// advance moves playback forward by dt seconds, looping over the range, and
+// returns how many frames it stepped. frameTime returns how long, in
+// seconds, the frame at a display index is shown.
+func (a *animation) advance(dt float32, frameTime func(frame int32) float32) int32 {
+	if !a.playing || a.fps <= 0 {
+		return 0
+	}
+
+	a.elapsed += dt
+	steps := int32(0)
+	for t := frameTime(a.frame); t > 0 && a.elapsed >= t; t = frameTime(a.frame) {
+		a.elapsed -= t
+		a.step()
+		steps++
+	}
+	return steps
+}
+
+// step moves to the next frame in the play direction, looping over the play
+// range.
+func (a *animation) step() {
+	first, last := a.playRange()
+	switch {
+	case a.frame < first || a.frame > last:
+		a.frame = first
+	case a.direction == playReverse:
+		a.frame--
+		if a.frame < first {
+			a.frame = last
+		}
+	case a.direction == playPingPong && first < last:
+		if a.backwards && a.frame == first || !a.backwards && a.frame == last {
+			a.backwards = !a.backwards
+		}
+		if a.backwards {
+			a.frame--
+		} else {
+			a.frame++
+		}
+	default:
+		a.frame++
+		if a.frame > last {
+			a.frame = first
+		}
+	}
+}
+
+// frameTime returns how long the sprite at display index i is shown: its
+// own duration if it has one, otherwise one frame at the preview's FPS.
+func (s *UIState) frameTime(i int32) float32 {
+	if int(i) < len(s.spriteNames) {
+		if ms, ok := s.frameDuration(s.spriteNames[i]); ok {
+			return float32(ms) / 1000
+		}
+	}
+	return 1 / float32(s.anim.fps)
+}
+
+// rangeTime returns how long one pass over the preview range takes, and
+// whether any frame in it has its own duration.
+func (s *UIState) rangeTime() (seconds float32, timed bool) {
+	for i := s.anim.start; i <= s.anim.end; i++ {
+		seconds += s.frameTime(i)
+		if int(i) < len(s.spriteNames) {
+			_, ok := s.frameDuration(s.spriteNames[i])
+			timed = timed || ok
+		}
+	}
+	return seconds, timed
+}
+
+// intendedFPS returns the frames per second the preview should advance at:
+// its FPS, or with frame durations, the range's frame count over the time it
+// takes.
+func (s *UIState) intendedFPS() float32 {
+	seconds, timed := s.rangeTime()
+	if !timed || seconds <= 0 {
+		return float32(s.anim.fps)
+	}
+	return float32(s.anim.length()) / seconds
+}
+
+// rateStatus returns the status bar text comparing the animation's measured
+// rate with the intended one and the render rate, while the preview plays.
+// off is set when playback is more than a tenth off its intended rate.
+func (s *UIState) rateStatus() (text string, off bool) {
+	if !s.anim.visible || !s.anim.playing || s.anim.rate.fps == 0 {
+		return "", false
+	}
+	want := s.intendedFPS()
+	got := s.anim.rate.fps
+	return trf(msgPlayRate, got, want, rl.GetFPS()), want > 0 && (got < want*0.9 || got > want*1.1)
+}
+
+// playTag plays the frames of an Aseprite tag in its direction. A reverse
+// tag starts from its last frame.
+func (s *UIState) playTag(tag asepriteAnim) {
+	start, end := s.displayIndex(tag.first), s.displayIndex(tag.last)
+	if start < 0 || end < 0 {
+		return
+	}
+	if start > end {
+		start, end = end, start
+	}
+	s.anim.setRange(start, end)
+	s.anim.direction, s.anim.tag = tag.direction, tag.name
+	if tag.direction == playReverse {
+		s.anim.frame = end
+	}
+}
+
+// cycleTag switches the preview to the next Aseprite tag, and back to the
+// whole sheet after the last one.
+func (s *UIState) cycleTag() {
+	tags := s.aseprite.tags
+	next := 0
+	for i, tag := range tags {
+		if tag.name == s.anim.tag {
+			next = i + 1
+		}
+	}
+	if next == len(tags) {
+		s.anim.reset(int32(len(s.spriteNames)))
+		return
+	}
+	s.playTag(tags[next])
+}
+
+// displayIndex returns the index of the named sprite in display order, or -1.
+func (s *UIState) displayIndex(name string) int32 {
+	for i, n := range s.spriteNames {
+		if n == name {
+			return int32(i)
+		}
+	}
+	return -1
+}
+
+// withAnimation runs fn on the animation preview if it is shown, so its keys
+// do nothing while it is hidden.
+func (s *UIState) withAnimation(fn func(a *animation)) {
+	if s.anim.visible && len(s.spriteNames) > 0 {
+		fn(&s.anim)
+	}
+}
+
+// toggleAnimation shows or hides the animation preview. Showing it starts
+// playback.
+func (s *UIState) toggleAnimation() {
+	s.anim.visible = !s.anim.visible
+	s.anim.playing = s.anim.visible
+}
+
+// playingSprite returns the sprite the animation preview is showing, or ""
+// while it is hidden.
+func (s *UIState) playingSprite() string {
+	if !s.anim.visible || s.sheet == nil || int(s.anim.frame) >= len(s.spriteNames) {
+		return ""
+	}
+	return s.spriteNames[s.anim.frame]
+}
+
+// drawPlayingOutline marks the cell at dest as the one the animation preview
+// is showing: an outline inside the cell, where it can't be mistaken for the
+// selection drawn around it, and a play mark in the corner.
+func (s *UIState) drawPlayingOutline(dest rl.Rectangle) {
+	rl.DrawRectangleLinesEx(dest, 2, s.theme.Playing)
+	size := min(dest.Width, dest.Height, 10)
+	rl.DrawTriangle(
+		rl.Vector2{X: dest.X + 2, Y: dest.Y + 2},
+		rl.Vector2{X: dest.X + 2 + size*0.8, Y: dest.Y + 2 + size/2},
+		rl.Vector2{X: dest.X + 2, Y: dest.Y + 2 + size},
+		s.theme.Playing)
+}
+
+// renderAnimation draws the animation preview panel in the lower right of
+// the viewport, with fields for the frame range and playback rate.
+func (s *UIState) renderAnimation(cfg Config) rl.Rectangle {
+	if s.sheet == nil || len(s.spriteNames) == 0 {
+		return rl.Rectangle{}
+	}
+	steps := s.anim.advance(rl.GetFrameTime(), s.frameTime)
+	s.anim.rate.count(steps, rl.GetTime(), s.anim.playing)
+	if steps > 0 && s.viewMode != sheetView {
+		// The grid follows playback, so the cell a glitchy frame comes
+		// from stays in view.
+		s.reveal = s.spriteNames[s.anim.frame]
+	}
+
+	// Sheets with Aseprite tags get a row to pick one.
+	tagRow := float32(0)
+	if s.aseprite != nil && len(s.aseprite.tags) > 0 {
+		tagRow = 30
+	}
+	panel := rl.Rectangle{X: float32(cfg.width - 210), Y: float32(cfg.startY+cfg.viewportHeight) - 250 - tagRow, Width: 200, Height: 240 + tagRow}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(tr(msgAnimation), int32(panel.X)+10, int32(panel.Y)+8, 15, s.theme.Text)
+
+	name := s.spriteNames[s.anim.frame]
+	rect := s.sheet.Sprites[name]
+	source := rl.Rectangle{X: float32(rect.X), Y: float32(rect.Y), Width: float32(rect.Width), Height: float32(rect.Height)}
+	dest := rl.Rectangle{X: panel.X + 52, Y: panel.Y + 30, Width: 96, Height: 96}
+	rl.DrawTexturePro(s.drawTexture(), s.texSource(source), dest, rl.Vector2{}, 0, rl.White)
+	rl.DrawRectangleLinesEx(dest, 1, s.theme.CellBorder)
+
+	frameText := trf(msgFrame, s.anim.frame, name)
+	drawText(frameText, int32(panel.X+panel.Width/2)-measureText(frameText, 10)/2, int32(panel.Y)+132, 10, s.theme.MutedText)
+
+	if tagRow > 0 {
+		label := tr(msgAllFrames)
+		if s.anim.tag != "" {
+			label = trf(msgTag, s.anim.tag)
+		}
+		if drawButton(rl.Rectangle{X: panel.X + 10, Y: panel.Y + 150, Width: panel.Width - 20, Height: 22}, label) {
+			s.cycleTag()
+		}
+	}
+
+	last := int32(len(s.spriteNames) - 1)
+	fieldY := panel.Y + 165 + tagRow
+	start := s.drawInputField(rl.Rectangle{X: panel.X + 10, Y: fieldY, Width: 50, Height: 20}, tr(msgStart), s.anim.start, 0, last, 0)
+	end := s.drawInputField(rl.Rectangle{X: panel.X + 75, Y: fieldY, Width: 50, Height: 20}, tr(msgEnd), s.anim.end, 0, last, last)
+	s.anim.fps = s.drawInputField(rl.Rectangle{X: panel.X + 140, Y: fieldY, Width: 50, Height: 20}, tr(msgFPS), s.anim.fps, 1, 60, defaultAnimFPS)
+
+	if start != s.anim.start || end != s.anim.end {
+		if start > end {
+			start, end = end, start
+			s.notify(msgRangeSwapped, start, end)
+		}
+		s.anim.setRange(start, end)
+	}
+
+	duration, timed := s.rangeTime()
+	rangeText := trf(msgRangeInfo, s.anim.length(), duration, s.anim.fps)
+	rangeColor := s.theme.MutedText
+	if timed {
+		rangeText = trf(msgRangeInfoTimed, s.anim.length(), duration)
+	}
+	if s.anim.looping() {
+		first, last := s.anim.playRange()
+		rangeText = trf(msgLoopInfo, first, last, binding{key: rl.KeyBackSlash})
+		rangeColor = s.selectionColor()
+	}
+	drawText(rangeText, int32(panel.X)+10, int32(panel.Y+192+tagRow), 10, rangeColor)
+
+	label := tr(msgPlay)
+	if s.anim.playing {
+		label = tr(msgPause)
+	}
+	if drawButton(rl.Rectangle{X: panel.X + 10, Y: panel.Y + 208 + tagRow, Width: panel.Width - 20, Height: 24}, label) {
+		s.anim.playing = !s.anim.playing
+	}
+
+	s.renderTimeline(cfg, panel)
+	return panel
+}
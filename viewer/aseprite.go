@@ -0,0 +1,227 @@
+package viewer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ztkent/beam/resources"
+)
+
+// asepriteFrame is one entry of the frames list or hash Aseprite exports
+// next to a sheet. Duration is in milliseconds.
+type asepriteFrame struct {
+	Filename string    `json:"filename"`
+	Frame    atlasRect `json:"frame"`
+	Duration int32     `json:"duration"`
+}
+
+// asepriteTag is a named frame range, which Aseprite uses for animations.
+// From and To index the exported frames, inclusive.
+type asepriteTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+}
+
+type asepriteMeta struct {
+	App       string        `json:"app"`
+	FrameTags []asepriteTag `json:"frameTags"`
+}
+
+// asepriteSheet is the frame data read from an Aseprite sidecar: the named
+// frames in export order with their source rectangles and durations, and
+// the tags as ranges of those names.
+type asepriteSheet struct {
+	file      string
+	names     []string
+	sprites   map[string]resources.Rectangle
+	durations map[string]int32
+	tags      []asepriteAnim
+}
+
+// asepriteAnim is a tag resolved to frame names.
+type asepriteAnim struct {
+	name      string
+	first     string
+	last      string
+	direction playDirection
+}
+
+// asepritePath returns the JSON file Aseprite writes for the sheet at path
+// by default: the same name with a .json extension.
+func asepritePath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".json"
+}
+
+// loadAseprite reads the Aseprite sidecar of the sheet at path. It returns
+// nil without an error if there is none, or if the JSON there was written
+// by another tool. Frames outside a width by height image are dropped.
+func loadAseprite(path string, width, height int32) (*asepriteSheet, error) {
+	file := asepritePath(path)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Frames json.RawMessage `json:"frames"`
+		Meta   asepriteMeta    `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.Contains(strings.ToLower(doc.Meta.App), "aseprite") {
+		return nil, nil
+	}
+	frames, err := decodeAsepriteFrames(doc.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &asepriteSheet{
+		file:      file,
+		sprites:   make(map[string]resources.Rectangle, len(frames)),
+		durations: make(map[string]int32, len(frames)),
+	}
+	// Tags index the exported frames, including any dropped here, so names
+	// are kept per frame with "" standing in for dropped ones.
+	byIndex := make([]string, len(frames))
+	for i, f := range frames {
+		r := f.Frame
+		if r.W <= 0 || r.H <= 0 || r.X < 0 || r.Y < 0 || r.X+r.W > width || r.Y+r.H > height {
+			continue
+		}
+		name := uniqueName(a.sprites, frameName(f.Filename, i))
+		byIndex[i] = name
+		a.names = append(a.names, name)
+		a.sprites[name] = resources.Rectangle{X: r.X, Y: r.Y, Width: r.W, Height: r.H}
+		if f.Duration > 0 {
+			a.durations[name] = f.Duration
+		}
+	}
+	if len(a.names) == 0 {
+		return nil, errors.New(tr(msgAsepriteNoFrames))
+	}
+
+	for _, tag := range doc.Meta.FrameTags {
+		first, last := firstNamed(byIndex, tag.From, tag.To, 1), firstNamed(byIndex, tag.To, tag.From, -1)
+		if first == "" {
+			continue
+		}
+		a.tags = append(a.tags, asepriteAnim{
+			name:      tag.Name,
+			first:     first,
+			last:      last,
+			direction: asepriteDirection(tag.Direction),
+		})
+	}
+	return a, nil
+}
+
+// decodeAsepriteFrames decodes the frames of an Aseprite export, written
+// either as an array or as a hash keyed by file name. The hash is read
+// key by key, since its order is the frame order tags refer to.
+func decodeAsepriteFrames(raw json.RawMessage) ([]asepriteFrame, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		var frames []asepriteFrame
+		err := json.Unmarshal(raw, &frames)
+		return frames, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New(tr(msgAsepriteNoFrames))
+	}
+	var frames []asepriteFrame
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var f asepriteFrame
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		f.Filename = tok.(string)
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// frameName returns the sprite name of the i-th frame exported as filename,
+// without the .aseprite or .ase extension Aseprite appends by default.
+func frameName(filename string, i int) string {
+	for _, ext := range []string{".aseprite", ".ase"} {
+		filename = strings.TrimSuffix(filename, ext)
+	}
+	if filename == "" {
+		return strconv.Itoa(i)
+	}
+	return filename
+}
+
+// uniqueName returns name, or name with a numeric suffix if it is already
+// taken in sprites.
+func uniqueName(sprites map[string]resources.Rectangle, name string) string {
+	if _, taken := sprites[name]; !taken {
+		return name
+	}
+	for n := 2; ; n++ {
+		candidate := name + "_" + strconv.Itoa(n)
+		if _, taken := sprites[candidate]; !taken {
+			return candidate
+		}
+	}
+}
+
+// firstNamed returns the first non-empty name in byIndex walking from from
+// towards to in steps of step, or "" if the range holds none.
+func firstNamed(byIndex []string, from, to, step int) string {
+	for i := from; i >= 0 && i < len(byIndex); i += step {
+		if byIndex[i] != "" {
+			return byIndex[i]
+		}
+		if i == to {
+			break
+		}
+	}
+	return ""
+}
+
+// asepriteDirection maps the direction of an Aseprite tag to playback.
+func asepriteDirection(direction string) playDirection {
+	switch direction {
+	case "reverse":
+		return playReverse
+	case "pingpong", "pingpong_reverse":
+		return playPingPong
+	default:
+		return playForward
+	}
+}
+
+// loadAsepriteFor reads the Aseprite sidecar of the current file for a
+// freshly loaded sheet. A sidecar that can't be used is reported once, and
+// the sheet is sliced by the grid settings instead.
+func (s *UIState) loadAsepriteFor(sheet *resources.SpriteSheet) *asepriteSheet {
+	ase, err := loadAseprite(s.currentFile, sheet.Texture.Width, sheet.Texture.Height)
+	var msg string
+	if err != nil {
+		msg = err.Error()
+		if msg != s.asepriteErr {
+			s.notify(msgAsepriteFailed, filepath.Base(asepritePath(s.currentFile)), err)
+		}
+	}
+	s.asepriteErr = msg
+	return ase
+}
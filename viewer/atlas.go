@@ -0,0 +1,126 @@
+package viewer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// atlasRect and atlasSize follow the TexturePacker JSON conventions.
+type atlasRect struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+	W int32 `json:"w"`
+	H int32 `json:"h"`
+}
+
+type atlasSize struct {
+	W int32 `json:"w"`
+	H int32 `json:"h"`
+}
+
+// atlasFrame describes one sprite. Frame is always the full grid cell. When
+// trim data is requested, ContentFrame is the non-transparent part of the
+// cell in sheet coordinates, SpriteSourceSize is that same content relative
+// to the cell, and SourceSize is the untrimmed cell size.
+type atlasFrame struct {
+	Filename         string     `json:"filename"`
+	Frame            atlasRect  `json:"frame"`
+	Rotated          bool       `json:"rotated"`
+	Trimmed          bool       `json:"trimmed"`
+	ContentFrame     *atlasRect `json:"contentFrame,omitempty"`
+	SpriteSourceSize *atlasRect `json:"spriteSourceSize,omitempty"`
+	SourceSize       *atlasSize `json:"sourceSize,omitempty"`
+}
+
+type atlasMeta struct {
+	Image    string    `json:"image"`
+	Size     atlasSize `json:"size"`
+	GridSize int32     `json:"gridSize"`
+	Margin   int32     `json:"margin"`
+}
+
+type atlas struct {
+	Frames []atlasFrame `json:"frames"`
+	Meta   atlasMeta    `json:"meta"`
+}
+
+// buildAtlas describes the named sprites of src. With trim set, the content
+// bounds of every sprite are measured from its cropped pixels, counting
+// pixels with an alpha of at most alpha as transparent.
+func buildAtlas(src *rl.Image, image string, rects map[string]resources.Rectangle, names []string, gridSize, margin int32, trim bool, alpha uint8) atlas {
+	a := atlas{
+		Frames: make([]atlasFrame, 0, len(names)),
+		Meta: atlasMeta{
+			Image:    image,
+			Size:     atlasSize{W: src.Width, H: src.Height},
+			GridSize: gridSize,
+			Margin:   margin,
+		},
+	}
+
+	var pixels *sheetPixels
+	if trim {
+		pixels = imagePixels(src, alpha)
+	}
+	for _, name := range names {
+		rect := rects[name]
+		frame := atlasFrame{
+			Filename: name,
+			Frame:    atlasRect{X: rect.X, Y: rect.Y, W: rect.Width, H: rect.Height},
+		}
+		if trim {
+			content := pixels.contentBounds(rect)
+			frame.Trimmed = content.W != rect.Width || content.H != rect.Height
+			frame.ContentFrame = &atlasRect{X: rect.X + content.X, Y: rect.Y + content.Y, W: content.W, H: content.H}
+			frame.SpriteSourceSize = &content
+			frame.SourceSize = &atlasSize{W: rect.Width, H: rect.Height}
+		}
+		a.Frames = append(a.Frames, frame)
+	}
+	return a
+}
+
+// exportAtlas asks for a destination folder and writes a JSON atlas of the
+// current sheet into it. An existing atlas is never replaced; a numbered file
+// is written next to it instead.
+func (s *UIState) exportAtlas() {
+	if s.sheet == nil {
+		s.notify(msgNothingToExport)
+		return
+	}
+
+	dir := openDirectoryDialog()
+	if dir == "" {
+		return
+	}
+
+	src := rl.LoadImage(s.currentFile)
+	if !rl.IsImageValid(src) {
+		s.notify(msgExportReadFailed, filepath.Base(s.currentFile))
+		return
+	}
+	defer rl.UnloadImage(src)
+
+	image := filepath.Base(s.currentFile)
+	a := buildAtlas(src, image, s.sheet.Sprites, s.spriteNames, s.slicing.cellWidth, s.slicing.margin, s.atlasTrim, s.alphaCutoff())
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		s.notify(msgExportFailed, err)
+		return
+	}
+
+	path := filepath.Join(dir, strings.TrimSuffix(image, filepath.Ext(image))+".json")
+	if _, err := os.Stat(path); err == nil {
+		path = uniquePath(path)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		s.notify(msgExportFailed, err)
+		return
+	}
+	s.notify(msgWroteAtlas, len(a.Frames), path)
+}
@@ -0,0 +1,110 @@
+package viewer
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Auto-scrolling while dragging a selection band starts within
+// bandScrollEdge pixels of the viewport edge and reaches bandScrollSpeed
+// pixels per second at the edge itself.
+const (
+	bandScrollEdge  = 30
+	bandScrollSpeed = 900
+)
+
+// selectionBand tracks a rubber-band selection dragged out from empty grid
+// space. anchor is where the drag started in content coordinates, so it
+// stays put as the grid scrolls under it. base is the selection the band
+// adds to.
+type selectionBand struct {
+	pressed bool
+	active  bool
+	anchor  rl.Vector2
+	base    map[string]bool
+}
+
+// beginBand starts a selection band at the mouse position. With Ctrl held
+// the band adds to the current selection, otherwise it replaces it.
+func (s *UIState) beginBand() {
+	mouse := mousePosition()
+	base := make(map[string]bool)
+	if rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl) {
+		for name := range s.selected {
+			base[name] = true
+		}
+	}
+	s.band = selectionBand{
+		pressed: true,
+		anchor:  rl.Vector2{X: mouse.X + s.scrollOffsetX, Y: mouse.Y + s.scrollOffset},
+		base:    base,
+	}
+}
+
+// updateBand grows the selection band with the mouse, selects every
+// thumbnail it touches and draws it. The band is drawn in screen space but
+// tested against the scrolled thumbnail rectangles, so thumbnails that have
+// scrolled out of view stay selected.
+func (s *UIState) updateBand(cfg Config) {
+	if !s.band.pressed {
+		return
+	}
+	if !rl.IsMouseButtonDown(rl.MouseLeftButton) {
+		if s.band.active {
+			s.snapScroll(cfg)
+		}
+		s.band = selectionBand{}
+		return
+	}
+
+	mouse := mousePosition()
+	start := rl.Vector2{X: s.band.anchor.X - s.scrollOffsetX, Y: s.band.anchor.Y - s.scrollOffset}
+	if !s.band.active && rl.Vector2Distance(mouse, start) < dragThreshold {
+		return
+	}
+	s.band.active = true
+	s.autoScrollBand(cfg, mouse)
+
+	band := rl.Rectangle{
+		X:      min(start.X, mouse.X),
+		Y:      min(start.Y, mouse.Y),
+		Width:  float32(math.Abs(float64(mouse.X - start.X))),
+		Height: float32(math.Abs(float64(mouse.Y - start.Y))),
+	}
+	selected := make(map[string]bool, len(s.band.base))
+	for name := range s.band.base {
+		selected[name] = true
+	}
+	for i, name := range s.spriteNames {
+		if !s.cellHidden(i) && rl.CheckCollisionRecs(band, s.cellRect(cfg, i)) {
+			selected[name] = true
+		}
+	}
+	s.selected = selected
+
+	rl.DrawRectangleRec(band, rl.ColorAlpha(s.selectionColor(), 0.15))
+	rl.DrawRectangleLinesEx(band, 1, s.selectionColor())
+}
+
+// autoScrollBand scrolls the grid while the band is dragged against the top
+// or bottom of the viewport, or the sides in strip view, faster the closer
+// the mouse gets to the edge. The offsets are clamped with the next layout.
+func (s *UIState) autoScrollBand(cfg Config, mouse rl.Vector2) {
+	speed := func(pos, low, high float32) float32 {
+		switch {
+		case pos < low+bandScrollEdge:
+			return -min(low+bandScrollEdge-pos, bandScrollEdge) / bandScrollEdge * bandScrollSpeed
+		case pos > high-bandScrollEdge:
+			return min(pos-(high-bandScrollEdge), bandScrollEdge) / bandScrollEdge * bandScrollSpeed
+		}
+		return 0
+	}
+
+	dt := rl.GetFrameTime()
+	if s.viewMode == stripView {
+		s.scrollOffsetX += speed(mouse.X, 0, float32(cfg.width)) * dt
+		return
+	}
+	s.scrollOffset += speed(mouse.Y, float32(cfg.startY), float32(cfg.startY+cfg.viewportHeight)) * dt
+}
@@ -0,0 +1,223 @@
+package viewer
+
+import (
+	"fmt"
+	"image/color"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// defaultKeyTolerance is how far, per channel, a pixel may be from the
+// background color and still be keyed out. JPEG sheets need some leeway, as
+// their flat backgrounds come out of the encoder slightly uneven.
+const defaultKeyTolerance int32 = 16
+
+// backgroundKey previews the sheet with a solid background color keyed out,
+// for sheets exported without transparency. Like a recolor preview it lives
+// in its own texture, so exports keep writing the original pixels.
+type backgroundKey struct {
+	color color.RGBA
+	keyed int
+	tex   rl.Texture2D
+	// picking waits for a click on the sheet to take the key color from.
+	picking bool
+}
+
+func (k *backgroundKey) close() {
+	if k.tex.ID != 0 {
+		rl.UnloadTexture(k.tex)
+	}
+}
+
+// texture returns the keyed texture, or an empty one if there is none.
+func (k *backgroundKey) texture() rl.Texture2D {
+	if k == nil {
+		return rl.Texture2D{}
+	}
+	return k.tex
+}
+
+// hexColor formats c as #RRGGBB.
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// cornerColor returns the color most of the sheet's four corner pixels
+// share, which on a sheet with a solid background is the background. With
+// no majority the top left corner wins.
+func (p *sheetPixels) cornerColor() color.RGBA {
+	corners := []color.RGBA{
+		p.at(0, 0),
+		p.at(p.width-1, 0),
+		p.at(0, p.height-1),
+		p.at(p.width-1, p.height-1),
+	}
+	best, bestCount := corners[0], 0
+	for _, c := range corners {
+		count := 0
+		for _, other := range corners {
+			if other == c {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = c, count
+		}
+	}
+	return best
+}
+
+// keyOut returns a copy of the pixels with those within tolerance of c on
+// every channel made transparent, and how many there were.
+func (p *sheetPixels) keyOut(c color.RGBA, tolerance int32) ([]color.RGBA, int) {
+	near := func(a, b uint8) bool {
+		d := int32(a) - int32(b)
+		return d <= tolerance && d >= -tolerance
+	}
+	out := make([]color.RGBA, len(p.pix))
+	keyed := 0
+	for i, px := range p.pix {
+		if px.A != 0 && near(px.R, c.R) && near(px.G, c.G) && near(px.B, c.B) {
+			keyed++
+			continue
+		}
+		out[i] = px
+	}
+	return out, keyed
+}
+
+// guessBackground keys out the color of the sheet's corners.
+func (s *UIState) guessBackground() {
+	if s.sheet == nil {
+		s.notify(msgKeyNeedsSheet)
+		return
+	}
+	p, err := s.sheetPixels()
+	if err != nil {
+		s.notify(msgKeyFailed, err)
+		return
+	}
+	s.setBackgroundKey(p.cornerColor())
+}
+
+// setBackgroundKey previews the sheet with c keyed out, replacing the key in
+// use if there is one.
+func (s *UIState) setBackgroundKey(c color.RGBA) {
+	k, err := s.buildBackgroundKey(c)
+	if err != nil {
+		s.notify(msgKeyFailed, err)
+		return
+	}
+	s.closeBackgroundKey()
+	s.bgKey = k
+	s.notify(msgKeyedBackground, hexColor(c), k.keyed)
+}
+
+// buildBackgroundKey uploads the sheet with c keyed out at the current
+// tolerance, gamma corrected like the sheet if that is on.
+func (s *UIState) buildBackgroundKey(c color.RGBA) (*backgroundKey, error) {
+	p, err := s.sheetPixels()
+	if err != nil {
+		return nil, err
+	}
+	pix, keyed := p.keyOut(c, s.keyTolerance)
+	k := &backgroundKey{color: c, keyed: keyed}
+	k.tex = s.uploadPixels(s.gammaCorrected(pix), p.width, p.height)
+	return k, nil
+}
+
+// refreshBackgroundKey keys the sheet out again after a reload or a change
+// of tolerance.
+func (s *UIState) refreshBackgroundKey() {
+	if s.bgKey == nil {
+		return
+	}
+	k, err := s.buildBackgroundKey(s.bgKey.color)
+	if err != nil {
+		s.notify(msgKeyFailed, err)
+		s.closeBackgroundKey()
+		return
+	}
+	k.picking = s.bgKey.picking
+	s.bgKey.close()
+	s.bgKey = k
+}
+
+// closeBackgroundKey drops the key and shows the sheet's own background.
+func (s *UIState) closeBackgroundKey() {
+	if s.bgKey != nil {
+		s.bgKey.close()
+		s.bgKey = nil
+	}
+}
+
+// pickingKey reports whether the next click on the sheet picks the key
+// color rather than selecting.
+func (s *UIState) pickingKey() bool {
+	return s.bgKey != nil && s.bgKey.picking
+}
+
+// pickKey keys out the color of the sheet pixel under the mouse, in the grid
+// or the sheet view.
+func (s *UIState) pickKey(cfg Config) {
+	var px rl.Vector2
+	if s.viewMode == sheetView {
+		var ok bool
+		if px, ok = s.sheetPoint(cfg, mousePosition()); !ok {
+			return
+		}
+	} else {
+		i := s.hoveredCell(cfg)
+		if i < 0 {
+			return
+		}
+		rect := s.sheet.Sprites[s.spriteNames[i]]
+		dest := s.thumbnailRect(cfg, i, rect)
+		mouse := mousePosition()
+		if !rl.CheckCollisionPointRec(mouse, dest) {
+			return
+		}
+		px = rl.Vector2{
+			X: float32(rect.X) + (mouse.X-dest.X)*float32(rect.Width)/dest.Width,
+			Y: float32(rect.Y) + (mouse.Y-dest.Y)*float32(rect.Height)/dest.Height,
+		}
+	}
+	p, err := s.sheetPixels()
+	if err != nil {
+		s.notify(msgKeyFailed, err)
+		return
+	}
+	s.setBackgroundKey(p.at(int32(px.X), int32(px.Y)))
+}
+
+// renderKeyBar draws the key color and summary under the header, below the
+// comparison and recolor bars if they are shown.
+func (s *UIState) renderKeyBar(cfg Config) rl.Rectangle {
+	y := float32(cfg.headerHeight + 1)
+	if s.diff != nil {
+		y += 25
+	}
+	if s.recolor != nil {
+		y += 25
+	}
+	rl.DrawRectangle(0, int32(y), cfg.width, 24, rl.ColorAlpha(s.theme.Panel, 0.95))
+	swatch := rl.Rectangle{X: 10, Y: y + 4, Width: 16, Height: 16}
+	rl.DrawRectangleRec(swatch, s.bgKey.color)
+	rl.DrawRectangleLinesEx(swatch, 1, s.theme.Text)
+	text := trf(msgKeySummary, hexColor(s.bgKey.color), s.bgKey.keyed, s.keyTolerance)
+	if s.bgKey.picking {
+		text = tr(msgPickingKey)
+	}
+	drawText(text, 34, int32(y)+7, 10, s.theme.Text)
+
+	closeWidth := buttonWidth(tr(msgClose), 80)
+	closeX := float32(cfg.width-10) - closeWidth
+	pickWidth := buttonWidth(tr(msgPickKey), 80)
+	if drawButton(rl.Rectangle{X: closeX - 10 - pickWidth, Y: y + 2, Width: pickWidth, Height: 20}, tr(msgPickKey)) {
+		s.bgKey.picking = !s.bgKey.picking
+	}
+	if drawButton(rl.Rectangle{X: closeX, Y: y + 2, Width: closeWidth, Height: 20}, tr(msgClose)) {
+		s.closeBackgroundKey()
+	}
+	return rl.Rectangle{Y: y, Width: float32(cfg.width), Height: 24}
+}
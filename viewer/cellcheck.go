@@ -0,0 +1,180 @@
+package viewer
+
+import (
+	"fmt"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// expectedSlicing returns the grid a sheet of the given size is expected to
+// hold when the margin only separates cells, so a last column or row without
+// a margin after it still counts. The resources package divides by the cell
+// size plus margin instead, and drops such a column or row.
+func expectedSlicing(width, height, gridSize, margin int32) sheetSlicing {
+	g := newSlicing(width, height, gridSize, margin)
+	g.cols = (width + g.margin) / (g.cellWidth + g.margin)
+	g.rows = (height + g.margin) / (g.cellHeight + g.margin)
+	return g
+}
+
+// cellCheck compares the cells the sheet was expected to be cut into with the
+// sprites the resources package actually produced.
+type cellCheck struct {
+	grid     sheetSlicing
+	expected int
+	actual   int
+	// missing are the expected cells the sheet has no matching sprite for,
+	// and extra the sprites that match no expected cell.
+	missing []resources.Rectangle
+	extra   []resources.Rectangle
+	// missingCols and missingRows are columns and rows missing entirely.
+	missingCols []int32
+	missingRows []int32
+}
+
+// checkCells compares grid with sprites. A sprite matches an expected cell
+// when it has the cell's "row_col" name and the same rectangle.
+func checkCells(grid sheetSlicing, sprites map[string]resources.Rectangle) *cellCheck {
+	c := &cellCheck{grid: grid, expected: int(grid.cols * grid.rows), actual: len(sprites)}
+	expected := grid.sprites()
+	absent := make(map[[2]int32]bool)
+	for row := int32(0); row < grid.rows; row++ {
+		for col := int32(0); col < grid.cols; col++ {
+			rect := grid.cell(col, row)
+			if got, ok := sprites[fmt.Sprintf("%d_%d", row, col)]; !ok || got != rect {
+				c.missing = append(c.missing, rect)
+				absent[[2]int32{col, row}] = true
+			}
+		}
+	}
+	for name, rect := range sprites {
+		if want, ok := expected[name]; !ok || want != rect {
+			c.extra = append(c.extra, rect)
+		}
+	}
+
+	for col := int32(0); col < grid.cols; col++ {
+		all := grid.rows > 0
+		for row := int32(0); row < grid.rows && all; row++ {
+			all = absent[[2]int32{col, row}]
+		}
+		if all {
+			c.missingCols = append(c.missingCols, col)
+		}
+	}
+	for row := int32(0); row < grid.rows; row++ {
+		all := grid.cols > 0
+		for col := int32(0); col < grid.cols && all; col++ {
+			all = absent[[2]int32{col, row}]
+		}
+		if all {
+			c.missingRows = append(c.missingRows, row)
+		}
+	}
+	return c
+}
+
+// ok reports whether the sheet holds exactly the expected cells.
+func (c *cellCheck) ok() bool {
+	return len(c.missing) == 0 && len(c.extra) == 0
+}
+
+// summary describes the comparison in one line, such as "expected 120 cells,
+// sheet contains 112; missing column 7 (rows 0-7)", for bug reports against
+// the slicer.
+func (c *cellCheck) summary() string {
+	if c.ok() {
+		return trf(msgCellCheckOK, c.expected)
+	}
+	parts := []string{trf(msgCellCheckCounts, c.expected, c.actual)}
+	inWhole := 0
+	for _, col := range c.missingCols {
+		parts = append(parts, trf(msgMissingColumn, col, c.grid.rows-1))
+		inWhole += int(c.grid.rows)
+	}
+	for _, row := range c.missingRows {
+		parts = append(parts, trf(msgMissingRow, row, c.grid.cols-1))
+		inWhole += int(c.grid.cols)
+	}
+	// A cell where a missing row and column cross was counted twice.
+	inWhole -= len(c.missingCols) * len(c.missingRows)
+	if other := len(c.missing) - inWhole; other > 0 {
+		parts = append(parts, trf(msgMissingCells, other))
+	}
+	if len(c.extra) > 0 {
+		parts = append(parts, trf(msgUnexpectedCells, len(c.extra)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// cellCheck compares the loaded sheet with the grid expected for the
+// settings it was sliced with, caching the result until the sheet is
+// resliced. It returns nil for sheets the resources package didn't slice:
+// Aseprite frames and count mode, whose cells the viewer computes itself.
+func (s *UIState) cellCheck() *cellCheck {
+	if s.sheet == nil || s.aseprite != nil || s.sliceByCount {
+		return nil
+	}
+	if s.cells == nil {
+		tex := s.sheet.Texture
+		s.cells = checkCells(expectedSlicing(tex.Width, tex.Height, s.slicing.cellWidth, s.slicing.margin), s.sheet.Sprites)
+	}
+	return s.cells
+}
+
+// toggleCellCheck shows or hides the expected grid over the sheet view,
+// switching to the sheet view to show it.
+func (s *UIState) toggleCellCheck() {
+	s.showCellCheck = !s.showCellCheck
+	if !s.showCellCheck {
+		return
+	}
+	c := s.cellCheck()
+	if c == nil {
+		s.showCellCheck = false
+		s.notify(msgCellCheckGridOnly)
+		return
+	}
+	s.viewMode = sheetView
+	s.notifyText(c.summary())
+}
+
+// drawCellCheck draws the expected grid over the sheet view at dest and
+// scale, on top of the sprites present, which the sheet view outlines in
+// another color. Expected cells without a sprite are tinted, and sprites
+// without an expected cell are outlined in the error color.
+func (s *UIState) drawCellCheck(cfg Config, dest rl.Rectangle, scale float32) {
+	c := s.cellCheck()
+	if c == nil {
+		return
+	}
+	onScreen := func(r resources.Rectangle) rl.Rectangle {
+		src := spriteSource(r)
+		return rl.Rectangle{X: dest.X + src.X*scale, Y: dest.Y + src.Y*scale, Width: src.Width * scale, Height: src.Height * scale}
+	}
+	for row := int32(0); row < c.grid.rows; row++ {
+		for col := int32(0); col < c.grid.cols; col++ {
+			cell := onScreen(c.grid.cell(col, row))
+			inset := rl.Rectangle{X: cell.X + 1, Y: cell.Y + 1, Width: cell.Width - 2, Height: cell.Height - 2}
+			rl.DrawRectangleLinesEx(inset, 1, s.theme.DiffRemoved)
+		}
+	}
+	for _, r := range c.missing {
+		rl.DrawRectangleRec(onScreen(r), rl.ColorAlpha(s.theme.DiffRemoved, 0.35))
+	}
+	for _, r := range c.extra {
+		rl.DrawRectangleLinesEx(onScreen(r), 2, s.theme.Error)
+	}
+
+	// The legend names the two colors, and the summary what differs.
+	y := float32(cfg.startY) - 15
+	expected := tr(msgLegendExpected)
+	drawText(expected, 10, int32(y), 10, s.theme.DiffRemoved)
+	x := 10 + measureText(expected, 10) + 15
+	present := tr(msgLegendPresent)
+	drawText(present, x, int32(y), 10, s.theme.DiffChanged)
+	x += measureText(present, 10) + 15
+	drawText(c.summary(), x, int32(y), 10, s.theme.Text)
+}
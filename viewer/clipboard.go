@@ -0,0 +1,58 @@
+package viewer
+
+import (
+	"errors"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// copySpriteImage puts the pixels of the selected sprite on the system
+// clipboard as a PNG, so it can be pasted straight into an image editor or a
+// chat. With several sprites selected, the first in display order is copied.
+func (s *UIState) copySpriteImage() {
+	names := s.selectedNames()
+	if s.sheet == nil || len(names) == 0 {
+		s.notify(msgSelectSpriteToCopy)
+		return
+	}
+	name := names[0]
+
+	// The clipboard tools read the image from a file, which is removed once
+	// they have taken it.
+	f, err := os.CreateTemp("", "sprite-*.png")
+	if err != nil {
+		s.notify(msgCopyImageFailed, err)
+		return
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	if err := s.writeSpritePNG(name, path); err != nil {
+		s.notify(msgCopyImageFailed, err)
+		return
+	}
+	if err := copyPNGFile(path); err != nil {
+		s.notify(msgCopyImageFailed, err)
+		return
+	}
+	s.notify(msgCopiedImage, name)
+}
+
+// writeSpritePNG writes the pixels of the named sprite to path as a PNG.
+func (s *UIState) writeSpritePNG(name, path string) error {
+	rect := s.sheet.Sprites[name]
+	src := rl.LoadImage(s.currentFile)
+	if !rl.IsImageValid(src) {
+		return errors.New(trf(msgCouldNotRead, s.currentFile))
+	}
+	sprite := rl.ImageFromImage(*src, spriteSource(rect))
+	rl.UnloadImage(src)
+	defer rl.UnloadImage(&sprite)
+
+	if !rl.ExportImage(sprite, path) {
+		return errors.New(trf(msgCouldNotWrite, path))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package viewer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// copyPNGFile puts the PNG image at path on the clipboard using the
+// platform's clipboard tool: osascript on macOS, and wl-copy or xclip on
+// Linux depending on the display server.
+func copyPNGFile(path string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf(`set the clipboard to (read (POSIX file %q) as «class PNGf»)`, path)
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux":
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			cmd = exec.Command("wl-copy", "--type", "image/png")
+			cmd.Stdin = f
+		} else {
+			cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "image/png", "-i", path)
+		}
+	default:
+		return errors.New("copying images is not supported on " + runtime.GOOS)
+	}
+
+	// xclip and wl-copy stay in the background to serve the clipboard, so
+	// their output isn't captured: waiting for the pipes to close would block
+	// until something else is copied.
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", cmd.Args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package viewer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32   = syscall.NewLazyDLL("user32.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	procOpenClipboard           = user32.NewProc("OpenClipboard")
+	procCloseClipboard          = user32.NewProc("CloseClipboard")
+	procEmptyClipboard          = user32.NewProc("EmptyClipboard")
+	procSetClipboardData        = user32.NewProc("SetClipboardData")
+	procRegisterClipboardFormat = user32.NewProc("RegisterClipboardFormatW")
+	procGlobalAlloc             = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree              = kernel32.NewProc("GlobalFree")
+	procGlobalLock              = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock            = kernel32.NewProc("GlobalUnlock")
+	procRtlMoveMemory           = kernel32.NewProc("RtlMoveMemory")
+)
+
+const (
+	cfDIB        = 8
+	gmemMoveable = 0x0002
+)
+
+// copyPNGFile puts the PNG image at path on the clipboard through the Windows
+// clipboard API. The image is offered both as PNG, which keeps transparency
+// for applications that understand it, and as a device-independent bitmap
+// for everything else.
+func copyPNGFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	name, err := syscall.UTF16PtrFromString("PNG")
+	if err != nil {
+		return err
+	}
+	pngFormat, _, err := procRegisterClipboardFormat.Call(uintptr(unsafe.Pointer(name)))
+	if pngFormat == 0 {
+		return err
+	}
+
+	if r, _, err := procOpenClipboard.Call(0); r == 0 {
+		return err
+	}
+	defer procCloseClipboard.Call()
+
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return err
+	}
+	if err := setClipboardData(pngFormat, data); err != nil {
+		return err
+	}
+	return setClipboardData(cfDIB, dib(img))
+}
+
+// setClipboardData copies data into global memory and hands it to the
+// clipboard, which owns it from then on.
+func setClipboardData(format uintptr, data []byte) error {
+	h, _, err := procGlobalAlloc.Call(gmemMoveable, uintptr(len(data)))
+	if h == 0 {
+		return err
+	}
+	p, _, err := procGlobalLock.Call(h)
+	if p == 0 {
+		procGlobalFree.Call(h)
+		return err
+	}
+	procRtlMoveMemory.Call(p, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)))
+	procGlobalUnlock.Call(h)
+
+	if r, _, err := procSetClipboardData.Call(format, h); r == 0 {
+		procGlobalFree.Call(h)
+		if err == nil {
+			err = errors.New("SetClipboardData failed")
+		}
+		return err
+	}
+	return nil
+}
+
+// dib encodes img as a 32-bit bottom-up device-independent bitmap: a
+// BITMAPINFOHEADER followed by BGRA rows.
+func dib(img image.Image) []byte {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	var buf bytes.Buffer
+	header := struct {
+		Size          uint32
+		Width         int32
+		Height        int32
+		Planes        uint16
+		BitCount      uint16
+		Compression   uint32
+		SizeImage     uint32
+		XPelsPerMeter int32
+		YPelsPerMeter int32
+		ClrUsed       uint32
+		ClrImportant  uint32
+	}{Size: 40, Width: int32(w), Height: int32(h), Planes: 1, BitCount: 32, SizeImage: uint32(w * h * 4)}
+	binary.Write(&buf, binary.LittleEndian, header)
+
+	for y := b.Max.Y - 1; y >= b.Min.Y; y-- {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			buf.Write([]byte{byte(bl >> 8), byte(g >> 8), byte(r >> 8), byte(a >> 8)})
+		}
+	}
+	return buf.Bytes()
+}
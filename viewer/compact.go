@@ -0,0 +1,73 @@
+package viewer
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// compact returns the layout of compact mode, where the grid takes the whole
+// area without the header and status bar around it.
+func (cfg Config) compact() Config {
+	cfg.headerHeight = 0
+	cfg.startY = cfg.padding
+	cfg.viewportHeight = cfg.height - cfg.startY
+	return cfg
+}
+
+// SetCompact turns compact mode on or off. Compact mode drops the header,
+// tabs, panels and status bar and shows only the grid, or the selected
+// sprite on its own once exactly one is selected, for hosts that float the
+// viewer over another program as a reference. Clicking a thumbnail shows
+// that sprite, and clicking the sprite goes back to the grid.
+func (v *Viewer) SetCompact(on bool) {
+	v.compact = on
+}
+
+// Compact reports whether compact mode is on.
+func (v *Viewer) Compact() bool {
+	return v.compact
+}
+
+// layout returns the layout input and drawing use, compact or not.
+func (v *Viewer) layout() Config {
+	if v.compact {
+		return v.cfg.compact()
+	}
+	return v.cfg
+}
+
+// compactSprite returns the sprite compact mode shows on its own: the
+// selected one, if exactly one is selected.
+func (s *UIState) compactSprite() string {
+	if s.sheet == nil || len(s.selected) != 1 {
+		return ""
+	}
+	for name := range s.selected {
+		if _, ok := s.sheet.Sprites[name]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// renderCompact draws compact mode: the selected sprite scaled to fit the
+// area, in whole steps when it is enlarged so pixels stay square, or else the
+// grid.
+func (s *UIState) renderCompact(cfg Config) {
+	name := s.compactSprite()
+	if name == "" {
+		s.renderSprites(cfg.compact())
+		return
+	}
+
+	rect := s.sheet.Sprites[name]
+	room := rl.Rectangle{X: float32(cfg.padding), Y: float32(cfg.padding), Width: float32(cfg.width - 2*cfg.padding), Height: float32(cfg.height - 2*cfg.padding)}
+	scale := min(room.Width/float32(rect.Width), room.Height/float32(rect.Height))
+	if scale >= 1 {
+		scale = float32(int(scale))
+	}
+	w, h := float32(rect.Width)*scale, float32(rect.Height)*scale
+	dest := rl.Rectangle{X: room.X + (room.Width-w)/2, Y: room.Y + (room.Height-h)/2, Width: w, Height: h}
+	rl.DrawTexturePro(s.drawTexture(), s.texSource(spriteSource(rect)), dest, rl.Vector2{}, 0, rl.White)
+
+	if rl.IsMouseButtonPressed(rl.MouseLeftButton) && rl.CheckCollisionPointRec(mousePosition(), rl.Rectangle{Width: float32(cfg.width), Height: float32(cfg.height)}) {
+		s.selected = make(map[string]bool)
+	}
+}
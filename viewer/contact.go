@@ -0,0 +1,129 @@
+package viewer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Contact sheet layout. maxContactSize caps either side of the image, which
+// is rendered into a texture and GPUs commonly allow no larger ones.
+const (
+	contactPadding   = 8
+	contactLabelSize = 10
+	maxContactSize   = 16384
+)
+
+// contactLayout places the sprites of a contact sheet in a grid. Every cell
+// is as large as the largest sprite at the export scale, and as wide as the
+// longest label when labels are drawn.
+type contactLayout struct {
+	cols, rows             int32
+	spriteWidth            int32
+	spriteHeight           int32
+	columnWidth, rowHeight int32
+	labelHeight            int32
+}
+
+// contactLayout lays names out in as many columns as the sheet has, so the
+// contact sheet reads like the sheet itself.
+func (s *UIState) contactLayout(names []string) contactLayout {
+	var l contactLayout
+	for _, name := range names {
+		rect := s.sheet.Sprites[name]
+		l.spriteWidth = max(l.spriteWidth, rect.Width*s.exportScale)
+		l.spriteHeight = max(l.spriteHeight, rect.Height*s.exportScale)
+	}
+	l.columnWidth = l.spriteWidth
+	if s.contactLabels {
+		for _, name := range names {
+			l.columnWidth = max(l.columnWidth, measureText(name, contactLabelSize))
+		}
+		l.labelHeight = labelGap + int32(float32(contactLabelSize)*uiText.scale) + labelGap
+	}
+	l.columnWidth += contactPadding
+	l.rowHeight = l.spriteHeight + l.labelHeight + contactPadding
+
+	n := int32(len(names))
+	l.cols = min(max(s.slicing.cols, 1), n)
+	l.rows = (n + l.cols - 1) / l.cols
+	return l
+}
+
+// size returns the size of the contact sheet image.
+func (l contactLayout) size() (int32, int32) {
+	return contactPadding + l.cols*l.columnWidth, contactPadding + l.rows*l.rowHeight
+}
+
+// exportContactSheet asks for a destination folder and writes the sprites
+// shown in the grid, in display order, to one PNG at the export scale. The
+// sprites are drawn from the full-size sheet, whatever the preview shows,
+// with their names and cell outlines if those are turned on in the settings.
+// Rows fill from the right when the sheet reads right to left.
+func (s *UIState) exportContactSheet() {
+	if s.sheet == nil || len(s.spriteNames) == 0 {
+		s.notify(msgNothingToExport)
+		return
+	}
+	names := s.spriteNames
+	l := s.contactLayout(names)
+	width, height := l.size()
+	if width > maxContactSize || height > maxContactSize {
+		s.notify(msgContactTooLarge, width, height, maxContactSize)
+		return
+	}
+
+	dir := openDirectoryDialog()
+	if dir == "" {
+		return
+	}
+	tex := rl.LoadTexture(s.currentFile)
+	if tex.ID == 0 {
+		s.notify(msgExportReadFailed, filepath.Base(s.currentFile))
+		return
+	}
+	defer rl.UnloadTexture(tex)
+
+	target := rl.LoadRenderTexture(width, height)
+	defer rl.UnloadRenderTexture(target)
+	rl.BeginTextureMode(target)
+	rl.ClearBackground(s.theme.Background)
+	for i, name := range names {
+		col := int32(i) % l.cols
+		if s.rightToLeft {
+			col = l.cols - 1 - col
+		}
+		x := float32(contactPadding + col*l.columnWidth)
+		y := float32(contactPadding + int32(i)/l.cols*l.rowHeight)
+		cell := rl.Rectangle{X: x, Y: y, Width: float32(l.columnWidth - contactPadding), Height: float32(l.spriteHeight)}
+		rect := s.sheet.Sprites[name]
+		w, h := float32(rect.Width*s.exportScale), float32(rect.Height*s.exportScale)
+		dest := rl.Rectangle{X: cell.X + (cell.Width-w)/2, Y: cell.Y + (cell.Height-h)/2, Width: w, Height: h}
+		rl.DrawTexturePro(tex, spriteSource(rect), dest, rl.Vector2{}, 0, rl.White)
+		if s.contactGrid {
+			rl.DrawRectangleLinesEx(cell, 1, s.theme.CellBorder)
+		}
+		if s.contactLabels {
+			drawText(name, int32(cell.X), int32(cell.Y+cell.Height)+labelGap, contactLabelSize, s.theme.Text)
+		}
+	}
+	rl.EndTextureMode()
+
+	// Render textures are stored bottom-up.
+	img := rl.LoadImageFromTexture(target.Texture)
+	defer rl.UnloadImage(img)
+	rl.ImageFlipVertical(img)
+
+	base := strings.TrimSuffix(filepath.Base(s.currentFile), filepath.Ext(s.currentFile)) + "-contact"
+	path := filepath.Join(dir, base+".png")
+	if _, err := os.Stat(path); err == nil {
+		path = uniquePath(path)
+	}
+	if !rl.ExportImage(*img, path) {
+		s.notify(msgExportWriteFailed, path)
+		return
+	}
+	s.notify(msgWroteContactSheet, len(names), l.cols, l.rows, path, s.orderName())
+}
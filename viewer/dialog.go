@@ -0,0 +1,65 @@
+package viewer
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// dialogSeparator separates the paths a file dialog prints when several
+// files are chosen. Unlike the "|" zenity uses by default, the ASCII record
+// separator never turns up in a real file name.
+const dialogSeparator = "\x1e"
+
+// kdialogSeparator separates the paths kdialog prints with --separate-output,
+// which puts each on a line of its own.
+const kdialogSeparator = "\n"
+
+// linuxDialog returns the file dialog to run on Linux and the separator
+// between the paths it prints: zenity, given the zenity arguments, or
+// kdialog, given the kdialog ones, on desktops that only have that.
+func linuxDialog(zenity, kdialog []string) (*exec.Cmd, string) {
+	if _, err := exec.LookPath("zenity"); err != nil {
+		if _, err := exec.LookPath("kdialog"); err == nil {
+			return exec.Command("kdialog", kdialog...), kdialogSeparator
+		}
+	}
+	return exec.Command("zenity", zenity...), dialogSeparator
+}
+
+// runDialog runs a file dialog and returns the paths it printed, split at
+// sep, or nil if it was cancelled or couldn't be started.
+func runDialog(cmd *exec.Cmd, sep string) []string {
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return parseDialogOutput(string(output), sep)
+}
+
+// parseDialogOutput splits what a file dialog printed into paths at sep.
+// Only the line ending the dialog adds is removed, "\n" from zenity and
+// kdialog and "\r" or "\r\n" from osascript, so a name that starts or ends
+// with a space keeps it. A directory chosen with osascript comes with a
+// trailing slash, which filepath.Clean drops along with any other redundant
+// separators.
+func parseDialogOutput(output, sep string) []string {
+	output = strings.TrimSuffix(output, "\n")
+	output = strings.TrimSuffix(output, "\r")
+	var paths []string
+	for _, path := range strings.Split(output, sep) {
+		if path != "" {
+			paths = append(paths, filepath.Clean(path))
+		}
+	}
+	return paths
+}
+
+// runSingleDialog runs a file dialog that picks one path and returns it, or
+// "" if nothing was chosen.
+func runSingleDialog(cmd *exec.Cmd, sep string) string {
+	if paths := runDialog(cmd, sep); len(paths) > 0 {
+		return paths[0]
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package viewer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseDialogOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		sep    string
+		want   []string
+	}{
+		{"zenity", "/home/me/hero.png\n", dialogSeparator, []string{"/home/me/hero.png"}},
+		{"spaces", "/home/me/my sheets/hero walk .png\n", dialogSeparator, []string{"/home/me/my sheets/hero walk .png"}},
+		{"leading space", "/home/me/ hero.png\n", dialogSeparator, []string{"/home/me/ hero.png"}},
+		{"parentheses", "/home/me/hero (copy).png\n", dialogSeparator, []string{"/home/me/hero (copy).png"}},
+		{"unicode", "/home/me/スプライト/héros.png\n", dialogSeparator, []string{"/home/me/スプライト/héros.png"}},
+		{"pipe in name", "/home/me/a|b.png\n", dialogSeparator, []string{"/home/me/a|b.png"}},
+		{"osascript CR", "/Users/me/hero.png\r", dialogSeparator, []string{"/Users/me/hero.png"}},
+		{"osascript CRLF", "/Users/me/hero.png\r\n", dialogSeparator, []string{"/Users/me/hero.png"}},
+		{"osascript folder", "/Users/me/export/\n", dialogSeparator, []string{"/Users/me/export"}},
+		{"no line ending", "/home/me/hero.png", dialogSeparator, []string{"/home/me/hero.png"}},
+		{"redundant separators", "/home/me//sheets/./hero.png\n", dialogSeparator, []string{"/home/me/sheets/hero.png"}},
+		{"cancelled", "", dialogSeparator, nil},
+		{"only line ending", "\n", dialogSeparator, nil},
+		{
+			"zenity multiple",
+			"/home/me/a b.png\x1e/home/me/c|d.png\x1e/home/me/é.png\n",
+			dialogSeparator,
+			[]string{"/home/me/a b.png", "/home/me/c|d.png", "/home/me/é.png"},
+		},
+		{
+			"osascript multiple",
+			"/Users/me/a.png\x1e/Users/me/b c.png\x1e\n",
+			dialogSeparator,
+			[]string{"/Users/me/a.png", "/Users/me/b c.png"},
+		},
+		{"kdialog", "/home/me/hero walk.png\n", kdialogSeparator, []string{"/home/me/hero walk.png"}},
+		{
+			"kdialog multiple",
+			"/home/me/a.png\n/home/me/b c.png\n/home/me/ü.png\n",
+			kdialogSeparator,
+			[]string{"/home/me/a.png", "/home/me/b c.png", "/home/me/ü.png"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDialogOutput(tt.output, tt.sep); !slices.Equal(got, tt.want) {
+				t.Errorf("parseDialogOutput(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package viewer
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// sheetDiff is the result of comparing the loaded sheet against another
+// version of it, sliced with the same settings. Sprites are matched by name,
+// which the grid slicer derives from their position.
+type sheetDiff struct {
+	other    string
+	total    int
+	changed  map[string]bool
+	added    []string
+	removed  []string
+	sizeNote string
+	// isRemoved indexes removed for marking thumbnails.
+	isRemoved map[string]bool
+	// overlay marks every differing pixel of the loaded sheet in opaque white
+	// and everything else transparent, so it can be drawn over thumbnails
+	// tinted with the theme's color.
+	overlay rl.Texture2D
+}
+
+// summary describes the diff in one line.
+func (d *sheetDiff) summary() string {
+	text := trf(msgDiffSummary, len(d.changed), d.total, len(d.added), len(d.removed))
+	if d.sizeNote != "" {
+		text += " (" + d.sizeNote + ")"
+	}
+	return text
+}
+
+func (d *sheetDiff) close() {
+	if d.overlay.ID != 0 {
+		rl.UnloadTexture(d.overlay)
+	}
+}
+
+// compareWith diffs the loaded sheet against the image at path.
+func (s *UIState) compareWith(path string) {
+	if s.sheet == nil {
+		s.notify(msgCompareNeedsSheet)
+		return
+	}
+
+	a, err := s.sheetPixels()
+	if err != nil {
+		s.notify(msgCompareFailed, err)
+		return
+	}
+	b, err := loadPixels(path)
+	if err != nil {
+		s.notify(msgCompareFailed, err)
+		return
+	}
+
+	g, _ := s.slicingFor(b.width, b.height)
+	other := g.sprites()
+	d := diffSheets(a, b, s.sheet.Sprites, other)
+	d.other = path
+
+	if s.diff != nil {
+		s.diff.close()
+	}
+	s.diff = d
+	s.notifyText(d.summary())
+}
+
+// refreshDiff recomputes an active diff after the sheet was resliced.
+func (s *UIState) refreshDiff() {
+	if s.diff != nil {
+		s.compareWith(s.diff.other)
+	}
+}
+
+// closeDiff ends the comparison.
+func (s *UIState) closeDiff() {
+	if s.diff != nil {
+		s.diff.close()
+		s.diff = nil
+	}
+}
+
+// diffSheets compares the sprites of a and b. Images of different sizes are
+// reported rather than rejected; cells outside either image are counted as
+// added or removed.
+func diffSheets(a, b *sheetPixels, rectsA, rectsB map[string]resources.Rectangle) *sheetDiff {
+	d := &sheetDiff{total: len(rectsA), changed: make(map[string]bool), isRemoved: make(map[string]bool)}
+	if a.width != b.width || a.height != b.height {
+		d.sizeNote = trf(msgDiffSizes, a.width, a.height, b.width, b.height)
+	}
+
+	mask := make([]color.RGBA, len(a.pix))
+	for name, rect := range rectsA {
+		if _, ok := rectsB[name]; !ok || !b.contains(rect) {
+			d.removed = append(d.removed, name)
+			d.isRemoved[name] = true
+			continue
+		}
+		for y := rect.Y; y < rect.Y+rect.Height; y++ {
+			for x := rect.X; x < rect.X+rect.Width; x++ {
+				if a.at(x, y) != b.at(x, y) {
+					d.changed[name] = true
+					mask[y*a.width+x] = rl.White
+				}
+			}
+		}
+	}
+	for name := range rectsB {
+		if _, ok := rectsA[name]; !ok {
+			d.added = append(d.added, name)
+		}
+	}
+	sortNames(d.added)
+	sortNames(d.removed)
+
+	if len(d.changed) > 0 {
+		img := rl.NewImage(colorBytes(mask), a.width, a.height, 1, rl.UncompressedR8g8b8a8)
+		d.overlay = rl.LoadTextureFromImage(img)
+	}
+	return d
+}
+
+// colorBytes flattens colors into RGBA bytes.
+func colorBytes(colors []color.RGBA) []byte {
+	data := make([]byte, 0, len(colors)*4)
+	for _, c := range colors {
+		data = append(data, c.R, c.G, c.B, c.A)
+	}
+	return data
+}
+
+// exportDiffList asks for a folder and writes the changed, added and removed
+// sprite names there as a text file.
+func (s *UIState) exportDiffList() {
+	dir := openDirectoryDialog()
+	if dir == "" {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s vs %s\n# %s\n", filepath.Base(s.currentFile), filepath.Base(s.diff.other), s.diff.summary())
+	for _, name := range s.spriteNames {
+		if s.diff.changed[name] {
+			fmt.Fprintf(&b, "changed %s\n", name)
+		}
+	}
+	for _, name := range s.diff.added {
+		fmt.Fprintf(&b, "added %s\n", name)
+	}
+	for _, name := range s.diff.removed {
+		fmt.Fprintf(&b, "removed %s\n", name)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(s.currentFile), filepath.Ext(s.currentFile))
+	path := filepath.Join(dir, base+"-changes.txt")
+	if _, err := os.Stat(path); err == nil {
+		path = uniquePath(path)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		s.notify(msgExportFailed, err)
+		return
+	}
+	s.notify(msgWroteChangeList, path)
+}
+
+// drawDiffMarker highlights a changed thumbnail by drawing the per-pixel
+// difference overlay on top of it, and outlines a thumbnail the other image
+// doesn't have. Both also get a badge, so the two don't differ by color alone.
+func (s *UIState) drawDiffMarker(name string, dest rl.Rectangle) {
+	switch {
+	case s.diff.changed[name]:
+		rect := s.sheet.Sprites[name]
+		source := rl.Rectangle{X: float32(rect.X), Y: float32(rect.Y), Width: float32(rect.Width), Height: float32(rect.Height)}
+		rl.DrawTexturePro(s.diff.overlay, source, dest, rl.Vector2{}, 0, rl.ColorAlpha(s.theme.DiffChanged, 0.6))
+		rl.DrawRectangleLinesEx(dest, 1, s.theme.DiffChanged)
+		drawBadge(dest, "~", s.theme.DiffChanged)
+	case s.diff.isRemoved[name]:
+		rl.DrawRectangleLinesEx(dest, 1, s.theme.DiffRemoved)
+		drawBadge(dest, "-", s.theme.DiffRemoved)
+	}
+}
+
+// renderDiffBar draws the comparison summary under the header.
+func (s *UIState) renderDiffBar(cfg Config) rl.Rectangle {
+	y := float32(cfg.headerHeight + 1)
+	rl.DrawRectangle(0, int32(y), cfg.width, 24, rl.ColorAlpha(s.theme.Panel, 0.95))
+	text := trf(msgDiffVersus, filepath.Base(s.diff.other), s.diff.summary())
+	drawText(text, 10, int32(y)+7, 10, s.theme.Text)
+
+	closeWidth := buttonWidth(tr(msgClose), 80)
+	closeX := float32(cfg.width-10) - closeWidth
+	listWidth := buttonWidth(tr(msgExportList), 80)
+	if drawButton(rl.Rectangle{X: closeX - 10 - listWidth, Y: y + 2, Width: listWidth, Height: 20}, tr(msgExportList)) {
+		s.exportDiffList()
+	}
+	if drawButton(rl.Rectangle{X: closeX, Y: y + 2, Width: closeWidth, Height: 20}, tr(msgClose)) {
+		s.closeDiff()
+	}
+	return rl.Rectangle{Y: y, Width: float32(cfg.width), Height: 24}
+}
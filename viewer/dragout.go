@@ -0,0 +1,68 @@
+package viewer
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// outsideWindow reports whether the mouse has left the window, which it can
+// while a button is held.
+func outsideWindow() bool {
+	m := rl.GetMousePosition()
+	return m.X < 0 || m.Y < 0 || m.X >= float32(rl.GetScreenWidth()) || m.Y >= float32(rl.GetScreenHeight())
+}
+
+// fileURI returns the file:// URI of the absolute path.
+func fileURI(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		// Windows paths start with the drive letter.
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
+
+// dragOutPath returns the file the named sprite is written to when dragged
+// out. Each sprite keeps its file for the session, so dragging it out again
+// overwrites rather than piles up copies.
+func (s *UIState) dragOutPath(name string) (string, error) {
+	if s.dragOutDir == "" {
+		dir, err := os.MkdirTemp("", "spritesheet-viewer-*")
+		if err != nil {
+			return "", err
+		}
+		s.dragOutDir = dir
+	}
+	sheet := strings.TrimSuffix(filepath.Base(s.currentFile), filepath.Ext(s.currentFile))
+	file := fileBase(sheet + "_" + name)
+	return filepath.Join(s.dragOutDir, file+".png"), nil
+}
+
+// dragOut hands over a thumbnail dropped outside the window. raylib can't
+// start a native drag and drop, so the sprite is written to a PNG in a
+// temporary folder and the file's URI is copied instead, ready to paste into
+// a file manager or chat.
+func (s *UIState) dragOut(name string) {
+	path, err := s.dragOutPath(name)
+	if err == nil {
+		err = s.writeSpritePNG(name, path)
+	}
+	if err != nil {
+		s.notify(msgDragOutFailed, err)
+		return
+	}
+	rl.SetClipboardText(fileURI(path))
+	s.notify(msgDraggedOut, name)
+}
+
+// removeDragOutFiles deletes the files sprites were dragged out to.
+func (s *UIState) removeDragOutFiles() {
+	if s.dragOutDir != "" {
+		os.RemoveAll(s.dragOutDir)
+		s.dragOutDir = ""
+	}
+}
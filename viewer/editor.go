@@ -0,0 +1,134 @@
+package viewer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// editWatchInterval is how often, in seconds, a sheet open in an external
+// editor is checked for having been saved.
+const editWatchInterval = 0.5
+
+// editWatch watches the current sheet after it was handed to an external
+// editor, so saving it there reloads it here. modTime is the modification
+// time the sheet was last loaded with.
+type editWatch struct {
+	path      string
+	modTime   time.Time
+	checkedAt float64
+}
+
+// editorCommand returns the command that opens path in editor, or in the
+// system's default application for it when editor is empty.
+func editorCommand(editor, path string) *exec.Cmd {
+	switch {
+	case editor != "":
+		return exec.Command(editor, path)
+	case runtime.GOOS == "darwin":
+		return exec.Command("open", path)
+	case runtime.GOOS == "windows":
+		return exec.Command("cmd", "/c", "start", "", path)
+	default:
+		return exec.Command("xdg-open", path)
+	}
+}
+
+// editorName returns how the configured editor is named on the Edit button.
+func (s *UIState) editorName() string {
+	if s.editor == "" {
+		return tr(msgDefaultEditor)
+	}
+	return filepath.Base(s.editor)
+}
+
+// editSheet opens the current sheet in the external editor and watches it
+// for changes.
+func (s *UIState) editSheet() {
+	if s.launchEditor() {
+		s.notify(msgEditing, filepath.Base(s.currentFile), s.editorName())
+	}
+}
+
+// editSprite opens the current sheet in the external editor like editSheet,
+// and copies the position of the sprite under the mouse, or else the first
+// selected one, to the clipboard, so the editor can be taken straight to
+// it.
+func (s *UIState) editSprite() {
+	if s.sheet == nil {
+		s.notify(msgNoSheetToEdit)
+		return
+	}
+	var name string
+	if i := s.hover.cell; i >= 0 && i < len(s.spriteNames) {
+		name = s.spriteNames[i]
+	} else if names := s.selectedNames(); len(names) > 0 {
+		name = names[0]
+	} else {
+		s.notify(msgSelectSpriteToEdit)
+		return
+	}
+	if !s.launchEditor() {
+		return
+	}
+	rect := s.sheet.Sprites[name]
+	rl.SetClipboardText(fmt.Sprintf("%d, %d", rect.X, rect.Y))
+	s.notify(msgEditingSprite, name, rect.X, rect.Y, rect.Width, rect.Height, s.editorName())
+}
+
+// launchEditor starts the external editor on the current sheet and watches
+// the sheet for changes. The editor runs on its own; it is never waited on.
+// It reports whether the editor was started.
+func (s *UIState) launchEditor() bool {
+	if s.currentFile == "" {
+		s.notify(msgNoSheetToEdit)
+		return false
+	}
+	cmd := editorCommand(s.editor, s.currentFile)
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			s.notify(msgEditorNotFound, cmd.Path)
+		} else {
+			s.notify(msgEditorFailed, err)
+		}
+		return false
+	}
+	go cmd.Wait()
+
+	w := &editWatch{path: s.currentFile}
+	if info, err := os.Stat(s.currentFile); err == nil {
+		w.modTime = info.ModTime()
+	}
+	s.editWatch = w
+	return true
+}
+
+// pollEditWatch reloads the sheet once the external editor has saved it.
+// The watch stays armed for further saves until another sheet is opened. A
+// sheet that fails to load, as one still being written might, is tried
+// again on the next check.
+func (s *UIState) pollEditWatch() {
+	w := s.editWatch
+	if w == nil || rl.GetTime() < w.checkedAt+editWatchInterval {
+		return
+	}
+	w.checkedAt = rl.GetTime()
+	if w.path != s.currentFile {
+		s.editWatch = nil
+		return
+	}
+	info, err := os.Stat(w.path)
+	if err != nil || info.ModTime().Equal(w.modTime) {
+		return
+	}
+	if s.reload() {
+		w.modTime = info.ModTime()
+		s.notify(msgReloadedAfterEdit, filepath.Base(w.path))
+	}
+}
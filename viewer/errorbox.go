@@ -0,0 +1,163 @@
+package viewer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// maxBannerLines caps how many lines of an error the banner over a loaded
+// sheet shows. The copied text always holds all of it.
+const maxBannerLines = 6
+
+// errorReport keeps the last load error with what was loaded when it
+// happened, so it can be read and copied into a bug report after the sheet
+// has loaded fine again.
+type errorReport struct {
+	text     string
+	file     string
+	settings string
+	at       time.Time
+}
+
+// keepError records err as the last load error. The same error again, as a
+// watched file keeps failing, leaves the report as it was.
+func (s *UIState) keepError(err error) {
+	if s.lastError != nil && s.lastError.text == err.Error() {
+		return
+	}
+	s.lastError = &errorReport{
+		text:     err.Error(),
+		file:     s.currentFile,
+		settings: s.currentPreset().summary(),
+		at:       time.Now(),
+	}
+}
+
+// loadFailed records a failed load or reslice. The current sheet, if any,
+// stays shown.
+func (s *UIState) loadFailed(err error) {
+	if s.sheet != nil && err.Error() != s.loadError {
+		s.notify(msgReloadFailed, err)
+	}
+	s.loadError = err.Error()
+	s.keepError(err)
+}
+
+// clipboardText returns the error with its context and the build info for a
+// bug report. It is written in English whatever the UI language, for
+// whoever reads the report.
+func (e *errorReport) clipboardText(buildInfo []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Error: %s\n", e.text)
+	fmt.Fprintf(&b, "File: %s\n", e.file)
+	fmt.Fprintf(&b, "Settings: %s\n", e.settings)
+	fmt.Fprintf(&b, "Time: %s\n", e.at.Format(time.RFC3339))
+	for _, line := range buildInfo {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// copyError puts the last load error and its context on the clipboard.
+func (s *UIState) copyError() {
+	if s.lastError == nil {
+		return
+	}
+	rl.SetClipboardText(s.lastError.clipboardText(s.buildInfo()))
+	s.notify(msgCopiedError)
+}
+
+// wrapText breaks text into lines no wider than width at size, between words
+// where it can. Words wider than a line, such as long paths, are broken
+// wherever they have to be.
+func wrapText(text string, size, width int32) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if measureText(candidate, size) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for measureText(word, size) > width {
+			runes := []rune(word)
+			n := 1
+			for n < len(runes) && measureText(string(runes[:n+1]), size) <= width {
+				n++
+			}
+			lines = append(lines, string(runes[:n]))
+			word = string(runes[n:])
+		}
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// errorButtons draws the buttons for the last load error, right-aligned to
+// right, and handles their clicks.
+func (s *UIState) errorButtons(right, y, height float32) {
+	closeWidth := buttonWidth(tr(msgClose), 60)
+	closeX := right - closeWidth
+	copyWidth := buttonWidth(tr(msgCopyError), 80)
+	if drawButton(rl.Rectangle{X: closeX - 10 - copyWidth, Y: y, Width: copyWidth, Height: height}, tr(msgCopyError)) {
+		s.copyError()
+	}
+	if drawButton(rl.Rectangle{X: closeX, Y: y, Width: closeWidth, Height: height}, tr(msgClose)) {
+		s.lastError = nil
+	}
+}
+
+// errorButtonsWidth is the room errorButtons takes.
+func errorButtonsWidth() float32 {
+	return buttonWidth(tr(msgClose), 60) + 10 + buttonWidth(tr(msgCopyError), 80)
+}
+
+// renderError draws the last load error until it is closed. Without a sheet
+// it fills the viewport; over a loaded sheet it is a banner under the header,
+// which stays after the sheet has loaded fine again, marked as such. It
+// returns the area it takes.
+func (s *UIState) renderError(cfg Config) rl.Rectangle {
+	e := s.lastError
+	if s.sheet == nil {
+		width := cfg.width - 100
+		lines := wrapText(e.text, 20, width)
+		y := cfg.startY
+		for _, line := range lines {
+			drawText(line, 50, y, 20, s.theme.Error)
+			y += 24
+		}
+		s.errorButtons(float32(50+width), float32(y+10), 25)
+		return rl.Rectangle{X: 50, Y: float32(cfg.startY), Width: float32(width), Height: float32(y + 35 - cfg.startY)}
+	}
+
+	text := trf(msgReloadFailedBanner, e.text)
+	col := rl.ColorAlpha(s.theme.Warning, 0.85)
+	if s.loadError == "" {
+		text = trf(msgLoadErrorResolved, e.text)
+		col = rl.ColorAlpha(s.theme.MutedText, 0.85)
+	}
+	lines := wrapText(text, 10, cfg.width-30-int32(errorButtonsWidth()))
+	if len(lines) > maxBannerLines {
+		lines = append(lines[:maxBannerLines-1], tr(msgErrorTruncated))
+	}
+	y := cfg.headerHeight + 1
+	height := max(int32(24), int32(len(lines))*14+10)
+	rl.DrawRectangle(0, y, cfg.width, height, col)
+	for i, line := range lines {
+		drawText(line, 10, y+7+int32(i)*14, 10, rl.White)
+	}
+	s.errorButtons(float32(cfg.width-10), float32(y+2), 20)
+	return rl.Rectangle{Y: float32(y), Width: float32(cfg.width), Height: float32(height)}
+}
@@ -0,0 +1,434 @@
+package viewer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// exportItem is a single sprite to be written by an export job.
+type exportItem struct {
+	name string
+	rect resources.Rectangle
+	path string
+}
+
+// collisionPolicy decides what an export does with output files that already
+// exist in the target directory.
+type collisionPolicy int
+
+const (
+	overwriteExisting collisionPolicy = iota
+	skipExisting
+	renameExisting
+)
+
+// ExportNaming selects how exported sprite files are named.
+type ExportNaming int
+
+const (
+	// NameBySprite names each file after its sprite.
+	NameBySprite ExportNaming = iota
+	// NameByGrid names each file after the row and column of the sprite's
+	// cell, as in sprite_r03_c05.png.
+	NameByGrid
+	// NameByIndex numbers the files by the sprite's cell in reading order,
+	// as in sprite_017.png.
+	NameByIndex
+)
+
+// exportNamings lists the naming schemes in the order the settings panel
+// cycles through them, with their labels.
+var exportNamings = []struct {
+	naming ExportNaming
+	label  msgID
+}{
+	{NameBySprite, msgNameBySprite},
+	{NameByGrid, msgNameByGrid},
+	{NameByIndex, msgNameByIndex},
+}
+
+// exportBases returns the file name, without extension, that each of names
+// is exported under. Grid and index names come from the position of the
+// sprite's cell in g and are zero-padded so they sort in order, and sprite
+// names are taken from renames for the sprites that were renamed, unless the
+// new name isn't a file name. Sprites that share a cell, as Aseprite frames
+// can, get a numeric suffix.
+func exportBases(names []string, rects map[string]resources.Rectangle, renames map[string]string, g sheetSlicing, naming ExportNaming) []string {
+	digits := func(n, least int32) int {
+		return max(len(strconv.Itoa(int(n))), int(least))
+	}
+	rowDigits, colDigits := digits(g.rows-1, 2), digits(g.cols-1, 2)
+	indexDigits := digits(g.cols*g.rows-1, 3)
+
+	bases := make([]string, len(names))
+	seen := make(map[string]int, len(names))
+	for i, name := range names {
+		col, row := g.position(rects[name])
+		base := fileBase(name)
+		if r, ok := renames[name]; ok && validName(r) {
+			base = r
+		}
+		switch naming {
+		case NameByGrid:
+			base = fmt.Sprintf("sprite_r%0*d_c%0*d", rowDigits, row, colDigits, col)
+		case NameByIndex:
+			base = fmt.Sprintf("sprite_%0*d", indexDigits, row*g.cols+col)
+		}
+		if seen[base]++; seen[base] > 1 {
+			base = fmt.Sprintf("%s_%d", base, seen[base])
+		}
+		bases[i] = base
+	}
+	return bases
+}
+
+// fileBase makes name safe to use as a file name, replacing path separators
+// and a parent directory name with underscores.
+func fileBase(name string) string {
+	if name == ".." {
+		return "__"
+	}
+	return strings.NewReplacer("/", "_", `\`, "_").Replace(name)
+}
+
+// exportPrompt holds an export waiting on the user to decide how existing
+// files should be handled.
+type exportPrompt struct {
+	names      []string
+	bases      []string
+	dir        string
+	scale      int32
+	collisions []string
+}
+
+// exportStatus is a point-in-time view of an export job's progress.
+type exportStatus struct {
+	done      int
+	total     int
+	current   string
+	cancelled bool
+	finished  bool
+	// unwritten is the file a sprite couldn't be written to, which stopped
+	// the export. It is reported by the render loop, in the viewer's
+	// language.
+	unwritten string
+	// failed lists the animations of a batch GIF export that couldn't be
+	// written, each with its error.
+	failed []string
+}
+
+// exportProgress is shared between an export goroutine and the render loop,
+// which polls it every frame.
+type exportProgress struct {
+	mu     sync.Mutex
+	status exportStatus
+}
+
+func (p *exportProgress) update(fn func(st *exportStatus)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn(&p.status)
+}
+
+func (p *exportProgress) snapshot() exportStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// exportJob is a running export. Only one may exist at a time so that two
+// jobs never race on the same output directory.
+type exportJob struct {
+	dir     string
+	scale   int32
+	skipped int
+	// animations is set for a batch GIF export, which counts animations
+	// rather than sprites.
+	animations bool
+	cancel     context.CancelFunc
+	progress   *exportProgress
+}
+
+// startExport writes the named sprites as individual PNG files into dir,
+// upscaled by the current export scale. If any of the output files already
+// exist, the user is asked how to handle them before anything is written.
+func (s *UIState) startExport(names []string, dir string) {
+	if s.export != nil {
+		s.notify(msgExportRunning)
+		return
+	}
+	if s.sheet == nil || len(names) == 0 {
+		s.notify(msgNothingToExport)
+		return
+	}
+
+	scale := s.exportScale
+	bases := exportBases(names, s.sheet.Sprites, s.renames, s.slicing, s.exportNaming)
+	if collisions := findCollisions(dir, bases, scale); len(collisions) > 0 {
+		s.exportPrompt = &exportPrompt{names: names, bases: bases, dir: dir, scale: scale, collisions: collisions}
+		return
+	}
+	s.runExport(names, bases, dir, scale, overwriteExisting)
+}
+
+// runExport starts the export on a background goroutine, writing each of
+// names to the file named by the matching entry of bases and applying policy
+// to any files that already exist. The sheet is re-read from disk so the job
+// is not affected by reloads while it runs.
+func (s *UIState) runExport(names, bases []string, dir string, scale int32, policy collisionPolicy) {
+	src := rl.LoadImage(s.currentFile)
+	if !rl.IsImageValid(src) {
+		s.notify(msgExportReadFailed, filepath.Base(s.currentFile))
+		return
+	}
+
+	items, skipped := planExport(s.sheet.Sprites, names, bases, dir, scale, policy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &exportJob{dir: dir, scale: scale, skipped: skipped, cancel: cancel, progress: &exportProgress{}}
+	job.progress.status.total = len(items)
+	s.export = job
+
+	go job.run(ctx, src, items)
+}
+
+// exportPath returns the file a sprite is written to inside dir, base being
+// its file name without extension. Upscaled exports carry the factor in
+// their name so they never replace the 1x files.
+func exportPath(dir, base string, scale int32) string {
+	if scale > 1 {
+		return filepath.Join(dir, fmt.Sprintf("%s@%dx.png", base, scale))
+	}
+	return filepath.Join(dir, base+".png")
+}
+
+// findCollisions returns the output paths in dir that already exist.
+func findCollisions(dir string, bases []string, scale int32) []string {
+	var collisions []string
+	for _, base := range bases {
+		path := exportPath(dir, base, scale)
+		if _, err := os.Stat(path); err == nil {
+			collisions = append(collisions, path)
+		}
+	}
+	return collisions
+}
+
+// planExport resolves the output path of every sprite according to policy.
+// It returns the items to write and how many sprites were skipped.
+func planExport(rects map[string]resources.Rectangle, names, bases []string, dir string, scale int32, policy collisionPolicy) ([]exportItem, int) {
+	items := make([]exportItem, 0, len(names))
+	skipped := 0
+	for i, name := range names {
+		path := exportPath(dir, bases[i], scale)
+		if _, err := os.Stat(path); err == nil {
+			switch policy {
+			case skipExisting:
+				skipped++
+				continue
+			case renameExisting:
+				path = uniquePath(path)
+			}
+		}
+		items = append(items, exportItem{name: name, rect: rects[name], path: path})
+	}
+	return items, skipped
+}
+
+// uniquePath appends a numeric suffix to path until it names a file that
+// does not exist yet.
+func uniquePath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s_%d%s", base, n, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// run writes each item in order, scaled with nearest-neighbor filtering so
+// pixel art stays crisp, stopping at the first error or as soon as
+// the job is cancelled. Files written before a cancellation are kept.
+func (j *exportJob) run(ctx context.Context, src *rl.Image, items []exportItem) {
+	defer rl.UnloadImage(src)
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			j.progress.update(func(st *exportStatus) { st.cancelled = true })
+			break
+		}
+		j.progress.update(func(st *exportStatus) { st.current = item.name })
+
+		sprite := rl.ImageFromImage(*src, rl.Rectangle{
+			X:      float32(item.rect.X),
+			Y:      float32(item.rect.Y),
+			Width:  float32(item.rect.Width),
+			Height: float32(item.rect.Height),
+		})
+		if j.scale > 1 {
+			rl.ImageResizeNN(&sprite, sprite.Width*j.scale, sprite.Height*j.scale)
+		}
+		ok := rl.ExportImage(sprite, item.path)
+		rl.UnloadImage(&sprite)
+
+		if !ok {
+			j.progress.update(func(st *exportStatus) { st.unwritten = filepath.Base(item.path) })
+			break
+		}
+		j.progress.update(func(st *exportStatus) { st.done++ })
+	}
+
+	j.progress.update(func(st *exportStatus) { st.finished = true })
+}
+
+// pollExport reports a finished export job and clears it.
+func (s *UIState) pollExport() {
+	if s.export == nil {
+		return
+	}
+
+	st := s.export.progress.snapshot()
+	if !st.finished {
+		return
+	}
+
+	switch {
+	case s.export.animations:
+		s.reportAnimations(st)
+	case st.unwritten != "":
+		s.notify(msgExportFailedAfter, st.done, st.total, trf(msgCouldNotWrite, st.unwritten))
+	case st.cancelled:
+		s.notify(msgExportCancelled, st.done, st.total, s.export.dir)
+	case s.export.skipped > 0:
+		s.notify(msgExportedSkipped, st.done, s.export.dir, s.export.skipped)
+	default:
+		s.notify(msgExported, st.done, s.export.dir)
+	}
+
+	s.export.cancel()
+	s.export = nil
+}
+
+// cycleExportNaming switches to the next way of naming exported files.
+func (s *UIState) cycleExportNaming() {
+	for i, n := range exportNamings {
+		if n.naming == s.exportNaming {
+			s.exportNaming = exportNamings[(i+1)%len(exportNamings)].naming
+			return
+		}
+	}
+}
+
+// exportNamingLabel returns the label of the selected naming scheme.
+func (s *UIState) exportNamingLabel() string {
+	for _, n := range exportNamings {
+		if n.naming == s.exportNaming {
+			return tr(n.label)
+		}
+	}
+	return ""
+}
+
+// renderExportProgress draws the progress bar and Cancel button for the
+// running export into the right side of the status bar.
+func (s *UIState) renderExportProgress(cfg Config, right int32) {
+	st := s.export.progress.snapshot()
+	top := cfg.startY + cfg.viewportHeight
+
+	cancel := rl.Rectangle{Y: float32(top + 2), Width: buttonWidth(tr(msgCancel), 80), Height: 16}
+	cancel.X = float32(right) - cancel.Width
+	bar := rl.Rectangle{X: cancel.X - 230, Y: float32(top + 5), Width: 220, Height: 10}
+	rl.DrawRectangleRec(bar, s.theme.Panel)
+	if st.total > 0 {
+		filled := bar
+		filled.Width = bar.Width * float32(st.done) / float32(st.total)
+		rl.DrawRectangleRec(filled, s.theme.MutedText)
+	}
+	rl.DrawRectangleLinesEx(bar, 1, s.theme.CellBorder)
+
+	label := fmt.Sprintf("%d/%d %s", st.done, st.total, st.current)
+	drawText(label, int32(bar.X)-measureText(label, 10)-8, top+5, 10, s.theme.MutedText)
+
+	if drawButton(cancel, tr(msgCancel)) {
+		s.export.cancel()
+	}
+}
+
+// renderExportPrompt draws the dialog asking how to handle existing files
+// for a pending export, and starts the export once a choice is made.
+func (s *UIState) renderExportPrompt(cfg Config) rl.Rectangle {
+	p := s.exportPrompt
+	choices := []struct {
+		label  string
+		policy collisionPolicy
+	}{
+		{tr(msgOverwriteAll), overwriteExisting},
+		{tr(msgSkipExisting), skipExisting},
+		{tr(msgRenameNew), renameExisting},
+	}
+	cancelWidth := buttonWidth(tr(msgCancel), 90)
+	buttonsWidth := 10 + cancelWidth + 10
+	for _, c := range choices {
+		buttonsWidth += buttonWidth(c.label, 90) + 10
+	}
+	width := max(420, buttonsWidth+10)
+	panel := rl.Rectangle{X: (float32(cfg.width) - width) / 2, Y: float32(cfg.height)/2 - 100, Width: width, Height: 150}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(rl.LightGray, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, rl.Black)
+
+	title := trf(msgFilesExist, len(p.collisions), len(p.names))
+	drawText(title, int32(panel.X)+10, int32(panel.Y)+10, 15, rl.Black)
+	for i, path := range p.collisions {
+		if i == 3 {
+			drawText(trf(msgAndMore, len(p.collisions)-3), int32(panel.X)+10, int32(panel.Y)+35+int32(i)*14, 10, rl.DarkGray)
+			break
+		}
+		drawText(filepath.Base(path), int32(panel.X)+10, int32(panel.Y)+35+int32(i)*14, 10, rl.DarkGray)
+	}
+
+	buttonY := panel.Y + panel.Height - 35
+	x := panel.X + 10
+	for _, c := range choices {
+		w := buttonWidth(c.label, 90)
+		if drawButton(rl.Rectangle{X: x, Y: buttonY, Width: w, Height: 25}, c.label) {
+			s.exportPrompt = nil
+			s.runExport(p.names, p.bases, p.dir, p.scale, c.policy)
+			return panel
+		}
+		x += w + 10
+	}
+	if drawButton(rl.Rectangle{X: panel.X + panel.Width - 10 - cancelWidth, Y: buttonY, Width: cancelWidth, Height: 25}, tr(msgCancel)) {
+		s.exportPrompt = nil
+	}
+	return panel
+}
+
+func openDirectoryDialog() string {
+	var cmd *exec.Cmd
+	sep := dialogSeparator
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", `POSIX path of (choose folder with prompt "Choose an export folder:")`)
+	case "linux":
+		cmd, sep = linuxDialog(
+			[]string{"--file-selection", "--directory"},
+			[]string{"--getexistingdirectory", "."})
+	default:
+		return ""
+	}
+
+	return runSingleDialog(cmd, sep)
+}
@@ -0,0 +1,33 @@
+package viewer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ztkent/beam/resources"
+)
+
+func TestExportBasesStayInFolder(t *testing.T) {
+	g := newSlicing(68, 17, 16, 1)
+	sprites := g.sprites()
+	names := []string{"0_0", "0_1", "0_2", "0_3"}
+	renames := map[string]string{
+		"0_0": "../../x",
+		"0_1": `a\b`,
+		"0_2": "..",
+		"0_3": "hero walk",
+	}
+	got := exportBases(names, sprites, renames, g, NameBySprite)
+	want := []string{"0_0", "0_1", "0_2", "hero walk"}
+	if !slices.Equal(got, want) {
+		t.Errorf("exportBases = %q, want %q", got, want)
+	}
+
+	// Names that didn't come from a rename, such as Aseprite frame names,
+	// are made safe too.
+	sprites = map[string]resources.Rectangle{"walk/1": sprites["0_0"], "..": sprites["0_1"]}
+	got = exportBases([]string{"walk/1", ".."}, sprites, nil, g, NameBySprite)
+	if want := []string{"walk_1", "__"}; !slices.Equal(got, want) {
+		t.Errorf("exportBases = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,288 @@
+package viewer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// fileInfo is what the viewer reads from the sheet file itself, independent
+// of how raylib decoded it.
+type fileInfo struct {
+	size    int64
+	modTime time.Time
+	format  string
+	width   uint32
+	height  uint32
+	// details describes the encoding, such as bit depth and color type.
+	details string
+	// color holds the PNG's color space chunks.
+	color pngColor
+}
+
+// pngColor is what a PNG's ancillary chunks say about its color space.
+// raylib ignores all of them and shows the raw samples, which is why a file
+// can look different here than in the tool that saved it.
+type pngColor struct {
+	// gamma is the gAMA chunk's file gamma times 100000, or 0 without one.
+	gamma uint32
+	// srgb is set by an sRGB chunk, with its rendering intent.
+	srgb   bool
+	intent byte
+	// iccProfile names the embedded ICC profile; hasICC is set by an iCCP
+	// chunk even if the name is empty.
+	iccProfile string
+	hasICC     bool
+}
+
+// pngIntents names the rendering intents of a PNG sRGB chunk.
+var pngIntents = map[byte]string{
+	0: "perceptual",
+	1: "relative colorimetric",
+	2: "saturation",
+	3: "absolute colorimetric",
+}
+
+// pngColorTypes names the color types of a PNG IHDR chunk.
+var pngColorTypes = map[byte]string{
+	0: "grayscale",
+	2: "RGB",
+	3: "indexed",
+	4: "grayscale+alpha",
+	6: "RGBA",
+}
+
+// readFileInfo reads the size and modification time of the file at path and
+// parses its PNG or JPEG header.
+func readFileInfo(path string) (fileInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fileInfo{}, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return fileInfo{}, err
+	}
+	info := fileInfo{size: st.Size(), modTime: st.ModTime()}
+
+	r := bufio.NewReader(f)
+	magic, err := r.Peek(8)
+	switch {
+	case err == nil && string(magic) == "\x89PNG\r\n\x1a\n":
+		err = readPNGHeader(r, &info)
+	case len(magic) >= 2 && magic[0] == 0xFF && magic[1] == 0xD8:
+		err = readJPEGHeader(r, &info)
+	default:
+		info.format = "unknown"
+		err = nil
+	}
+	return info, err
+}
+
+// readPNGHeader parses the IHDR chunk, which the PNG format requires to come
+// first.
+func readPNGHeader(r io.Reader, info *fileInfo) error {
+	var hdr struct {
+		Signature   [8]byte
+		Length      uint32
+		Type        [4]byte
+		Width       uint32
+		Height      uint32
+		BitDepth    byte
+		ColorType   byte
+		Compression byte
+		Filter      byte
+		Interlace   byte
+	}
+	if err := binary.Read(r, binary.BigEndian, &hdr); err != nil {
+		return fmt.Errorf("reading PNG header: %w", err)
+	}
+	if string(hdr.Type[:]) != "IHDR" {
+		return errors.New("PNG does not start with an IHDR chunk")
+	}
+
+	colorType, ok := pngColorTypes[hdr.ColorType]
+	if !ok {
+		colorType = fmt.Sprintf("color type %d", hdr.ColorType)
+	}
+	interlace := "not interlaced"
+	if hdr.Interlace == 1 {
+		interlace = "Adam7 interlaced"
+	}
+	info.format = "PNG"
+	info.width, info.height = hdr.Width, hdr.Height
+	info.details = fmt.Sprintf("%d-bit %s, %s", hdr.BitDepth, colorType, interlace)
+
+	// Skip the IHDR checksum, then read the color chunks, which have to
+	// come before the image data.
+	if _, err := io.CopyN(io.Discard, r, 4); err != nil {
+		return fmt.Errorf("reading PNG header: %w", err)
+	}
+	return readPNGColor(r, &info.color)
+}
+
+// readPNGColor reads the chunks up to the first IDAT, keeping what gAMA, sRGB
+// and iCCP say.
+func readPNGColor(r io.Reader, c *pngColor) error {
+	for {
+		var chunk struct {
+			Length uint32
+			Type   [4]byte
+		}
+		if err := binary.Read(r, binary.BigEndian, &chunk); err != nil {
+			return fmt.Errorf("reading PNG chunks: %w", err)
+		}
+		typ := string(chunk.Type[:])
+		if typ == "IDAT" || typ == "IEND" {
+			return nil
+		}
+
+		var data []byte
+		switch typ {
+		case "gAMA", "sRGB", "iCCP":
+			// An iCCP chunk starts with the profile name, at most 79 bytes
+			// and a terminating zero; the compressed profile isn't needed.
+			n := min(chunk.Length, 80)
+			data = make([]byte, n)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return fmt.Errorf("reading PNG %s chunk: %w", typ, err)
+			}
+			chunk.Length -= n
+		}
+		switch {
+		case typ == "gAMA" && len(data) == 4:
+			c.gamma = binary.BigEndian.Uint32(data)
+		case typ == "sRGB" && len(data) == 1:
+			c.srgb, c.intent = true, data[0]
+		case typ == "iCCP":
+			c.hasICC = true
+			if i := bytes.IndexByte(data, 0); i >= 0 {
+				c.iccProfile = string(data[:i])
+			}
+		}
+		// The rest of the chunk and its checksum.
+		if _, err := io.CopyN(io.Discard, r, int64(chunk.Length)+4); err != nil {
+			return fmt.Errorf("reading PNG %s chunk: %w", typ, err)
+		}
+	}
+}
+
+// readJPEGHeader walks the JPEG markers up to the first start-of-frame
+// segment, which holds the image size and precision.
+func readJPEGHeader(r *bufio.Reader, info *fileInfo) error {
+	info.format = "JPEG"
+	if _, err := r.Discard(2); err != nil {
+		return err
+	}
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("reading JPEG header: %w", err)
+		}
+		if b != 0xFF {
+			continue
+		}
+		marker, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("reading JPEG header: %w", err)
+		}
+		// Fill bytes, and markers that carry no segment.
+		if marker == 0xFF || marker == 0x00 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD9) {
+			if marker == 0xFF {
+				r.UnreadByte()
+			}
+			continue
+		}
+
+		var length uint16
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return fmt.Errorf("reading JPEG header: %w", err)
+		}
+		isSOF := marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC
+		if !isSOF {
+			if _, err := r.Discard(int(length) - 2); err != nil {
+				return fmt.Errorf("reading JPEG header: %w", err)
+			}
+			continue
+		}
+
+		var sof struct {
+			Precision  byte
+			Height     uint16
+			Width      uint16
+			Components byte
+		}
+		if err := binary.Read(r, binary.BigEndian, &sof); err != nil {
+			return fmt.Errorf("reading JPEG header: %w", err)
+		}
+		mode := "baseline"
+		if marker == 0xC2 || marker == 0xC6 || marker == 0xCA || marker == 0xCE {
+			mode = "progressive"
+		}
+		info.width, info.height = uint32(sof.Width), uint32(sof.Height)
+		info.details = fmt.Sprintf("%d-bit, %d components, %s", sof.Precision, sof.Components, mode)
+		return nil
+	}
+}
+
+// lines describes the file for the sheet info report.
+func (f fileInfo) lines() []string {
+	lines := []string{
+		trf(msgReportFile, formatBytes(f.size), f.modTime.Format("2006-01-02 15:04")),
+	}
+	if f.width != 0 {
+		lines = append(lines, trf(msgReportHeader, f.format, f.width, f.height, f.details))
+	} else {
+		lines = append(lines, trf(msgReportFormat, f.format))
+	}
+	if f.format == "PNG" {
+		lines = append(lines, f.color.line())
+	}
+	return lines
+}
+
+// line describes the color chunks for the sheet info report, warning about
+// a gamma that changes how the sheet should look.
+func (c pngColor) line() string {
+	var parts []string
+	if c.srgb {
+		intent, ok := pngIntents[c.intent]
+		if !ok {
+			intent = fmt.Sprintf("intent %d", c.intent)
+		}
+		parts = append(parts, fmt.Sprintf("sRGB (%s)", intent))
+	}
+	if c.hasICC {
+		parts = append(parts, fmt.Sprintf("ICC profile %q", c.iccProfile))
+	}
+	if c.gamma != 0 {
+		parts = append(parts, fmt.Sprintf("gamma %s", c.gammaText()))
+	}
+	if len(parts) == 0 {
+		return tr(msgReportNoColor)
+	}
+	line := trf(msgReportColor, strings.Join(parts, ", "))
+	if c.nonstandardGamma() {
+		line += " " + tr(msgReportGammaWarning)
+	}
+	return line
+}
+
+// formatBytes formats a file size for display.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,167 @@
+package viewer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// Default values of the font strip export options.
+const (
+	defaultFontSpacing  int32 = 1
+	defaultFontBaseline int32 = 0
+)
+
+// fontGlyph is the metrics entry of one glyph in a font strip. Offsets let a
+// renderer put the trimmed glyph back where it sat in its cell.
+type fontGlyph struct {
+	Name   string `json:"name"`
+	X      int32  `json:"x"`
+	Y      int32  `json:"y"`
+	Width  int32  `json:"width"`
+	Height int32  `json:"height"`
+	// XOffset is the distance from the left of the cell to the glyph.
+	XOffset int32 `json:"xOffset"`
+	// YOffset is the distance from the baseline to the top of the glyph,
+	// negative above the baseline.
+	YOffset int32 `json:"yOffset"`
+	Advance int32 `json:"advance"`
+}
+
+// fontMetrics describes a font strip image.
+type fontMetrics struct {
+	Image      string      `json:"image"`
+	LineHeight int32       `json:"lineHeight"`
+	Baseline   int32       `json:"baseline"`
+	Spacing    int32       `json:"spacing"`
+	Glyphs     []fontGlyph `json:"glyphs"`
+}
+
+// buildFontStrip lays the named sprites of src out in one row, trimmed to
+// their content and aligned on a common baseline. baseline is measured up
+// from the bottom of each cell, and spacing is the gap between glyphs. It
+// also returns the source rectangle of each glyph, in the same order.
+// Fully transparent sprites are left out.
+func buildFontStrip(src *rl.Image, image string, rects map[string]resources.Rectangle, names []string, baseline, spacing int32, alpha uint8) (fontMetrics, []rl.Rectangle) {
+	type glyph struct {
+		name      string
+		cell      resources.Rectangle
+		content   atlasRect
+		baselineY int32
+	}
+	var glyphs []glyph
+	var ascent, descent int32
+	pixels := imagePixels(src, alpha)
+	for _, name := range names {
+		rect := rects[name]
+		content := pixels.contentBounds(rect)
+		if content.W == 0 || content.H == 0 {
+			continue
+		}
+		baselineY := rect.Height - baseline
+		ascent = max(ascent, baselineY-content.Y)
+		descent = max(descent, content.Y+content.H-baselineY)
+		glyphs = append(glyphs, glyph{name, rect, content, baselineY})
+	}
+
+	m := fontMetrics{Image: image, LineHeight: ascent + descent, Baseline: ascent, Spacing: spacing}
+	sources := make([]rl.Rectangle, 0, len(glyphs))
+	x := int32(0)
+	for _, g := range glyphs {
+		m.Glyphs = append(m.Glyphs, fontGlyph{
+			Name:    g.name,
+			X:       x,
+			Y:       ascent - (g.baselineY - g.content.Y),
+			Width:   g.content.W,
+			Height:  g.content.H,
+			XOffset: g.content.X,
+			YOffset: g.content.Y - g.baselineY,
+			Advance: g.content.W + spacing,
+		})
+		sources = append(sources, rl.Rectangle{
+			X:      float32(g.cell.X + g.content.X),
+			Y:      float32(g.cell.Y + g.content.Y),
+			Width:  float32(g.content.W),
+			Height: float32(g.content.H),
+		})
+		x += g.content.W + spacing
+	}
+	return m, sources
+}
+
+// stripWidth returns the width of the image holding the glyphs of m.
+func (m fontMetrics) stripWidth() int32 {
+	if len(m.Glyphs) == 0 {
+		return 0
+	}
+	last := m.Glyphs[len(m.Glyphs)-1]
+	return last.X + last.Width
+}
+
+// exportFontStrip asks for a destination folder and writes the selected
+// sprites there as a font strip image with a JSON metrics file.
+func (s *UIState) exportFontStrip() {
+	if s.sheet == nil {
+		s.notify(msgNothingToExport)
+		return
+	}
+	names := s.selectedNames()
+	if len(names) == 0 {
+		s.notify(msgSelectGlyphs)
+		return
+	}
+
+	dir := openDirectoryDialog()
+	if dir == "" {
+		return
+	}
+
+	src := rl.LoadImage(s.currentFile)
+	if !rl.IsImageValid(src) {
+		s.notify(msgExportReadFailed, filepath.Base(s.currentFile))
+		return
+	}
+	defer rl.UnloadImage(src)
+
+	base := strings.TrimSuffix(filepath.Base(s.currentFile), filepath.Ext(s.currentFile)) + "-font"
+	imagePath := filepath.Join(dir, base+".png")
+	metricsPath := filepath.Join(dir, base+".json")
+	_, imageErr := os.Stat(imagePath)
+	_, metricsErr := os.Stat(metricsPath)
+	if imageErr == nil || metricsErr == nil {
+		imagePath = uniquePath(imagePath)
+		metricsPath = strings.TrimSuffix(imagePath, ".png") + ".json"
+	}
+
+	m, sources := buildFontStrip(src, filepath.Base(imagePath), s.sheet.Sprites, names, s.fontBaseline, s.fontSpacing, s.alphaCutoff())
+	if len(m.Glyphs) == 0 {
+		s.notify(msgGlyphsEmpty)
+		return
+	}
+
+	strip := rl.GenImageColor(int(m.stripWidth()), int(m.LineHeight), rl.Blank)
+	defer rl.UnloadImage(strip)
+	for i, g := range m.Glyphs {
+		dst := rl.Rectangle{X: float32(g.X), Y: float32(g.Y), Width: float32(g.Width), Height: float32(g.Height)}
+		rl.ImageDraw(strip, src, sources[i], dst, rl.White)
+	}
+	if !rl.ExportImage(*strip, imagePath) {
+		s.notify(msgExportWriteFailed, imagePath)
+		return
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		s.notify(msgExportFailed, err)
+		return
+	}
+	if err := os.WriteFile(metricsPath, data, 0o644); err != nil {
+		s.notify(msgExportFailed, err)
+		return
+	}
+	s.notify(msgWroteGlyphs, len(m.Glyphs), imagePath)
+}
@@ -0,0 +1,118 @@
+package viewer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"path/filepath"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Gamma values as a PNG gAMA chunk stores them, times 100000. Files saved
+// for an sRGB display store 1/2.2; anything further off than gammaTolerance
+// makes the art tool show the sheet differently than raylib does.
+const (
+	srgbGamma      = 45455
+	gammaTolerance = 1000
+)
+
+// displayGamma is the gamma of the display raylib's raw samples are shown on.
+const displayGamma = 2.2
+
+// nonstandardGamma reports whether the file asks for a gamma other than the
+// sRGB one. An sRGB or iCCP chunk takes precedence over gAMA in PNG
+// decoders, so the gamma only counts without them.
+func (c pngColor) nonstandardGamma() bool {
+	if c.gamma == 0 || c.srgb || c.hasICC {
+		return false
+	}
+	d := int64(c.gamma) - srgbGamma
+	return d > gammaTolerance || d < -gammaTolerance
+}
+
+// gammaText formats the file gamma the way art tools show it, such as
+// "0.45455".
+func (c pngColor) gammaText() string {
+	return fmt.Sprintf("%.5g", float64(c.gamma)/100000)
+}
+
+// gammaTable maps each sample to what a color managed decoder would show for
+// the file gamma: decoded to linear light with it and encoded again for the
+// display.
+func (c pngColor) gammaTable() [256]uint8 {
+	exp := 1 / (float64(c.gamma) / 100000 * displayGamma)
+	var table [256]uint8
+	for i := range table {
+		table[i] = uint8(math.Round(255 * math.Pow(float64(i)/255, exp)))
+	}
+	return table
+}
+
+// gammaCorrected returns pix as a color managed decoder would show it, or pix
+// itself unless gamma correction is on and the file asks for it.
+func (s *UIState) gammaCorrected(pix []color.RGBA) []color.RGBA {
+	c := s.fileInfo.color
+	if !s.gammaCorrect || !c.nonstandardGamma() {
+		return pix
+	}
+	table := c.gammaTable()
+	out := make([]color.RGBA, len(pix))
+	for i, p := range pix {
+		out[i] = color.RGBA{R: table[p.R], G: table[p.G], B: table[p.B], A: p.A}
+	}
+	return out
+}
+
+// refreshGamma rebuilds the gamma corrected texture after the sheet was
+// loaded, warning once per file about a gamma the sheet isn't shown with.
+func (s *UIState) refreshGamma() {
+	s.unloadGamma()
+	c := s.fileInfo.color
+	if !c.nonstandardGamma() {
+		return
+	}
+	if !s.gammaCorrect {
+		if s.warnedGamma != s.currentFile {
+			s.warnedGamma = s.currentFile
+			s.notify(msgNonstandardGamma, filepath.Base(s.currentFile), c.gammaText())
+		}
+		return
+	}
+	p, err := s.sheetPixels()
+	if err != nil {
+		s.notify(msgGammaFailed, err)
+		return
+	}
+	s.gammaTex = s.uploadPixels(s.gammaCorrected(p.pix), p.width, p.height)
+}
+
+// toggleGammaCorrection shows the sheet corrected for the gamma its file
+// asks for, or the raw samples again.
+func (s *UIState) toggleGammaCorrection() {
+	s.gammaCorrect = !s.gammaCorrect
+	if s.sheet == nil {
+		return
+	}
+	c := s.fileInfo.color
+	if !c.nonstandardGamma() {
+		s.notify(msgNoFileGamma, filepath.Base(s.currentFile))
+		return
+	}
+	s.refreshGamma()
+	s.refreshRecolor()
+	s.refreshBackgroundKey()
+	if s.gammaCorrect {
+		s.notify(msgGammaCorrected, c.gammaText())
+	} else {
+		s.notify(msgGammaRaw)
+	}
+}
+
+// unloadGamma releases the gamma corrected texture, if any.
+func (s *UIState) unloadGamma() {
+	if s.gammaTex.ID != 0 {
+		rl.UnloadTexture(s.gammaTex)
+		s.gammaTex = rl.Texture2D{}
+	}
+}
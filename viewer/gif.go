@@ -0,0 +1,282 @@
+package viewer
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ztkent/beam/resources"
+)
+
+// animationGroup is a named run of sprites played as one animation, in play
+// order.
+type animationGroup struct {
+	name   string
+	frames []string
+}
+
+// gifAnimation is one GIF of a batch export: the source rectangles of its
+// frames in play order, how long each is shown in hundredths of a second,
+// as GIF counts time, and the file it is written to.
+type gifAnimation struct {
+	name   string
+	frames []resources.Rectangle
+	delays []int
+	path   string
+}
+
+// animationGroups returns the animations of the sheet: the tags of its
+// Aseprite sidecar if it has any, played in their direction, and otherwise
+// the sprites the grid shows grouped by name prefix, in display order.
+// Groups of a single frame aren't animations and are left out.
+func (s *UIState) animationGroups() []animationGroup {
+	var groups []animationGroup
+	if s.aseprite != nil && len(s.aseprite.tags) > 0 {
+		names := s.aseprite.names
+		for _, tag := range s.aseprite.tags {
+			first, last := slices.Index(names, tag.first), slices.Index(names, tag.last)
+			if first < 0 || last < 0 {
+				continue
+			}
+			if first > last {
+				first, last = last, first
+			}
+			frames := slices.Clone(names[first : last+1])
+			switch tag.direction {
+			case playReverse:
+				slices.Reverse(frames)
+			case playPingPong:
+				for i := len(frames) - 2; i > 0; i-- {
+					frames = append(frames, frames[i])
+				}
+			}
+			groups = append(groups, animationGroup{name: tag.name, frames: frames})
+		}
+	} else {
+		grouped, sections := groupByPrefix(s.spriteNames)
+		for _, sec := range sections {
+			groups = append(groups, animationGroup{name: sec.prefix, frames: grouped[sec.start:sec.end]})
+		}
+	}
+	return slices.DeleteFunc(groups, func(g animationGroup) bool { return len(g.frames) < 2 })
+}
+
+// gifDelay returns how long the named sprite is shown in an exported GIF, in
+// hundredths of a second: its frame duration, or one frame at the animation
+// preview's FPS, and never less than the shortest delay GIF can hold.
+func (s *UIState) gifDelay(name string) int {
+	ms, ok := s.frameDuration(name)
+	if !ok {
+		ms = 1000 / s.anim.fps
+	}
+	return max(1, int(ms+5)/10)
+}
+
+// gifFileName returns a file name for the animation, without directory or
+// extension. Characters that can't be used in file names on every system,
+// as Aseprite tag names can hold, are replaced.
+func gifFileName(sheet, animation string) string {
+	clean := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, animation)
+	return sheet + "-" + clean
+}
+
+// exportAnimations asks for a destination folder and writes every animation
+// of the sheet there as a looping GIF, upscaled by the export scale, on a
+// background job. Each GIF is named after the sheet and its animation, and
+// one that fails to write doesn't stop the others.
+func (s *UIState) exportAnimations() {
+	if s.export != nil {
+		s.notify(msgExportRunning)
+		return
+	}
+	if s.sheet == nil {
+		s.notify(msgNothingToExport)
+		return
+	}
+	groups := s.animationGroups()
+	if len(groups) == 0 {
+		s.notify(msgNoAnimations)
+		return
+	}
+
+	dir := openDirectoryDialog()
+	if dir == "" {
+		return
+	}
+	// The sheet is read again so the job isn't affected by reloads while it
+	// runs, as with other exports.
+	pixels, err := loadPixels(s.currentFile)
+	if err != nil {
+		s.notify(msgExportReadFailed, filepath.Base(s.currentFile))
+		return
+	}
+
+	base := strings.TrimSuffix(filepath.Base(s.currentFile), filepath.Ext(s.currentFile))
+	anims := make([]gifAnimation, 0, len(groups))
+	used := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		a := gifAnimation{name: g.name}
+		for _, name := range g.frames {
+			a.frames = append(a.frames, s.sheet.Sprites[name])
+			a.delays = append(a.delays, s.gifDelay(name))
+		}
+		// Existing files are kept, as are the files of animations earlier
+		// in the batch that share the name.
+		file := gifFileName(base, g.name)
+		a.path = filepath.Join(dir, file+".gif")
+		for n := 1; used[a.path] || exists(a.path); n++ {
+			a.path = filepath.Join(dir, fmt.Sprintf("%s_%d.gif", file, n))
+		}
+		used[a.path] = true
+		anims = append(anims, a)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &exportJob{dir: dir, scale: s.exportScale, animations: true, cancel: cancel, progress: &exportProgress{}}
+	job.progress.status.total = len(anims)
+	s.export = job
+
+	go job.runAnimations(ctx, pixels, anims)
+}
+
+// exists reports whether a file is at path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// runAnimations writes each animation in order, stopping as soon as the job
+// is cancelled. An animation that can't be written is recorded in the job's
+// status and the next one is tried, so done counts the animations attempted.
+func (j *exportJob) runAnimations(ctx context.Context, pixels *sheetPixels, anims []gifAnimation) {
+	for _, a := range anims {
+		if ctx.Err() != nil {
+			j.progress.update(func(st *exportStatus) { st.cancelled = true })
+			break
+		}
+		j.progress.update(func(st *exportStatus) { st.current = a.name })
+
+		if err := writeGIF(pixels, a, j.scale); err != nil {
+			j.progress.update(func(st *exportStatus) { st.failed = append(st.failed, fmt.Sprintf("%s: %v", a.name, err)) })
+		}
+		j.progress.update(func(st *exportStatus) { st.done++ })
+	}
+
+	j.progress.update(func(st *exportStatus) { st.finished = true })
+}
+
+// gifPalette returns the palette for the frames of an animation, with the
+// transparent color first. An animation using no more colors than a GIF can
+// hold keeps them exactly, as pixel art usually does; one with more is mapped
+// to the nearest colors of a fixed palette.
+func gifPalette(pixels *sheetPixels, frames []resources.Rectangle) color.Palette {
+	pal := color.Palette{color.RGBA{}}
+	seen := make(map[color.RGBA]bool)
+	for _, r := range frames {
+		for y := r.Y; y < r.Y+r.Height; y++ {
+			for x := r.X; x < r.X+r.Width; x++ {
+				c, ok := gifColor(pixels.at(x, y))
+				if !ok || seen[c] {
+					continue
+				}
+				if len(pal) == 256 {
+					return append(color.Palette{color.RGBA{}}, palette.Plan9[:255]...)
+				}
+				seen[c] = true
+				pal = append(pal, c)
+			}
+		}
+	}
+	return pal
+}
+
+// gifColor returns the opaque color a pixel has in a GIF, which has no
+// partial transparency, and reports false for a pixel that is more than half
+// transparent and left out.
+func gifColor(c color.RGBA) (color.RGBA, bool) {
+	if c.A < 128 {
+		return color.RGBA{}, false
+	}
+	c.A = 255
+	return c, true
+}
+
+// writeGIF writes the frames of a to its file as a GIF that loops forever,
+// each frame scaled up by scale with nearest-neighbor filtering. Frames are
+// placed at the top left of a canvas as large as the largest of them, and
+// each is cleared before the next so transparent pixels don't show the frame
+// before.
+func writeGIF(pixels *sheetPixels, a gifAnimation, scale int32) error {
+	var width, height int32
+	for _, r := range a.frames {
+		if !pixels.contains(r) {
+			return fmt.Errorf("frame %dx%d at %d,%d is outside the image", r.Width, r.Height, r.X, r.Y)
+		}
+		width, height = max(width, r.Width), max(height, r.Height)
+	}
+
+	pal := gifPalette(pixels, a.frames)
+	index := make(map[color.RGBA]uint8, len(pal))
+	out := &gif.GIF{}
+	for i, r := range a.frames {
+		img := image.NewPaletted(image.Rect(0, 0, int(width*scale), int(height*scale)), pal)
+		for y := int32(0); y < r.Height; y++ {
+			for x := int32(0); x < r.Width; x++ {
+				c, ok := gifColor(pixels.at(r.X+x, r.Y+y))
+				if !ok {
+					continue
+				}
+				idx, found := index[c]
+				if !found {
+					idx = uint8(pal.Index(c))
+					index[c] = idx
+				}
+				for dy := int32(0); dy < scale; dy++ {
+					row := img.Pix[int(y*scale+dy)*img.Stride:]
+					for dx := int32(0); dx < scale; dx++ {
+						row[x*scale+dx] = idx
+					}
+				}
+			}
+		}
+		out.Image = append(out.Image, img)
+		out.Delay = append(out.Delay, a.delays[i])
+		out.Disposal = append(out.Disposal, gif.DisposalBackground)
+	}
+
+	f, err := os.Create(a.path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, out); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// reportAnimations reports a finished batch GIF export, naming the
+// animations that failed.
+func (s *UIState) reportAnimations(st exportStatus) {
+	written := st.done - len(st.failed)
+	switch {
+	case len(st.failed) > 0:
+		s.notify(msgGIFsFailed, written, st.total, s.export.dir, len(st.failed), strings.Join(st.failed, "; "))
+	case st.cancelled:
+		s.notify(msgGIFsCancelled, written, st.total, s.export.dir)
+	default:
+		s.notify(msgExportedGIFs, written, s.export.dir)
+	}
+}
@@ -0,0 +1,95 @@
+package viewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// ExportOptions configures ExportSheet.
+type ExportOptions struct {
+	Margin   int32
+	GridSize int32
+	// Scale is the integer upscale factor applied to every sprite.
+	Scale int32
+	// Overwrite replaces files that already exist.
+	Overwrite bool
+	// SkipExisting leaves files that already exist untouched.
+	SkipExisting bool
+	// Naming selects how the files are named. By default each is named
+	// after its sprite.
+	Naming ExportNaming
+}
+
+// ExportResult reports what ExportSheet wrote.
+type ExportResult struct {
+	Written int
+	Skipped int
+}
+
+// CollisionError is returned by ExportSheet when sprites would replace files
+// that already exist and neither Overwrite nor SkipExisting was set.
+type CollisionError struct {
+	Paths []string
+}
+
+func (e *CollisionError) Error() string {
+	return fmt.Sprintf("%d files already exist", len(e.Paths))
+}
+
+// ExportSheet slices the sheet at path and writes every sprite into dir
+// without opening a window. Nothing is written when it returns a
+// *CollisionError.
+func ExportSheet(path, dir string, opts ExportOptions) (ExportResult, error) {
+	if opts.Scale < 1 {
+		return ExportResult{}, errors.New("scale must be at least 1")
+	}
+	if opts.Overwrite && opts.SkipExisting {
+		return ExportResult{}, errors.New("overwrite and skip existing cannot be combined")
+	}
+
+	src := rl.LoadImage(path)
+	if !rl.IsImageValid(src) {
+		return ExportResult{}, fmt.Errorf("could not read %s", path)
+	}
+
+	if src.Width < opts.GridSize || src.Height < opts.GridSize {
+		rl.UnloadImage(src)
+		return ExportResult{}, fmt.Errorf("image %dx%d too small for grid size %d", src.Width, src.Height, opts.GridSize)
+	}
+
+	slicing := newSlicing(src.Width, src.Height, opts.GridSize, opts.Margin)
+	rects := slicing.sprites()
+	names := sortedSpriteNames(rects)
+	if len(names) == 0 {
+		rl.UnloadImage(src)
+		return ExportResult{}, fmt.Errorf("no sprites found in %s with grid %d and margin %d", path, opts.GridSize, opts.Margin)
+	}
+
+	bases := exportBases(names, rects, nil, slicing, opts.Naming)
+	policy := overwriteExisting
+	switch {
+	case opts.SkipExisting:
+		policy = skipExisting
+	case !opts.Overwrite:
+		if collisions := findCollisions(dir, bases, opts.Scale); len(collisions) > 0 {
+			rl.UnloadImage(src)
+			return ExportResult{}, &CollisionError{Paths: collisions}
+		}
+	}
+
+	items, skipped := planExport(rects, names, bases, dir, opts.Scale, policy)
+	job := &exportJob{dir: dir, scale: opts.Scale, skipped: skipped, progress: &exportProgress{}}
+	job.run(context.Background(), src, items)
+
+	st := job.progress.snapshot()
+	result := ExportResult{Written: st.done, Skipped: skipped}
+	if st.unwritten != "" {
+		// Headless errors are in English, whatever language a viewer in the
+		// same program draws in.
+		return result, fmt.Errorf("export failed after %d of %d sprites: %s", st.done, len(items), fmt.Sprintf(english[msgCouldNotWrite], st.unwritten))
+	}
+	return result, nil
+}
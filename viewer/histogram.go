@@ -0,0 +1,218 @@
+package viewer
+
+import (
+	"fmt"
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Histogram layout. Sizes beyond maxHistogramBars are gathered in one
+// "other" bar.
+const (
+	maxHistogramBars   = 12
+	histogramBarHeight = 18
+)
+
+// sizeBucket counts the sprites whose drawn area has the same size. full is
+// set when that is the whole cell, and other for the bar collecting the
+// rarest sizes.
+type sizeBucket struct {
+	width, height int32
+	full          bool
+	other         bool
+	names         []string
+}
+
+// label returns how the bucket is named on its bar and in the status bar.
+func (b sizeBucket) label() string {
+	switch {
+	case b.other:
+		return tr(msgOtherSizes)
+	case b.width == 0:
+		return tr(msgEmptySize)
+	case b.full:
+		return trf(msgFullCellSize, b.width, b.height)
+	}
+	return fmt.Sprintf("%dx%d", b.width, b.height)
+}
+
+// spriteFilter limits the grid to some of the sheet's sprites.
+type spriteFilter struct {
+	label string
+	names map[string]bool
+}
+
+// apply returns the names the filter lets through, in their order.
+func (f *spriteFilter) apply(names []string) []string {
+	kept := make([]string, 0, len(f.names))
+	for _, name := range names {
+		if f.names[name] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// buildHistogram sorts every sprite of the sheet into buckets by the size of
+// its trimmed content, most common first.
+func (s *UIState) buildHistogram() ([]sizeBucket, error) {
+	if _, err := s.sheetPixels(); err != nil {
+		return nil, err
+	}
+	type key struct {
+		width, height int32
+		full          bool
+	}
+	index := make(map[key]int)
+	var buckets []sizeBucket
+	for _, name := range sortedSpriteNames(s.sheet.Sprites) {
+		w, h, _ := s.contentSize(name)
+		rect := s.sheet.Sprites[name]
+		k := key{w, h, w == rect.Width && h == rect.Height}
+		i, ok := index[k]
+		if !ok {
+			i = len(buckets)
+			index[k] = i
+			buckets = append(buckets, sizeBucket{width: w, height: h, full: k.full})
+		}
+		buckets[i].names = append(buckets[i].names, name)
+	}
+	sort.SliceStable(buckets, func(i, j int) bool { return len(buckets[i].names) > len(buckets[j].names) })
+
+	if len(buckets) > maxHistogramBars {
+		other := sizeBucket{other: true}
+		for _, b := range buckets[maxHistogramBars-1:] {
+			other.names = append(other.names, b.names...)
+		}
+		buckets = append(buckets[:maxHistogramBars-1], other)
+	}
+	return buckets, nil
+}
+
+// toggleHistogram shows the content size histogram, or hides it if it is
+// open.
+func (s *UIState) toggleHistogram() {
+	if s.histogram != nil {
+		s.histogram = nil
+		return
+	}
+	if s.sheet == nil {
+		s.notify(msgOpenSheetForInfo)
+		return
+	}
+	s.refreshHistogram(true)
+}
+
+// refreshHistogram rebuilds the histogram after the sheet was resliced. It
+// does nothing unless the histogram is open or force is set.
+func (s *UIState) refreshHistogram(force bool) {
+	if s.histogram == nil && !force {
+		return
+	}
+	buckets, err := s.buildHistogram()
+	if err != nil {
+		s.notify(msgInspectFailed, err)
+		s.histogram = nil
+		return
+	}
+	s.histogram = buckets
+}
+
+// setFilter shows only the sprites of the bucket in the grid, or every sprite
+// again when the bucket is the current filter.
+func (s *UIState) setFilter(b sizeBucket) {
+	if s.filter != nil && s.filter.label == b.label() {
+		s.clearFilter()
+		return
+	}
+	names := make(map[string]bool, len(b.names))
+	for _, name := range b.names {
+		names[name] = true
+	}
+	s.filter = &spriteFilter{label: b.label(), names: names}
+	s.updateSpriteNames()
+	s.anim.clampRange(int32(len(s.spriteNames)))
+	s.scrollOffset, s.scrollOffsetX = 0, 0
+}
+
+// clearFilter shows every sprite in the grid again.
+func (s *UIState) clearFilter() {
+	if s.filter == nil {
+		return
+	}
+	s.filter = nil
+	if s.sheet != nil {
+		s.updateSpriteNames()
+		s.anim.clampRange(int32(len(s.spriteNames)))
+	}
+}
+
+// filterStatus returns the status bar text for an active filter.
+func (s *UIState) filterStatus() string {
+	if s.filter == nil {
+		return ""
+	}
+	return trf(msgFiltered, len(s.spriteNames), s.filter.label)
+}
+
+// renderHistogram draws the content size histogram: a bar per size, scaled
+// to the most common one. Clicking a bar filters the grid to its sprites.
+func (s *UIState) renderHistogram(cfg Config) rl.Rectangle {
+	buckets := s.histogram
+	width := int32(420)
+	panel := rl.Rectangle{
+		X:      float32(cfg.width-width) / 2,
+		Y:      float32(cfg.headerHeight + 30),
+		Width:  float32(width),
+		Height: float32(45 + len(buckets)*histogramBarHeight + 60),
+	}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(tr(msgContentSizes), int32(panel.X)+10, int32(panel.Y)+10, 15, s.theme.Text)
+
+	labelWidth := float32(0)
+	for _, b := range buckets {
+		labelWidth = max(labelWidth, float32(measureText(b.label(), 10)))
+	}
+	most := 1
+	if len(buckets) > 0 {
+		most = len(buckets[0].names)
+	}
+	barX := panel.X + 20 + labelWidth
+	barSpace := panel.X + panel.Width - 50 - barX
+	mouse := mousePosition()
+	for i, b := range buckets {
+		y := panel.Y + 40 + float32(i)*histogramBarHeight
+		row := rl.Rectangle{X: panel.X + 5, Y: y - 2, Width: panel.Width - 10, Height: histogramBarHeight}
+		over := rl.CheckCollisionPointRec(mouse, row)
+		if over {
+			rl.DrawRectangleRec(row, rl.ColorAlpha(s.theme.CellBorder, 0.3))
+		}
+		col := s.theme.CellBorder
+		if s.filter != nil && s.filter.label == b.label() {
+			col = s.selectionColor()
+		}
+		drawText(b.label(), int32(panel.X)+10, int32(y)+1, 10, s.theme.Text)
+		bar := rl.Rectangle{X: barX, Y: y, Width: max(barSpace*float32(len(b.names))/float32(most), 1), Height: histogramBarHeight - 6}
+		rl.DrawRectangleRec(bar, col)
+		drawText(fmt.Sprint(len(b.names)), int32(bar.X+bar.Width)+5, int32(y)+1, 10, s.theme.MutedText)
+		if over && rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+			s.setFilter(b)
+		}
+	}
+
+	buttonY := panel.Y + panel.Height - 35
+	closeWidth := buttonWidth(tr(msgClose), 90)
+	closeX := panel.X + panel.Width - 10 - closeWidth
+	if s.filter != nil {
+		allWidth := buttonWidth(tr(msgShowAll), 90)
+		if drawButton(rl.Rectangle{X: closeX - 10 - allWidth, Y: buttonY, Width: allWidth, Height: 25}, tr(msgShowAll)) {
+			s.clearFilter()
+		}
+	}
+	if drawButton(rl.Rectangle{X: closeX, Y: buttonY, Width: closeWidth, Height: 25}, tr(msgClose)) {
+		s.histogram = nil
+	}
+	return panel
+}
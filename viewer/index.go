@@ -0,0 +1,86 @@
+package viewer
+
+import (
+	"strconv"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// indexBase chooses what a sprite's index counts. Either way it stays the
+// same whatever filter, custom order or grouping the grid shows, so indices
+// pasted into code keep pointing at the same sprites.
+type indexBase int
+
+const (
+	// indexByName counts sprites in natural name order, before any filter
+	// or reordering.
+	indexByName indexBase = iota
+	// indexBySheet counts the cells of the sheet row by row, as exports
+	// named by index do.
+	indexBySheet
+)
+
+// indexBases lists the index bases in the order the settings panel cycles
+// through them, with their labels.
+var indexBases = []struct {
+	base  indexBase
+	label msgID
+}{
+	{indexByName, msgIndexByName},
+	{indexBySheet, msgIndexBySheet},
+}
+
+// spriteIndices returns the index of every sprite, given their names in
+// natural order.
+func (s *UIState) spriteIndices(natural []string, sprites map[string]resources.Rectangle) map[string]int {
+	indices := make(map[string]int, len(natural))
+	for i, name := range natural {
+		if s.indexBase == indexBySheet {
+			col, row := s.slicing.position(sprites[name])
+			i = int(row*s.slicing.cols + col)
+		}
+		indices[name] = i
+	}
+	return indices
+}
+
+// cycleIndexBase switches to the next index base.
+func (s *UIState) cycleIndexBase() {
+	for i, b := range indexBases {
+		if b.base == s.indexBase {
+			s.indexBase = indexBases[(i+1)%len(indexBases)].base
+			break
+		}
+	}
+	if s.sheet != nil {
+		s.updateSpriteNames()
+	}
+}
+
+// indexBaseLabel returns the label of the selected index base.
+func (s *UIState) indexBaseLabel() string {
+	for _, b := range indexBases {
+		if b.base == s.indexBase {
+			return tr(b.label)
+		}
+	}
+	return ""
+}
+
+// copySpriteIndices copies the indices of the selected sprites to the
+// clipboard, comma separated in display order.
+func (s *UIState) copySpriteIndices() {
+	names := s.selectedNames()
+	if s.sheet == nil || len(names) == 0 {
+		s.notify(msgSelectSpriteToCopy)
+		return
+	}
+	indices := make([]string, len(names))
+	for i, name := range names {
+		indices[i] = strconv.Itoa(s.indices[name])
+	}
+	rl.SetClipboardText(strings.Join(indices, ", "))
+	s.notify(msgCopiedIndices, len(names), s.indexBaseLabel())
+}
@@ -0,0 +1,226 @@
+package viewer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// Inspector zoom limits, and the factor one wheel notch zooms by.
+const (
+	minInspectorZoom  = 0.25
+	maxInspectorZoom  = 64
+	inspectorZoomStep = 1.25
+	inspectorWidth    = 280
+)
+
+// inspectorPresets are the fixed zoom factors offered next to Fit.
+var inspectorPresets = []float32{1, 2, 4, 8}
+
+// inspector is the state of the sprite inspector, a side panel showing the
+// selected sprite on its own at a zoom independent of the thumbnail grid.
+// With fit set the zoom follows the panel size; pan offsets the sprite from
+// the center of the panel when it is larger than the panel.
+type inspector struct {
+	visible  bool
+	fit      bool
+	zoom     float32
+	pan      rl.Vector2
+	dragging bool
+	last     rl.Vector2
+}
+
+// toggleInspector shows or hides the inspector. It opens fitted.
+func (s *UIState) toggleInspector() {
+	if s.inspect.visible {
+		s.closeInspector()
+		return
+	}
+	s.inspect = inspector{visible: true, fit: true, zoom: 1}
+}
+
+// inspectSprite selects name alone and opens the inspector fitted on it, as
+// double-clicking its thumbnail does.
+func (s *UIState) inspectSprite(name string) {
+	s.selected = map[string]bool{name: true}
+	s.inspect = inspector{visible: true, fit: true, zoom: 1}
+}
+
+// closeInspector hides the inspector. The sprite it showed stays selected
+// and the grid scrolls to it, so browsing carries on from there.
+func (s *UIState) closeInspector() {
+	s.inspect.visible = false
+	if names := s.selectedNames(); len(names) > 0 {
+		s.reveal = names[0]
+	}
+}
+
+// inspectorRect returns the inspector panel, along the left of the viewport.
+func (s *UIState) inspectorRect(cfg Config) rl.Rectangle {
+	return rl.Rectangle{X: 10, Y: float32(cfg.startY), Width: inspectorWidth, Height: float32(cfg.viewportHeight - 10)}
+}
+
+// inspectorArea returns the part of the panel the sprite is drawn in.
+func inspectorArea(panel rl.Rectangle) rl.Rectangle {
+	return rl.Rectangle{X: panel.X + 10, Y: panel.Y + 65, Width: panel.Width - 20, Height: panel.Height - 125}
+}
+
+// overInspector reports whether the mouse is over the open inspector, so
+// the grid leaves the wheel to it.
+func (s *UIState) overInspector(cfg Config) bool {
+	return s.inspect.visible && rl.CheckCollisionPointRec(mousePosition(), s.inspectorRect(cfg))
+}
+
+// zoomInspector zooms by the given number of wheel notches, leaving fit
+// mode.
+func (s *UIState) zoomInspector(notches float32) {
+	zoom := s.inspect.zoom * float32(math.Pow(inspectorZoomStep, float64(notches)))
+	s.inspect.zoom = max(minInspectorZoom, min(maxInspectorZoom, zoom))
+	s.inspect.fit = false
+}
+
+// renderInspector draws the inspector panel: Fit and preset zoom buttons,
+// the first selected sprite scaled with nearest-neighbor sampling, and the
+// current zoom factor. The sprite can be dragged around when it is larger
+// than the panel.
+func (s *UIState) renderInspector(cfg Config) rl.Rectangle {
+	panel := s.inspectorRect(cfg)
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(tr(msgInspector), int32(panel.X)+10, int32(panel.Y)+8, 15, s.theme.Text)
+	base := tr(msgDecimal)
+	if s.hexCoords {
+		base = tr(msgHex)
+	}
+	baseWidth := buttonWidth(base, 40)
+	if drawButton(rl.Rectangle{X: panel.X + panel.Width - 10 - baseWidth, Y: panel.Y + 6, Width: baseWidth, Height: 20}, base) {
+		s.hexCoords = !s.hexCoords
+	}
+
+	x := panel.X + 10
+	fitWidth := buttonWidth(tr(msgFit), 40)
+	if drawButton(rl.Rectangle{X: x, Y: panel.Y + 32, Width: fitWidth, Height: 22}, tr(msgFit)) {
+		s.inspect.fit, s.inspect.pan = true, rl.Vector2{}
+	}
+	x += fitWidth + 6
+	for _, zoom := range inspectorPresets {
+		label := formatZoom(zoom)
+		if drawButton(rl.Rectangle{X: x, Y: panel.Y + 32, Width: 36, Height: 22}, label) {
+			s.inspect.zoom, s.inspect.fit, s.inspect.pan = zoom, false, rl.Vector2{}
+		}
+		x += 42
+	}
+
+	area := inspectorArea(panel)
+	rl.DrawRectangleLinesEx(area, 1, s.theme.CellBorder)
+	names := s.selectedNames()
+	if s.sheet == nil || len(names) == 0 {
+		text := tr(msgSelectSpriteToInspect)
+		drawText(text, int32(area.X+area.Width/2)-measureText(text, 10)/2, int32(area.Y+area.Height/2)-5, 10, s.theme.MutedText)
+		return panel
+	}
+	name := names[0]
+	src := spriteSource(s.sheet.Sprites[name])
+	if s.inspect.fit {
+		s.inspect.zoom = min(area.Width/src.Width, area.Height/src.Height)
+	}
+	zoom := s.inspect.zoom
+
+	s.panInspector(area, src, zoom)
+	dest := rl.Rectangle{
+		X:      area.X + (area.Width-src.Width*zoom)/2 + s.inspect.pan.X,
+		Y:      area.Y + (area.Height-src.Height*zoom)/2 + s.inspect.pan.Y,
+		Width:  src.Width * zoom,
+		Height: src.Height * zoom,
+	}
+
+	// The viewer's scissor area is already in use, so the sprite is cropped
+	// to the panel by drawing only the matching part of its source.
+	visible := rl.GetCollisionRec(dest, area)
+	if visible.Width > 0 && visible.Height > 0 {
+		crop := rl.Rectangle{
+			X:      src.X + (visible.X-dest.X)/zoom,
+			Y:      src.Y + (visible.Y-dest.Y)/zoom,
+			Width:  visible.Width / zoom,
+			Height: visible.Height / zoom,
+		}
+		rl.DrawTexturePro(s.drawTexture(), s.texSource(crop), visible, rl.Vector2{}, 0, rl.White)
+		if s.measureGrid {
+			s.drawMeasureGrid(crop, visible)
+		}
+	}
+
+	rect := s.sheet.Sprites[name]
+	rectY := panel.Y + panel.Height - 52
+	drawText(s.formatRect(rect), int32(panel.X)+10, int32(rectY)+5, 10, s.theme.Text)
+	copyWidth := buttonWidth(tr(msgCopy), 50)
+	if drawButton(rl.Rectangle{X: panel.X + panel.Width - 10 - copyWidth, Y: rectY, Width: copyWidth, Height: 20}, tr(msgCopy)) {
+		s.copySpriteRects()
+	}
+
+	footer := trf(msgInspectorZoom, s.displayName(name), formatZoom(zoom))
+	drawText(footer, int32(panel.X)+10, int32(panel.Y+panel.Height)-22, 10, s.theme.MutedText)
+	return panel
+}
+
+// panInspector moves the sprite with a left-button drag inside the area
+// and keeps it from being dragged out of view.
+func (s *UIState) panInspector(area, src rl.Rectangle, zoom float32) {
+	mouse := mousePosition()
+	if rl.IsMouseButtonPressed(rl.MouseLeftButton) && rl.CheckCollisionPointRec(mouse, area) {
+		s.inspect.dragging, s.inspect.last = true, mouse
+	}
+	if !rl.IsMouseButtonDown(rl.MouseLeftButton) {
+		s.inspect.dragging = false
+	}
+	if s.inspect.dragging {
+		s.inspect.pan.X += mouse.X - s.inspect.last.X
+		s.inspect.pan.Y += mouse.Y - s.inspect.last.Y
+		s.inspect.last = mouse
+	}
+
+	maxX := max(src.Width*zoom-area.Width, 0) / 2
+	maxY := max(src.Height*zoom-area.Height, 0) / 2
+	s.inspect.pan.X = max(-maxX, min(maxX, s.inspect.pan.X))
+	s.inspect.pan.Y = max(-maxY, min(maxY, s.inspect.pan.Y))
+}
+
+// coord formats a sprite coordinate or size in the chosen base.
+func (s *UIState) coord(v int32) string {
+	if s.hexCoords {
+		return fmt.Sprintf("0x%X", v)
+	}
+	return strconv.Itoa(int(v))
+}
+
+// formatRect formats a sprite's source rectangle as its X, Y, W and H in
+// the chosen base.
+func (s *UIState) formatRect(rect resources.Rectangle) string {
+	return trf(msgRectFields, s.coord(rect.X), s.coord(rect.Y), s.coord(rect.Width), s.coord(rect.Height))
+}
+
+// copySpriteRects copies the source rectangles of the selected sprites to
+// the clipboard, one "name: x, y, w, h" line each, in the chosen base.
+func (s *UIState) copySpriteRects() {
+	names := s.selectedNames()
+	if s.sheet == nil || len(names) == 0 {
+		s.notify(msgSelectSpriteToCopy)
+		return
+	}
+	var b strings.Builder
+	for _, name := range names {
+		rect := s.sheet.Sprites[name]
+		fmt.Fprintf(&b, "%s: %s, %s, %s, %s\n", name, s.coord(rect.X), s.coord(rect.Y), s.coord(rect.Width), s.coord(rect.Height))
+	}
+	rl.SetClipboardText(b.String())
+	s.notify(msgCopiedRects, len(names))
+}
+
+// formatZoom formats a zoom factor such as "4x" or "2.5x".
+func formatZoom(zoom float32) string {
+	return fmt.Sprintf("%.3gx", zoom)
+}
@@ -0,0 +1,91 @@
+package viewer
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// Names of the layers drawn over the grid, bottom to top.
+const (
+	layerDiff         = "diff"
+	layerRecolor      = "recolor"
+	layerKey          = "key"
+	layerError        = "error"
+	layerAnimation    = "animation"
+	layerPins         = "pins"
+	layerInspector    = "inspector"
+	layerSettings     = "settings"
+	layerAbout        = "about"
+	layerReport       = "report"
+	layerUsages       = "usages"
+	layerHistogram    = "histogram"
+	layerPalette      = "palette"
+	layerExportPrompt = "export-prompt"
+	layerSubSheet     = "sub-sheet"
+	layerRename       = "rename"
+	layerCloseConfirm = "close-confirm"
+	layerRecovery     = "recovery"
+)
+
+// layer is a panel or bar drawn over the grid in the last frame. A modal
+// layer takes the pointer wherever it is; close, if set, is what Escape does
+// to it.
+type layer struct {
+	name   string
+	bounds rl.Rectangle
+	modal  bool
+	close  func(*UIState)
+}
+
+// layerStack routes the pointer and Escape to the topmost layer, so a panel
+// takes the clicks and wheel over it and the grid and the panels under it
+// don't see them. Layers are collected while drawing and used for the input
+// of the next frame, which is when the panels were last seen on screen.
+type layerStack struct {
+	prev, cur []layer
+	// pointer is the layer the mouse belongs to this frame, or "" for the
+	// grid and header.
+	pointer string
+}
+
+// beginFrame works out which layer the mouse belongs to. A drag stays with
+// the layer it started on, so dragging a slider off a panel doesn't start
+// selecting sprites under it.
+func (l *layerStack) beginFrame() {
+	l.prev, l.cur = l.cur, l.prev[:0]
+	if rl.IsMouseButtonDown(rl.MouseLeftButton) && !rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+		return
+	}
+	l.pointer = ""
+	mouse := mousePosition()
+	for i := len(l.prev) - 1; i >= 0; i-- {
+		if l.prev[i].modal || rl.CheckCollisionPointRec(mouse, l.prev[i].bounds) {
+			l.pointer = l.prev[i].name
+			return
+		}
+	}
+}
+
+// escapeLayer closes the topmost layer that can be closed, if any.
+func (s *UIState) escapeLayer() {
+	for i := len(s.layers.prev) - 1; i >= 0; i-- {
+		if l := s.layers.prev[i]; l.close != nil {
+			l.close(s)
+			return
+		}
+	}
+}
+
+// drawLayer draws a layer with render, which returns the bounds it took.
+// Widgets in it see the mouse only while the mouse belongs to it.
+func (s *UIState) drawLayer(cfg Config, name string, modal bool, close func(*UIState), render func(Config) rl.Rectangle) {
+	mouseDisabled = s.layers.pointer != name
+	bounds := render(cfg)
+	mouseDisabled = false
+	if bounds.Width > 0 && bounds.Height > 0 {
+		s.layers.cur = append(s.layers.cur, layer{name: name, bounds: bounds, modal: modal, close: close})
+	}
+}
+
+// overLayer reports whether the mouse belongs to a layer rather than the
+// grid and header.
+func (s *UIState) overLayer() bool {
+	return s.layers.pointer != ""
+}
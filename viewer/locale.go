@@ -0,0 +1,1362 @@
+package viewer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// msgID identifies a user-facing string in the catalogs.
+type msgID int
+
+const (
+	msgTitle msgID = iota
+	msgInfo
+	msgExport
+	msgSettings
+	msgOpenFile
+	msgCancel
+	msgClose
+	msgSave
+	msgDiscard
+	msgRestore
+	msgRecoveryTitle
+	msgRecoveryFound
+	msgRestoredSheets
+	msgRecoveryKept
+	msgRecoveryWriteFailed
+	msgCopy
+	msgCtrl
+	msgShift
+	msgSpace
+
+	msgNoSheetLoaded
+	msgReloadFailed
+	msgReloadFailedBanner
+	msgLoadErrorResolved
+	msgErrorTruncated
+	msgCopyError
+	msgCopiedError
+	msgAbout
+	msgCopyDiagnostics
+	msgDiagnosticsCopied
+	msgLoadedSprites
+	msgLoadedAseprite
+	msgAsepriteFailed
+	msgAsepriteNoFrames
+	msgNoSprites
+	msgResourceManagerFailed
+	msgInvalidTexture
+	msgCouldNotRead
+	msgCouldNotWrite
+	msgSelectSpriteToCopy
+	msgCopyImageFailed
+	msgCopiedImage
+	msgDraggedOut
+	msgDragOutFailed
+	msgSingleRow
+	msgHoverInfo
+	msgHoverChanged
+	msgSelectedCount
+	msgReslicing
+	msgUnsaved
+	msgTooltipRect
+	msgTooltipIndex
+	msgInspector
+	msgFit
+	msgSelectSpriteToInspect
+	msgSelectSpriteToPin
+	msgInspectorZoom
+	msgDecimal
+	msgHex
+	msgRectFields
+	msgCopiedRects
+	msgCopiedIndices
+	msgMirrorOf
+	msgMirrorBadge
+
+	msgMargin
+	msgGridSize
+	msgGridCapped
+	msgOutlinePx
+	msgOutlineColor
+	msgExportScale
+	msgExportNames
+	msgAlphaThreshold
+	msgLockSlicing
+	msgContactLabels
+	msgContactGrid
+	msgRightToLeft
+	msgPresetLabel
+	msgPresetActiveLabel
+	msgStorePreset
+	msgPresetGrid
+	msgPresetCount
+	msgPresetStored
+	msgPresetActive
+	msgStorePresetsFirst
+	msgSlicingLocked
+	msgSlicingLockedOn
+	msgSlicingUnlocked
+	msgRightToLeftOn
+	msgRightToLeftOff
+	msgPinnedSprites
+	msgUnpinnedSprites
+	msgTooManyPins
+	msgPins
+	msgNameBySprite
+	msgNameByGrid
+	msgNameByIndex
+	msgIndexBase
+	msgIndexByName
+	msgIndexBySheet
+	msgBackground
+	msgGuessBackground
+	msgKeyTolerance
+	msgSilhouettes
+	msgSilhouetteColor
+	msgSilhouetteBackground
+	msgMeasureGrid
+	msgMeasurePitch
+	msgMeasureColor
+	msgExportNamingIs
+	msgAtlasTrim
+	msgAlphaTest
+	msgResetOrder
+	msgZebraRows
+	msgTrueSize
+	msgSnapRows
+	msgGroupPrefix
+	msgLabelOverlay
+	msgMipmaps
+	msgAccessibility
+	msgHighContrast
+	msgColorblindSafe
+	msgFontSpacing
+	msgFontBaseline
+	msgStripSpacing
+	msgStripVertical
+	msgStripTrim
+	msgTextScale
+	msgByCount
+	msgColumns
+	msgRows
+	msgSettingsHelp
+	msgPreview
+	msgNoCellsFit
+	msgGridExceedsWidth
+	msgGridExceedsHeight
+	msgGridMarginExceedsWidth
+	msgGridMarginExceedsHeight
+	msgImageEmpty
+	msgImageTooSmall
+	msgCountDoesntFit
+	msgCountInexact
+
+	msgUndone
+	msgRedone
+	msgNothingToUndo
+	msgNothingToRedo
+	msgSliceByGrid
+	msgSliceByCount
+	msgMoveSprite
+	msgAlreadyInOrder
+
+	msgActOpen
+	msgActEditSheet
+	msgActEditSprite
+	msgActRenameSprites
+	msgActNewTab
+	msgActCloseTab
+	msgActWatchFolder
+	msgActStopWatch
+	msgActSaveFolderDefaults
+	msgActReload
+	msgActSave
+	msgActExportSprites
+	msgActExportAtlas
+	msgActExportFontStrip
+	msgActExportStrip
+	msgActExportContact
+	msgActExportGIFs
+	msgActExportSubSheet
+	msgActRightToLeft
+	msgActPin
+	msgActCompare
+	msgActCloseCompare
+	msgActLoadPalette
+	msgActRecolorBefore
+	msgActCloseRecolor
+	msgActUndo
+	msgActRedo
+	msgActSheetInfo
+	msgActAbout
+	msgActPerfOverlay
+	msgActCellCheck
+	msgActMeasureGrid
+	msgActLockSlicing
+	msgActContentSizes
+	msgActShowAll
+	msgActCheckUsages
+	msgActCheckUsagesClipboard
+	msgActToggleSettings
+	msgActToggleAnimation
+	msgActInspector
+	msgActPlayPause
+	msgActPrevFrame
+	msgActNextFrame
+	msgActLoopIn
+	msgActLoopOut
+	msgActClearLoop
+	msgActStripView
+	msgActSheetView
+	msgActNativeView
+	msgActZebra
+	msgActLabelOverlay
+	msgActTrueSize
+	msgActSnapRows
+	msgActGroupPrefix
+	msgActHighContrast
+	msgActColorblind
+	msgActCopyImage
+	msgActCopyRects
+	msgActCopyIndices
+	msgActGuessBackground
+	msgActSilhouettes
+	msgActHexCoords
+	msgActExportNaming
+	msgActPreviewDownscale
+	msgActMipmaps
+	msgActGammaCorrection
+	msgActResetOrder
+	msgActStorePresetA
+	msgActStorePresetB
+	msgActTogglePreset
+	msgActPalette
+	msgNoSheetToReload
+	msgReloaded
+	msgNothingToSave
+	msgSaved
+	msgEditIn
+	msgDefaultEditor
+	msgNoSheetToEdit
+	msgEditorNotFound
+	msgEditorFailed
+	msgEditing
+	msgEditingSprite
+	msgSelectSpriteToEdit
+	msgReloadedAfterEdit
+	msgLargeTexture
+	msgPreviewDownscale
+	msgPreviewFullSize
+	msgMipmapsOn
+	msgMipmapsOff
+	msgNonstandardGamma
+	msgNoFileGamma
+	msgGammaCorrected
+	msgGammaRaw
+	msgGammaFailed
+	msgTextureMemory
+	msgDownscaled
+	msgTextureMemoryTabs
+	msgNewTab
+	msgTooManyTabs
+	msgWatching
+	msgWatchFailed
+	msgWatchStopped
+	msgWatchPaused
+	msgWatchHeld
+	msgWatchingStatus
+	msgWatchPausedStatus
+	msgSavedFolderDefaults
+	msgSaveFolderFailed
+	msgCompareNeedsSheet
+	msgTypeCommand
+	msgNoMatchingCommands
+
+	msgAnimation
+	msgPlayRate
+	msgFrame
+	msgStart
+	msgEnd
+	msgFPS
+	msgRangeSwapped
+	msgRangeInfo
+	msgRangeInfoTimed
+	msgLoopInfo
+	msgAllFrames
+	msgTag
+	msgDurations
+	msgSetAll
+	msgSetAllDurations
+	msgFrameDuration
+	msgMS
+	msgPlay
+	msgPause
+
+	msgNothingToExport
+	msgExportRunning
+	msgExportReadFailed
+	msgExportWriteFailed
+	msgExportFailed
+	msgExportFailedAfter
+	msgExportCancelled
+	msgExported
+	msgExportedSkipped
+	msgWroteAtlas
+	msgWroteGlyphs
+	msgSelectGlyphs
+	msgSubSheet
+	msgSubSheetNeedsGrid
+	msgRenameTitle
+	msgFindReplace
+	msgRenumber
+	msgFind
+	msgReplaceWith
+	msgGlobHint
+	msgRenamePattern
+	msgStartAt
+	msgRenameSummary
+	msgRenameMore
+	msgNameTaken
+	msgNameEmpty
+	msgNameInvalid
+	msgRename
+	msgRenamedSprites
+	msgNothingToRename
+	msgFirstColumn
+	msgLastColumn
+	msgFirstRow
+	msgLastRow
+	msgSubSheetSize
+	msgWroteSubSheet
+	msgPerfFPS
+	msgPerfThumbnails
+	msgPerfSprites
+	msgPerfUpdate
+	msgPerfSorting
+	msgPerfTextures
+	msgPerfLoad
+	msgPerfSlicing
+	msgGlyphsEmpty
+	msgSelectStripFrames
+	msgStripEmpty
+	msgNoAnimations
+	msgExportedGIFs
+	msgGIFsCancelled
+	msgGIFsFailed
+	msgWroteStrip
+	msgWroteContactSheet
+	msgContactTooLarge
+	msgOrderNatural
+	msgOrderRightToLeft
+	msgOrderCustom
+	msgOrderAseprite
+	msgOrderGrouped
+	msgFilesExist
+	msgAndMore
+	msgOverwriteAll
+	msgSkipExisting
+	msgRenameNew
+
+	msgCompareFailed
+	msgRecolorNeedsSheet
+	msgRecolorFailed
+	msgKeyNeedsSheet
+	msgKeyFailed
+	msgKeyedBackground
+	msgKeySummary
+	msgPickingKey
+	msgPickKey
+	msgRecolored
+	msgNoRecolor
+	msgBadColor
+	msgBadPaletteLine
+	msgEmptyPalette
+	msgRecolorSummary
+	msgRecolorAfter
+	msgRecolorBefore
+	msgShowOriginal
+	msgShowRecolored
+	msgDiffSummary
+	msgDiffSizes
+	msgDiffVersus
+	msgExportList
+	msgWroteChangeList
+	msgUsagesIn
+	msgUsagesFound
+	msgUsagesMissing
+	msgUsagesUnused
+	msgUsagesMore
+	msgSelectFound
+	msgWroteUsages
+	msgOpenSheetForUsages
+	msgUsagesReadFailed
+	msgClipboard
+	msgSaveChanges
+	msgSaveMetaFailed
+
+	msgSheetInfo
+	msgContentSizes
+	msgOtherSizes
+	msgEmptySize
+	msgFullCellSize
+	msgShowAll
+	msgFiltered
+	msgFilteredNoArrange
+	msgOpenSheetForInfo
+	msgInspectFailed
+	msgInfoCopied
+	msgReportSheet
+	msgReportFile
+	msgReportFileError
+	msgReportHeader
+	msgReportFormat
+	msgReportColor
+	msgReportNoColor
+	msgReportGammaWarning
+	msgReportSize
+	msgReportTexture
+	msgReportMipmaps
+	msgReportNoMipmaps
+	msgReportCells
+	msgReportLayout
+	msgReportCellCheck
+	msgCellCheckOK
+	msgCellCheckCounts
+	msgMissingColumn
+	msgMissingRow
+	msgMissingCells
+	msgUnexpectedCells
+	msgCellCheckGridOnly
+	msgLegendExpected
+	msgLegendPresent
+	msgReportSprites
+	msgReportEmpty
+	msgReportDuplicates
+	msgReportMirrors
+	msgReportMirrorPair
+	msgReportMoreMirrors
+)
+
+// catalog maps message IDs to the strings of one language. Strings with
+// arguments are fmt formats.
+type catalog map[msgID]string
+
+// english is the reference catalog. Every message has an English string, and
+// other catalogs fall back to it for anything they don't translate.
+var english = catalog{
+	msgTitle:               "Sprite Sheet Viewer",
+	msgInfo:                "Info",
+	msgExport:              "Export",
+	msgSettings:            "Settings",
+	msgOpenFile:            "Open File",
+	msgCancel:              "Cancel",
+	msgClose:               "Close",
+	msgSave:                "Save",
+	msgDiscard:             "Discard",
+	msgRestore:             "Restore",
+	msgRecoveryTitle:       "Restore unsaved changes?",
+	msgRecoveryFound:       "A session that didn't close cleanly left unsaved changes to %d sheets.",
+	msgRestoredSheets:      "Restored unsaved changes to %d of %d sheets",
+	msgRecoveryKept:        "Unsaved changes kept; you will be asked again next time",
+	msgRecoveryWriteFailed: "Could not write the recovery file: %v",
+	msgCopy:                "Copy",
+	msgCtrl:                "Ctrl",
+	msgShift:               "Shift",
+	msgSpace:               "Space",
+
+	msgNoSheetLoaded:         "No spritesheet loaded. Press '%s' to select one.",
+	msgReloadFailed:          "Reload failed: %v",
+	msgReloadFailedBanner:    "Reload failed: %s (showing last good sheet)",
+	msgLoadErrorResolved:     "Last reload failed: %s (loaded fine since)",
+	msgErrorTruncated:        "... \"Copy error\" copies all of it",
+	msgCopyError:             "Copy error",
+	msgCopiedError:           "Copied the error with its context",
+	msgAbout:                 "About Sprite Sheet Viewer",
+	msgCopyDiagnostics:       "Copy diagnostics",
+	msgDiagnosticsCopied:     "Copied the diagnostics",
+	msgLoadedSprites:         "Loaded %d sprites",
+	msgLoadedAseprite:        "Loaded %d frames and %d tags from %s",
+	msgAsepriteFailed:        "Ignoring %s: %v",
+	msgAsepriteNoFrames:      "no frames fit the sheet",
+	msgNoSprites:             "No sprites found in sheet",
+	msgResourceManagerFailed: "Failed to create resource manager",
+	msgInvalidTexture:        "Invalid texture",
+	msgCouldNotRead:          "could not read %s",
+	msgCouldNotWrite:         "could not write %s",
+	msgSelectSpriteToCopy:    "Select a sprite to copy",
+	msgCopyImageFailed:       "Copy failed: %v",
+	msgCopiedImage:           "Copied %s to the clipboard",
+	msgDraggedOut:            "%s saved as a PNG, paste it where you dropped it",
+	msgDragOutFailed:         "Could not hand over the sprite: %v",
+	msgSingleRow:             "Single-row sheet detected: press V for strip view",
+	msgHoverInfo:             "#%d, cell %d (col %d, row %d) src %s,%s %sx%s",
+	msgHoverChanged:          " changed",
+	msgSelectedCount:         "%d selected",
+	msgReslicing:             "Reslicing...",
+	msgUnsaved:               " (unsaved, %s)",
+	msgTooltipRect:           "%sx%s at %s,%s",
+	msgTooltipIndex:          "Index %d",
+	msgInspector:             "Inspector",
+	msgFit:                   "Fit",
+	msgSelectSpriteToInspect: "Select a sprite to inspect",
+	msgSelectSpriteToPin:     "Select a sprite to pin",
+	msgInspectorZoom:         "%s at %s",
+	msgDecimal:               "Dec",
+	msgHex:                   "Hex",
+	msgRectFields:            "x %s  y %s  w %s  h %s",
+	msgCopiedRects:           "Copied the rectangles of %d sprites",
+	msgCopiedIndices:         "Copied the indices of %d sprites (%s)",
+	msgMirrorOf:              "mirror of %s",
+	msgMirrorBadge:           "M%d",
+
+	msgMargin:                  "Margin",
+	msgGridSize:                "Grid Size",
+	msgGridCapped:              "Max %d: sheet is %dx%d",
+	msgOutlinePx:               "Outline px",
+	msgOutlineColor:            "Outline color",
+	msgExportScale:             "Export scale",
+	msgExportNames:             "Export names",
+	msgAlphaThreshold:          "Empty up to alpha",
+	msgLockSlicing:             "Lock slicing",
+	msgContactLabels:           "Contact labels",
+	msgContactGrid:             "Contact grid",
+	msgRightToLeft:             "Right to left",
+	msgPresetLabel:             "Preset %s",
+	msgPresetActiveLabel:       "Preset %s (active)",
+	msgStorePreset:             "Store as %s",
+	msgPresetGrid:              "grid %d, margin %d",
+	msgPresetCount:             "%dx%d cells, margin %d",
+	msgPresetStored:            "Stored preset %s: %s",
+	msgPresetActive:            "Preset %s: %s",
+	msgStorePresetsFirst:       "Store presets A and B in the settings first",
+	msgSlicingLocked:           "Slicing is locked; unlock it in the settings first",
+	msgSlicingLockedOn:         "Slicing locked",
+	msgSlicingUnlocked:         "Slicing unlocked",
+	msgRightToLeftOn:           "Sprites read right to left",
+	msgRightToLeftOff:          "Sprites read left to right",
+	msgPinnedSprites:           "Pinned %d sprites",
+	msgUnpinnedSprites:         "Unpinned %d sprites",
+	msgTooManyPins:             "Up to %d sprites can be pinned",
+	msgPins:                    "Pinned",
+	msgNameBySprite:            "Sprite name",
+	msgNameByGrid:              "Row and column",
+	msgNameByIndex:             "Index",
+	msgIndexBase:               "Sprite index",
+	msgIndexByName:             "Name order",
+	msgIndexBySheet:            "Sheet cells",
+	msgBackground:              "Background",
+	msgGuessBackground:         "Guess",
+	msgKeyTolerance:            "Key tolerance",
+	msgSilhouettes:             "Silhouettes",
+	msgSilhouetteColor:         "Silhouette color",
+	msgSilhouetteBackground:    "Silhouette backdrop",
+	msgMeasureGrid:             "Measure grid",
+	msgMeasurePitch:            "Measure pitch",
+	msgMeasureColor:            "Measure color",
+	msgExportNamingIs:          "Exported files are named by: %s",
+	msgAtlasTrim:               "Atlas trim",
+	msgAlphaTest:               "Alpha test",
+	msgResetOrder:              "Reset order",
+	msgZebraRows:               "Zebra rows",
+	msgTrueSize:                "True size",
+	msgSnapRows:                "Snap to rows",
+	msgGroupPrefix:             "Group by prefix",
+	msgLabelOverlay:            "Labels on cells",
+	msgMipmaps:                 "Smooth thumbnails",
+	msgAccessibility:           "Accessibility",
+	msgHighContrast:            "High contrast",
+	msgColorblindSafe:          "Colorblind-safe",
+	msgFontSpacing:             "Font spacing",
+	msgFontBaseline:            "Font baseline",
+	msgStripSpacing:            "Strip spacing",
+	msgStripVertical:           "Vertical strip",
+	msgStripTrim:               "Strip trim",
+	msgTextScale:               "Text scale %",
+	msgByCount:                 "By count",
+	msgColumns:                 "Columns",
+	msgRows:                    "Rows",
+	msgSettingsHelp:            "Scroll, drag, or click and Up/Down to adjust, double-click to reset",
+	msgPreview:                 "Preview",
+	msgNoCellsFit:              "No cells fit with these settings",
+	msgGridExceedsWidth:        "grid %d exceeds sheet width %d",
+	msgGridExceedsHeight:       "grid %d exceeds sheet height %d",
+	msgGridMarginExceedsWidth:  "grid %d with margin %d exceeds sheet width %d",
+	msgGridMarginExceedsHeight: "grid %d with margin %d exceeds sheet height %d",
+	msgImageEmpty:              "image is %dx%d and has no pixels",
+	msgImageTooSmall:           "image %dx%d too small for grid size %d",
+	msgCountDoesntFit:          "%d columns x %d rows don't fit a %dx%d sheet",
+	msgCountInexact:            "%dx%d px doesn't divide into %d x %d whole cells; using %dx%d px cells",
+
+	msgUndone:         "undo: %s",
+	msgRedone:         "redo: %s",
+	msgNothingToUndo:  "Nothing to undo",
+	msgNothingToRedo:  "Nothing to redo",
+	msgSliceByGrid:    "slice by grid size",
+	msgSliceByCount:   "slice by count",
+	msgMoveSprite:     "move %s",
+	msgAlreadyInOrder: "Sprites are already in sheet order",
+
+	msgActOpen:                 "Open file",
+	msgActEditSheet:            "Edit in external editor",
+	msgActEditSprite:           "Edit this sprite",
+	msgActRenameSprites:        "Rename sprites",
+	msgActNewTab:               "Open in new tab",
+	msgActCloseTab:             "Close tab",
+	msgActWatchFolder:          "Watch folder (or resume watching)",
+	msgActStopWatch:            "Stop watching folder",
+	msgActSaveFolderDefaults:   "Save slicing as folder default",
+	msgActReload:               "Reload sheet",
+	msgActSave:                 "Save settings",
+	msgActExportSprites:        "Export sprites",
+	msgActExportAtlas:          "Export atlas",
+	msgActExportFontStrip:      "Export font strip",
+	msgActExportStrip:          "Export sprite strip",
+	msgActExportContact:        "Export contact sheet",
+	msgActExportGIFs:           "Export animations as GIFs",
+	msgActExportSubSheet:       "Export sub-sheet",
+	msgActRightToLeft:          "Toggle right-to-left order",
+	msgActPin:                  "Pin or unpin the selected sprites",
+	msgActCompare:              "Compare with file",
+	msgActCloseCompare:         "Close comparison",
+	msgActLoadPalette:          "Recolor with palette map",
+	msgActRecolorBefore:        "Toggle recolor before/after",
+	msgActCloseRecolor:         "Close recolor preview",
+	msgActUndo:                 "Undo",
+	msgActRedo:                 "Redo",
+	msgActSheetInfo:            "Sheet info",
+	msgActAbout:                "About",
+	msgActPerfOverlay:          "Performance overlay",
+	msgActCellCheck:            "Check cells against expected grid",
+	msgActMeasureGrid:          "Toggle measurement grid",
+	msgActLockSlicing:          "Lock or unlock slicing",
+	msgActContentSizes:         "Content size histogram",
+	msgActShowAll:              "Show all sprites",
+	msgActCheckUsages:          "Check sprite names used in a file",
+	msgActCheckUsagesClipboard: "Check sprite names in clipboard",
+	msgActToggleSettings:       "Toggle settings",
+	msgActToggleAnimation:      "Toggle animation preview",
+	msgActInspector:            "Toggle sprite inspector",
+	msgActPlayPause:            "Play/pause animation",
+	msgActPrevFrame:            "Previous animation frame",
+	msgActNextFrame:            "Next animation frame",
+	msgActLoopIn:               "Set loop start at current frame",
+	msgActLoopOut:              "Set loop end at current frame",
+	msgActClearLoop:            "Clear animation loop",
+	msgActStripView:            "Toggle strip view",
+	msgActSheetView:            "Toggle whole-sheet view",
+	msgActNativeView:           "Toggle native size view",
+	msgActZebra:                "Toggle zebra rows",
+	msgActLabelOverlay:         "Toggle labels on cells",
+	msgActTrueSize:             "Toggle true size thumbnails",
+	msgActSnapRows:             "Toggle snap to rows",
+	msgActGroupPrefix:          "Toggle grouping by prefix",
+	msgActHighContrast:         "Toggle high contrast",
+	msgActColorblind:           "Toggle colorblind-safe colors",
+	msgActCopyImage:            "Copy sprite image",
+	msgActCopyRects:            "Copy sprite rectangles",
+	msgActCopyIndices:          "Copy sprite indices",
+	msgActGuessBackground:      "Guess and key out the background",
+	msgActSilhouettes:          "Toggle silhouettes",
+	msgActHexCoords:            "Toggle hexadecimal coordinates",
+	msgActExportNaming:         "Cycle export file naming",
+	msgActPreviewDownscale:     "Cycle preview downscale (1x/2x/4x)",
+	msgActMipmaps:              "Toggle smooth thumbnails (mipmaps)",
+	msgActGammaCorrection:      "Correct for file gamma",
+	msgActResetOrder:           "Reset sprite order",
+	msgActStorePresetA:         "Store settings as preset A",
+	msgActStorePresetB:         "Store settings as preset B",
+	msgActTogglePreset:         "Toggle preset A/B",
+	msgActPalette:              "Command palette",
+	msgNoSheetToReload:         "No sheet to reload",
+	msgReloaded:                "Reloaded %s",
+	msgNothingToSave:           "Nothing to save",
+	msgSaved:                   "Saved %s",
+	msgEditIn:                  "Edit in %s",
+	msgDefaultEditor:           "editor",
+	msgNoSheetToEdit:           "No sheet to edit",
+	msgEditorNotFound:          "Editor not found: %s",
+	msgEditorFailed:            "Could not start the editor: %v",
+	msgEditing:                 "Editing %s in %s; saving it there reloads it here",
+	msgEditingSprite:           "%s is at %d, %d (%dx%d), copied; opened the sheet in %s",
+	msgSelectSpriteToEdit:      "Point at or select the sprite to edit first",
+	msgReloadedAfterEdit:       "Reloaded %s after it was saved",
+	msgLargeTexture:            "%s takes %s of texture memory, over the %d MB limit; \"Cycle preview downscale\" shows it smaller",
+	msgPreviewDownscale:        "Preview downscaled %dx",
+	msgPreviewFullSize:         "Preview at full size",
+	msgMipmapsOn:               "Built mipmaps in %d ms",
+	msgMipmapsOff:              "Mipmaps off",
+	msgNonstandardGamma:        "%s has gamma %s set and may look different in the art tool; \"Correct for file gamma\" shows it as it would",
+	msgNoFileGamma:             "%s sets no gamma to correct for",
+	msgGammaCorrected:          "Showing the sheet corrected for gamma %s",
+	msgGammaRaw:                "Showing the sheet without gamma correction",
+	msgGammaFailed:             "Gamma correction failed: %v",
+	msgTextureMemory:           "VRAM %s",
+	msgDownscaled:              " (%dx smaller)",
+	msgTextureMemoryTabs:       ", %s in %d tabs",
+	msgNewTab:                  "New tab",
+	msgTooManyTabs:             "At most %d tabs can be open",
+	msgWatching:                "Watching %s for new sheets",
+	msgWatchFailed:             "Could not watch folder: %v",
+	msgWatchStopped:            "Stopped watching the folder",
+	msgWatchPaused:             "Folder watch paused; resume it from the command palette",
+	msgWatchHeld:               "%s appeared in the watched folder; save your changes to open it",
+	msgWatchingStatus:          "watching %s",
+	msgWatchPausedStatus:       "watch paused: %s",
+	msgSavedFolderDefaults:     "Saved slicing defaults for %s",
+	msgSaveFolderFailed:        "Could not save folder defaults: %v",
+	msgCompareNeedsSheet:       "Open a sheet before comparing",
+	msgTypeCommand:             "Type a command...",
+	msgNoMatchingCommands:      "No matching commands",
+
+	msgAnimation:       "Animation",
+	msgPlayRate:        "Anim %.1f/%.1f fps, render %d fps",
+	msgFrame:           "frame %d (%s)",
+	msgStart:           "Start",
+	msgEnd:             "End",
+	msgFPS:             "FPS",
+	msgRangeSwapped:    "Range start was after its end; swapped to %d-%d",
+	msgRangeInfo:       "%d frames, %.2f s at %d fps",
+	msgRangeInfoTimed:  "%d frames, %.2f s (frame durations)",
+	msgLoopInfo:        "looping frames %d-%d (%s clears)",
+	msgAllFrames:       "All frames",
+	msgTag:             "Tag: %s",
+	msgDurations:       "Durations",
+	msgSetAll:          "Set all",
+	msgSetAllDurations: "Set frame durations to %d ms",
+	msgFrameDuration:   "Duration of %s",
+	msgMS:              "ms",
+	msgPlay:            "Play",
+	msgPause:           "Pause",
+
+	msgNothingToExport:   "Nothing to export",
+	msgExportRunning:     "An export is already running",
+	msgExportReadFailed:  "Export failed: could not read %s",
+	msgExportWriteFailed: "Export failed: could not write %s",
+	msgExportFailed:      "Export failed: %v",
+	msgExportFailedAfter: "Export failed after %d of %d sprites: %v",
+	msgExportCancelled:   "Export cancelled: %d of %d sprites were written to %s",
+	msgExported:          "Exported %d sprites to %s",
+	msgExportedSkipped:   "Exported %d sprites to %s (%d existing skipped)",
+	msgWroteAtlas:        "Wrote atlas for %d sprites to %s",
+	msgWroteGlyphs:       "Wrote %d glyphs to %s",
+	msgSelectGlyphs:      "Select the glyph sprites to export first",
+	msgSubSheet:          "Export a range of cells",
+	msgSubSheetNeedsGrid: "Sub-sheets need a sheet sliced into a grid",
+	msgRenameTitle:       "Rename %d sprites",
+	msgFindReplace:       "Find and replace",
+	msgRenumber:          "Number",
+	msgFind:              "Find",
+	msgReplaceWith:       "Replace with",
+	msgGlobHint:          "* matches any text and ? one character; * in the replacement puts it back",
+	msgRenamePattern:     "Pattern, {n} is the number",
+	msgStartAt:           "Start at",
+	msgRenameSummary:     "%d renamed, %d conflicts",
+	msgRenameMore:        "+ %d more",
+	msgNameTaken:         "name taken",
+	msgNameEmpty:         "empty name",
+	msgNameInvalid:       "not a file name",
+	msgRename:            "Rename",
+	msgRenamedSprites:    "Renamed %d sprites",
+	msgNothingToRename:   "No sprites to rename",
+	msgFirstColumn:       "First column",
+	msgLastColumn:        "Last column",
+	msgFirstRow:          "First row",
+	msgLastRow:           "Last row",
+	msgSubSheetSize:      "%dx%d cells, %dx%d px",
+	msgWroteSubSheet:     "Wrote %d sprites (%dx%d cells) to %s",
+	msgPerfFPS:           "%d FPS, %.1f ms a frame",
+	msgPerfThumbnails:    "Thumbnails: %d drawn, %d culled",
+	msgPerfSprites:       "Grid: %s",
+	msgPerfUpdate:        "Update: %s",
+	msgPerfSorting:       "Sprite order: %s",
+	msgPerfTextures:      "Uploads: %d, textures %s",
+	msgPerfLoad:          "Last load: %s, texture %s",
+	msgPerfSlicing:       "Slicing: %s, names %s",
+	msgGlyphsEmpty:       "The selected sprites are all empty",
+	msgSelectStripFrames: "Select the frames of the strip first",
+	msgStripEmpty:        "The selected sprites are all empty",
+	msgNoAnimations:      "No animations to export: tag the sheet in Aseprite or name frames with a shared prefix",
+	msgExportedGIFs:      "Exported %d animations to %s",
+	msgGIFsCancelled:     "Export cancelled: %d of %d animations were written to %s",
+	msgGIFsFailed:        "Exported %d of %d animations to %s, %d failed: %s",
+	msgWroteStrip:        "Wrote a strip of %d frames of %dx%d to %s, in %s",
+	msgWroteContactSheet: "Wrote a contact sheet of %d sprites in %d columns and %d rows to %s, in %s",
+	msgContactTooLarge:   "The contact sheet would be %dx%d, over the %d pixel limit; lower the export scale or filter the grid",
+	msgOrderNatural:      "name order",
+	msgOrderRightToLeft:  "right-to-left order",
+	msgOrderCustom:       "custom order",
+	msgOrderAseprite:     "Aseprite frame order",
+	msgOrderGrouped:      "%s, grouped by prefix",
+	msgFilesExist:        "%d of %d files already exist",
+	msgAndMore:           "...and %d more",
+	msgOverwriteAll:      "Overwrite all",
+	msgSkipExisting:      "Skip existing",
+	msgRenameNew:         "Rename new",
+
+	msgCompareFailed:      "Compare failed: %v",
+	msgRecolorNeedsSheet:  "Open a sheet to recolor first",
+	msgRecolorFailed:      "Recolor failed: %v",
+	msgKeyNeedsSheet:      "Open a sheet to key out its background first",
+	msgKeyFailed:          "Keying out the background failed: %v",
+	msgKeyedBackground:    "Keyed out %s: %d pixels",
+	msgKeySummary:         "Background %s keyed out: %d pixels within %d",
+	msgPickingKey:         "Click a pixel of the sheet to key out its color",
+	msgPickKey:            "Pick",
+	msgRecolored:          "Recolored %d pixels with %d color swaps",
+	msgNoRecolor:          "No palette map loaded",
+	msgBadColor:           "\"%s\" is not a color like #RRGGBB or #RRGGBBAA",
+	msgBadPaletteLine:     "line %d: expected an old and a new color: %s",
+	msgEmptyPalette:       "%s has no color swaps",
+	msgRecolorSummary:     "Palette %s: %d pixels recolored by %d swaps, showing %s",
+	msgRecolorAfter:       "after",
+	msgRecolorBefore:      "before",
+	msgShowOriginal:       "Before",
+	msgShowRecolored:      "After",
+	msgDiffSummary:        "%d of %d sprites changed, %d added, %d removed",
+	msgDiffSizes:          "sizes differ: %dx%d vs %dx%d",
+	msgDiffVersus:         "vs %s: %s",
+	msgExportList:         "Export list",
+	msgWroteChangeList:    "Wrote change list to %s",
+	msgUsagesIn:           "Sprite names in %s",
+	msgUsagesFound:        "Found (%d)",
+	msgUsagesMissing:      "Missing from sheet (%d)",
+	msgUsagesUnused:       "Never referenced (%d)",
+	msgUsagesMore:         "... and %d more",
+	msgSelectFound:        "Select found",
+	msgWroteUsages:        "Wrote usage report to %s",
+	msgOpenSheetForUsages: "Open a sheet to check names against first",
+	msgUsagesReadFailed:   "Could not read the file: %v",
+	msgClipboard:          "clipboard",
+	msgSaveChanges:        "Save changes to sheet metadata?",
+	msgSaveMetaFailed:     "saving sheet metadata: %w",
+
+	msgSheetInfo:          "Sheet info",
+	msgContentSizes:       "Content sizes",
+	msgOtherSizes:         "other",
+	msgEmptySize:          "empty",
+	msgFullCellSize:       "%dx%d (full cell)",
+	msgShowAll:            "Show all",
+	msgFiltered:           "%d sprites %s",
+	msgFilteredNoArrange:  "Show all sprites to rearrange them",
+	msgOpenSheetForInfo:   "Open a sheet to see its info",
+	msgInspectFailed:      "Could not inspect pixels: %v",
+	msgInfoCopied:         "Sheet info copied to clipboard",
+	msgReportSheet:        "Sheet: %s",
+	msgReportFile:         "File: %s, modified %s",
+	msgReportFileError:    "File: %v",
+	msgReportHeader:       "Header: %s %dx%d, %s",
+	msgReportFormat:       "Header: %s",
+	msgReportColor:        "Color: %s",
+	msgReportNoColor:      "Color: no gamma or color profile set",
+	msgReportGammaWarning: "(not sRGB; shown uncorrected unless \"Correct for file gamma\" is on)",
+	msgReportSize:         "Size: %dx%d px",
+	msgReportTexture:      "Texture memory: %s",
+	msgReportMipmaps:      "Mipmaps: built in %d ms",
+	msgReportNoMipmaps:    "Mipmaps: off",
+	msgReportCells:        "Cells: %dx%d px, margin %d px",
+	msgReportLayout:       "Layout: %d columns x %d rows",
+	msgReportCellCheck:    "Grid check: %s",
+	msgCellCheckOK:        "all %d expected cells present",
+	msgCellCheckCounts:    "expected %d cells, sheet contains %d",
+	msgMissingColumn:      "missing column %d (rows 0-%d)",
+	msgMissingRow:         "missing row %d (columns 0-%d)",
+	msgMissingCells:       "%d other cells missing",
+	msgUnexpectedCells:    "%d unexpected cells",
+	msgCellCheckGridOnly:  "The grid check only applies to sheets sliced by grid size",
+	msgLegendExpected:     "expected grid",
+	msgLegendPresent:      "cells in sheet",
+	msgReportSprites:      "Sprites: %d",
+	msgReportEmpty:        "Empty cells: %d",
+	msgReportDuplicates:   "Duplicate groups: %d (%d sprites)",
+	msgReportMirrors:      "Mirror pairs: %d",
+	msgReportMirrorPair:   "  M%d: %s mirror of %s",
+	msgReportMoreMirrors:  "  ... and %d more",
+}
+
+// german translates the viewer into German. It sticks to Latin-1 so the
+// strings render with raylib's built-in font.
+var german = catalog{
+	msgTitle:               "Sprite-Sheet-Betrachter",
+	msgInfo:                "Info",
+	msgExport:              "Exportieren",
+	msgSettings:            "Einstellungen",
+	msgOpenFile:            "Datei öffnen",
+	msgCancel:              "Abbrechen",
+	msgClose:               "Schließen",
+	msgSave:                "Speichern",
+	msgDiscard:             "Verwerfen",
+	msgRestore:             "Wiederherstellen",
+	msgRecoveryTitle:       "Ungespeicherte Änderungen wiederherstellen?",
+	msgRecoveryFound:       "Eine nicht sauber beendete Sitzung hinterließ ungespeicherte Änderungen an %d Bögen.",
+	msgRestoredSheets:      "Ungespeicherte Änderungen an %d von %d Bögen wiederhergestellt",
+	msgRecoveryKept:        "Ungespeicherte Änderungen behalten; beim nächsten Start wird erneut gefragt",
+	msgRecoveryWriteFailed: "Wiederherstellungsdatei konnte nicht geschrieben werden: %v",
+	msgCopy:                "Kopieren",
+	msgCtrl:                "Strg",
+	msgShift:               "Umschalt",
+	msgSpace:               "Leertaste",
+
+	msgNoSheetLoaded:         "Kein Sprite-Sheet geladen. Mit '%s' eines auswählen.",
+	msgReloadFailed:          "Neu laden fehlgeschlagen: %v",
+	msgReloadFailedBanner:    "Neu laden fehlgeschlagen: %s (letzter gültiger Stand wird angezeigt)",
+	msgLoadErrorResolved:     "Letztes Neuladen fehlgeschlagen: %s (seitdem wieder geladen)",
+	msgErrorTruncated:        "... \"Fehler kopieren\" kopiert alles",
+	msgCopyError:             "Fehler kopieren",
+	msgCopiedError:           "Fehler mit Kontext kopiert",
+	msgAbout:                 "Über Sprite Sheet Viewer",
+	msgCopyDiagnostics:       "Diagnose kopieren",
+	msgDiagnosticsCopied:     "Diagnose kopiert",
+	msgLoadedSprites:         "%d Sprites geladen",
+	msgLoadedAseprite:        "%d Bilder und %d Tags aus %s geladen",
+	msgAsepriteFailed:        "%s wird ignoriert: %v",
+	msgAsepriteNoFrames:      "keine Bilder passen in das Sheet",
+	msgNoSprites:             "Keine Sprites im Sheet gefunden",
+	msgResourceManagerFailed: "Ressourcenverwaltung konnte nicht erstellt werden",
+	msgInvalidTexture:        "Ungültige Textur",
+	msgCouldNotRead:          "%s konnte nicht gelesen werden",
+	msgCouldNotWrite:         "%s konnte nicht geschrieben werden",
+	msgSelectSpriteToCopy:    "Sprite zum Kopieren auswählen",
+	msgCopyImageFailed:       "Kopieren fehlgeschlagen: %v",
+	msgCopiedImage:           "%s in die Zwischenablage kopiert",
+	msgDraggedOut:            "%s als PNG gespeichert, dort einfügen, wo es abgelegt wurde",
+	msgDragOutFailed:         "Sprite konnte nicht übergeben werden: %v",
+	msgSingleRow:             "Einzeiliges Sheet erkannt: V für die Streifenansicht drücken",
+	msgHoverInfo:             "#%d, Zelle %d (Spalte %d, Zeile %d) Quelle %s,%s %sx%s",
+	msgHoverChanged:          " geändert",
+	msgSelectedCount:         "%d ausgewählt",
+	msgReslicing:             "Wird neu aufgeteilt...",
+	msgUnsaved:               " (nicht gespeichert, %s)",
+	msgTooltipRect:           "%sx%s bei %s,%s",
+	msgTooltipIndex:          "Index %d",
+	msgInspector:             "Inspektor",
+	msgFit:                   "Einpassen",
+	msgSelectSpriteToInspect: "Sprite zum Untersuchen auswählen",
+	msgSelectSpriteToPin:     "Sprite zum Anheften auswählen",
+	msgInspectorZoom:         "%s bei %s",
+	msgDecimal:               "Dez",
+	msgHex:                   "Hex",
+	msgRectFields:            "x %s  y %s  b %s  h %s",
+	msgCopiedRects:           "Rechtecke von %d Sprites kopiert",
+	msgCopiedIndices:         "Indizes von %d Sprites kopiert (%s)",
+	msgMirrorOf:              "Spiegelbild von %s",
+	msgMirrorBadge:           "M%d",
+
+	msgMargin:                  "Rand",
+	msgGridSize:                "Rastergröße",
+	msgGridCapped:              "Max. %d: Sheet ist %dx%d",
+	msgOutlinePx:               "Rahmen px",
+	msgOutlineColor:            "Rahmenfarbe",
+	msgExportScale:             "Exportfaktor",
+	msgExportNames:             "Exportnamen",
+	msgAlphaThreshold:          "Leer bis Alpha",
+	msgLockSlicing:             "Slicing sperren",
+	msgContactLabels:           "Kontaktbogen-Namen",
+	msgContactGrid:             "Kontaktbogen-Raster",
+	msgRightToLeft:             "Von rechts nach links",
+	msgPresetLabel:             "Preset %s",
+	msgPresetActiveLabel:       "Preset %s (aktiv)",
+	msgStorePreset:             "Als %s speichern",
+	msgPresetGrid:              "Raster %d, Rand %d",
+	msgPresetCount:             "%dx%d Zellen, Rand %d",
+	msgPresetStored:            "Preset %s gespeichert: %s",
+	msgPresetActive:            "Preset %s: %s",
+	msgStorePresetsFirst:       "Zuerst Presets A und B in den Einstellungen speichern",
+	msgSlicingLocked:           "Slicing ist gesperrt; zuerst in den Einstellungen entsperren",
+	msgSlicingLockedOn:         "Slicing gesperrt",
+	msgSlicingUnlocked:         "Slicing entsperrt",
+	msgRightToLeftOn:           "Sprites von rechts nach links",
+	msgRightToLeftOff:          "Sprites von links nach rechts",
+	msgPinnedSprites:           "%d Sprites angeheftet",
+	msgUnpinnedSprites:         "%d Sprites gelöst",
+	msgTooManyPins:             "Es können bis zu %d Sprites angeheftet werden",
+	msgPins:                    "Angeheftet",
+	msgNameBySprite:            "Spritename",
+	msgNameByGrid:              "Zeile und Spalte",
+	msgNameByIndex:             "Index",
+	msgIndexBase:               "Sprite-Index",
+	msgIndexByName:             "Namensreihenfolge",
+	msgIndexBySheet:            "Blattzellen",
+	msgBackground:              "Hintergrund",
+	msgGuessBackground:         "Erraten",
+	msgKeyTolerance:            "Toleranz",
+	msgSilhouettes:             "Silhouetten",
+	msgSilhouetteColor:         "Silhouettenfarbe",
+	msgSilhouetteBackground:    "Silhouettenhintergrund",
+	msgMeasureGrid:             "Messraster",
+	msgMeasurePitch:            "Rasterabstand",
+	msgMeasureColor:            "Rasterfarbe",
+	msgExportNamingIs:          "Exportierte Dateien benannt nach: %s",
+	msgAtlasTrim:               "Atlas beschneiden",
+	msgAlphaTest:               "Alphatest",
+	msgResetOrder:              "Reihenfolge zurücksetzen",
+	msgZebraRows:               "Zebrazeilen",
+	msgTrueSize:                "Originalgröße",
+	msgSnapRows:                "An Zeilen ausrichten",
+	msgGroupPrefix:             "Nach Präfix gruppieren",
+	msgLabelOverlay:            "Beschriftung auf Zellen",
+	msgMipmaps:                 "Glatte Miniaturen",
+	msgAccessibility:           "Barrierefreiheit",
+	msgHighContrast:            "Hoher Kontrast",
+	msgColorblindSafe:          "Farbenblind-sicher",
+	msgFontSpacing:             "Zeichenabstand",
+	msgFontBaseline:            "Grundlinie",
+	msgStripSpacing:            "Streifenabstand",
+	msgStripVertical:           "Senkrechter Streifen",
+	msgStripTrim:               "Streifen beschneiden",
+	msgTextScale:               "Textgröße %",
+	msgByCount:                 "Nach Anzahl",
+	msgColumns:                 "Spalten",
+	msgRows:                    "Zeilen",
+	msgSettingsHelp:            "Scrollen, ziehen oder Klick und Auf/Ab zum Ändern, Doppelklick setzt zurück",
+	msgPreview:                 "Vorschau",
+	msgNoCellsFit:              "Mit diesen Einstellungen passt keine Zelle",
+	msgGridExceedsWidth:        "Raster %d ist breiter als das Sheet (%d)",
+	msgGridExceedsHeight:       "Raster %d ist höher als das Sheet (%d)",
+	msgGridMarginExceedsWidth:  "Raster %d mit Rand %d ist breiter als das Sheet (%d)",
+	msgGridMarginExceedsHeight: "Raster %d mit Rand %d ist höher als das Sheet (%d)",
+	msgImageEmpty:              "Bild ist %dx%d und hat keine Pixel",
+	msgImageTooSmall:           "Bild %dx%d zu klein für Rastergröße %d",
+	msgCountDoesntFit:          "%d Spalten x %d Zeilen passen nicht in ein %dx%d-Sheet",
+	msgCountInexact:            "%dx%d px lassen sich nicht in %d x %d ganze Zellen teilen; Zellen mit %dx%d px werden verwendet",
+
+	msgUndone:         "Rückgängig: %s",
+	msgRedone:         "Wiederholt: %s",
+	msgNothingToUndo:  "Nichts rückgängig zu machen",
+	msgNothingToRedo:  "Nichts zu wiederholen",
+	msgSliceByGrid:    "nach Rastergröße aufteilen",
+	msgSliceByCount:   "nach Anzahl aufteilen",
+	msgMoveSprite:     "%s verschieben",
+	msgAlreadyInOrder: "Die Sprites sind bereits in Sheet-Reihenfolge",
+
+	msgActOpen:                 "Datei öffnen",
+	msgActEditSheet:            "In externem Editor bearbeiten",
+	msgActEditSprite:           "Dieses Sprite bearbeiten",
+	msgActRenameSprites:        "Sprites umbenennen",
+	msgActNewTab:               "In neuem Tab öffnen",
+	msgActCloseTab:             "Tab schließen",
+	msgActWatchFolder:          "Ordner beobachten (oder fortsetzen)",
+	msgActStopWatch:            "Ordner nicht mehr beobachten",
+	msgActSaveFolderDefaults:   "Raster als Ordnervorgabe speichern",
+	msgActReload:               "Sheet neu laden",
+	msgActSave:                 "Einstellungen speichern",
+	msgActExportSprites:        "Sprites exportieren",
+	msgActExportAtlas:          "Atlas exportieren",
+	msgActExportFontStrip:      "Schriftstreifen exportieren",
+	msgActExportStrip:          "Sprite-Streifen exportieren",
+	msgActExportContact:        "Kontaktbogen exportieren",
+	msgActExportGIFs:           "Animationen als GIFs exportieren",
+	msgActExportSubSheet:       "Teilbogen exportieren",
+	msgActRightToLeft:          "Reihenfolge von rechts nach links ein/aus",
+	msgActPin:                  "Ausgewählte Sprites anheften/lösen",
+	msgActCompare:              "Mit Datei vergleichen",
+	msgActCloseCompare:         "Vergleich schließen",
+	msgActLoadPalette:          "Mit Palettenzuordnung umfärben",
+	msgActRecolorBefore:        "Umfärbung vorher/nachher umschalten",
+	msgActCloseRecolor:         "Umfärbevorschau schließen",
+	msgActUndo:                 "Rückgängig",
+	msgActRedo:                 "Wiederholen",
+	msgActSheetInfo:            "Sheet-Info",
+	msgActAbout:                "Über",
+	msgActPerfOverlay:          "Leistungsanzeige",
+	msgActCellCheck:            "Zellen mit erwartetem Raster abgleichen",
+	msgActMeasureGrid:          "Messraster umschalten",
+	msgActLockSlicing:          "Slicing sperren oder entsperren",
+	msgActContentSizes:         "Histogramm der Inhaltsgrößen",
+	msgActShowAll:              "Alle Sprites anzeigen",
+	msgActCheckUsages:          "In einer Datei verwendete Sprite-Namen prüfen",
+	msgActCheckUsagesClipboard: "Sprite-Namen in der Zwischenablage prüfen",
+	msgActToggleSettings:       "Einstellungen ein/aus",
+	msgActToggleAnimation:      "Animationsvorschau ein/aus",
+	msgActInspector:            "Sprite-Inspektor ein/aus",
+	msgActPlayPause:            "Animation abspielen/anhalten",
+	msgActPrevFrame:            "Vorheriges Animationsbild",
+	msgActNextFrame:            "Nächstes Animationsbild",
+	msgActLoopIn:               "Schleifenanfang auf aktuelles Bild",
+	msgActLoopOut:              "Schleifenende auf aktuelles Bild",
+	msgActClearLoop:            "Animationsschleife aufheben",
+	msgActStripView:            "Streifenansicht ein/aus",
+	msgActSheetView:            "Gesamtansicht ein/aus",
+	msgActNativeView:           "Originalgröße-Ansicht umschalten",
+	msgActZebra:                "Zebrazeilen ein/aus",
+	msgActLabelOverlay:         "Beschriftung auf Zellen umschalten",
+	msgActTrueSize:             "Originalgröße ein/aus",
+	msgActSnapRows:             "An Zeilen ausrichten ein/aus",
+	msgActGroupPrefix:          "Gruppierung nach Präfix ein/aus",
+	msgActHighContrast:         "Hoher Kontrast ein/aus",
+	msgActColorblind:           "Farbenblind-sichere Farben ein/aus",
+	msgActCopyImage:            "Sprite-Bild kopieren",
+	msgActCopyRects:            "Sprite-Rechtecke kopieren",
+	msgActCopyIndices:          "Sprite-Indizes kopieren",
+	msgActGuessBackground:      "Hintergrund erraten und ausblenden",
+	msgActSilhouettes:          "Silhouetten ein/aus",
+	msgActHexCoords:            "Hexadezimale Koordinaten umschalten",
+	msgActExportNaming:         "Benennung exportierter Dateien wechseln",
+	msgActPreviewDownscale:     "Vorschau verkleinern (1x/2x/4x) umschalten",
+	msgActMipmaps:              "Glatte Miniaturen (Mipmaps) umschalten",
+	msgActGammaCorrection:      "Dateigamma ausgleichen",
+	msgActResetOrder:           "Sprite-Reihenfolge zurücksetzen",
+	msgActStorePresetA:         "Einstellungen als Preset A speichern",
+	msgActStorePresetB:         "Einstellungen als Preset B speichern",
+	msgActTogglePreset:         "Preset A/B umschalten",
+	msgActPalette:              "Befehlspalette",
+	msgNoSheetToReload:         "Kein Sheet zum Neuladen",
+	msgReloaded:                "%s neu geladen",
+	msgNothingToSave:           "Nichts zu speichern",
+	msgSaved:                   "%s gespeichert",
+	msgEditIn:                  "Bearbeiten in %s",
+	msgDefaultEditor:           "Editor",
+	msgNoSheetToEdit:           "Kein Sheet zum Bearbeiten",
+	msgEditorNotFound:          "Editor nicht gefunden: %s",
+	msgEditorFailed:            "Editor konnte nicht gestartet werden: %v",
+	msgEditing:                 "%s wird in %s bearbeitet; Speichern lädt es hier neu",
+	msgEditingSprite:           "%s liegt bei %d, %d (%dx%d), kopiert; Bogen in %s geöffnet",
+	msgSelectSpriteToEdit:      "Zuerst auf das zu bearbeitende Sprite zeigen oder es auswählen",
+	msgReloadedAfterEdit:       "%s nach dem Speichern neu geladen",
+	msgLargeTexture:            "%s belegt %s Texturspeicher, mehr als %d MB; „Vorschau verkleinern“ zeigt es kleiner an",
+	msgPreviewDownscale:        "Vorschau %dx verkleinert",
+	msgPreviewFullSize:         "Vorschau in voller Größe",
+	msgMipmapsOn:               "Mipmaps in %d ms erzeugt",
+	msgMipmapsOff:              "Mipmaps aus",
+	msgNonstandardGamma:        "%s setzt Gamma %s und sieht im Grafikprogramm womöglich anders aus; \"Dateigamma ausgleichen\" zeigt es so an",
+	msgNoFileGamma:             "%s setzt kein Gamma zum Ausgleichen",
+	msgGammaCorrected:          "Sheet wird für Gamma %s ausgeglichen angezeigt",
+	msgGammaRaw:                "Sheet wird ohne Gammaausgleich angezeigt",
+	msgGammaFailed:             "Gammaausgleich fehlgeschlagen: %v",
+	msgTextureMemory:           "VRAM %s",
+	msgDownscaled:              " (%dx kleiner)",
+	msgTextureMemoryTabs:       ", %s in %d Tabs",
+	msgNewTab:                  "Neuer Tab",
+	msgTooManyTabs:             "Es können höchstens %d Tabs offen sein",
+	msgWatching:                "Beobachte %s auf neue Sheets",
+	msgWatchFailed:             "Ordner kann nicht beobachtet werden: %v",
+	msgWatchStopped:            "Ordner wird nicht mehr beobachtet",
+	msgWatchPaused:             "Ordnerbeobachtung pausiert; in der Befehlspalette fortsetzen",
+	msgWatchHeld:               "%s ist im beobachteten Ordner erschienen; zum Öffnen die Änderungen speichern",
+	msgWatchingStatus:          "beobachte %s",
+	msgWatchPausedStatus:       "Beobachtung pausiert: %s",
+	msgSavedFolderDefaults:     "Rastervorgaben für %s gespeichert",
+	msgSaveFolderFailed:        "Ordnervorgaben konnten nicht gespeichert werden: %v",
+	msgCompareNeedsSheet:       "Vor dem Vergleichen ein Sheet öffnen",
+	msgTypeCommand:             "Befehl eingeben...",
+	msgNoMatchingCommands:      "Keine passenden Befehle",
+
+	msgAnimation:       "Animation",
+	msgPlayRate:        "Anim %.1f/%.1f fps, Rendern %d fps",
+	msgFrame:           "Bild %d (%s)",
+	msgStart:           "Anfang",
+	msgEnd:             "Ende",
+	msgFPS:             "FPS",
+	msgRangeSwapped:    "Der Anfang lag hinter dem Ende; getauscht zu %d-%d",
+	msgRangeInfo:       "%d Bilder, %.2f s bei %d fps",
+	msgRangeInfoTimed:  "%d Bilder, %.2f s (Bilddauern)",
+	msgLoopInfo:        "Schleife Bilder %d-%d (%s hebt auf)",
+	msgAllFrames:       "Alle Bilder",
+	msgTag:             "Tag: %s",
+	msgDurations:       "Dauer",
+	msgSetAll:          "Alle setzen",
+	msgSetAllDurations: "Bilddauern auf %d ms gesetzt",
+	msgFrameDuration:   "Dauer von %s",
+	msgMS:              "ms",
+	msgPlay:            "Abspielen",
+	msgPause:           "Anhalten",
+
+	msgNothingToExport:   "Nichts zu exportieren",
+	msgExportRunning:     "Es läuft bereits ein Export",
+	msgExportReadFailed:  "Export fehlgeschlagen: %s konnte nicht gelesen werden",
+	msgExportWriteFailed: "Export fehlgeschlagen: %s konnte nicht geschrieben werden",
+	msgExportFailed:      "Export fehlgeschlagen: %v",
+	msgExportFailedAfter: "Export nach %d von %d Sprites fehlgeschlagen: %v",
+	msgExportCancelled:   "Export abgebrochen: %d von %d Sprites wurden nach %s geschrieben",
+	msgExported:          "%d Sprites nach %s exportiert",
+	msgExportedSkipped:   "%d Sprites nach %s exportiert (%d vorhandene übersprungen)",
+	msgWroteAtlas:        "Atlas mit %d Sprites nach %s geschrieben",
+	msgWroteGlyphs:       "%d Zeichen nach %s geschrieben",
+	msgSelectGlyphs:      "Zuerst die Zeichen-Sprites zum Exportieren auswählen",
+	msgSubSheet:          "Zellbereich exportieren",
+	msgSubSheetNeedsGrid: "Teilbögen brauchen einen in ein Raster geteilten Bogen",
+	msgRenameTitle:       "%d Sprites umbenennen",
+	msgFindReplace:       "Suchen und ersetzen",
+	msgRenumber:          "Nummerieren",
+	msgFind:              "Suchen",
+	msgReplaceWith:       "Ersetzen durch",
+	msgGlobHint:          "* für beliebigen Text, ? für ein Zeichen; * im Ersatz fügt ihn ein",
+	msgRenamePattern:     "Muster, {n} ist die Nummer",
+	msgStartAt:           "Beginnen bei",
+	msgRenameSummary:     "%d umbenannt, %d Konflikte",
+	msgRenameMore:        "+ %d weitere",
+	msgNameTaken:         "Name vergeben",
+	msgNameEmpty:         "leerer Name",
+	msgNameInvalid:       "kein Dateiname",
+	msgRename:            "Umbenennen",
+	msgRenamedSprites:    "%d Sprites umbenannt",
+	msgNothingToRename:   "Keine Sprites zum Umbenennen",
+	msgFirstColumn:       "Erste Spalte",
+	msgLastColumn:        "Letzte Spalte",
+	msgFirstRow:          "Erste Zeile",
+	msgLastRow:           "Letzte Zeile",
+	msgSubSheetSize:      "%dx%d Zellen, %dx%d px",
+	msgWroteSubSheet:     "%d Sprites (%dx%d Zellen) nach %s geschrieben",
+	msgPerfFPS:           "%d FPS, %.1f ms pro Bild",
+	msgPerfThumbnails:    "Vorschaubilder: %d gezeichnet, %d ausgelassen",
+	msgPerfSprites:       "Raster: %s",
+	msgPerfUpdate:        "Update: %s",
+	msgPerfSorting:       "Sprite-Reihenfolge: %s",
+	msgPerfTextures:      "Uploads: %d, Texturen %s",
+	msgPerfLoad:          "Letztes Laden: %s, Textur %s",
+	msgPerfSlicing:       "Zerlegen: %s, Namen %s",
+	msgGlyphsEmpty:       "Die ausgewählten Sprites sind alle leer",
+	msgSelectStripFrames: "Zuerst die Frames des Streifens auswählen",
+	msgStripEmpty:        "Die ausgewählten Sprites sind alle leer",
+	msgNoAnimations:      "Keine Animationen zum Exportieren: Bogen in Aseprite taggen oder Frames mit gemeinsamem Präfix benennen",
+	msgExportedGIFs:      "%d Animationen nach %s exportiert",
+	msgGIFsCancelled:     "Export abgebrochen: %d von %d Animationen wurden nach %s geschrieben",
+	msgGIFsFailed:        "%d von %d Animationen nach %s exportiert, %d fehlgeschlagen: %s",
+	msgWroteStrip:        "Streifen mit %d Frames zu %dx%d nach %s geschrieben, in %s",
+	msgWroteContactSheet: "Kontaktbogen mit %d Sprites in %d Spalten und %d Zeilen nach %s geschrieben, in %s",
+	msgContactTooLarge:   "Der Kontaktbogen wäre %dx%d groß, über der Grenze von %d Pixeln; Exportmaßstab senken oder das Raster filtern",
+	msgOrderNatural:      "Namensreihenfolge",
+	msgOrderRightToLeft:  "Reihenfolge von rechts nach links",
+	msgOrderCustom:       "eigener Reihenfolge",
+	msgOrderAseprite:     "Aseprite-Framereihenfolge",
+	msgOrderGrouped:      "%s, nach Präfix gruppiert",
+	msgFilesExist:        "%d von %d Dateien existieren bereits",
+	msgAndMore:           "...und %d weitere",
+	msgOverwriteAll:      "Alle überschreiben",
+	msgSkipExisting:      "Vorhandene überspringen",
+	msgRenameNew:         "Neue umbenennen",
+
+	msgCompareFailed:      "Vergleich fehlgeschlagen: %v",
+	msgRecolorNeedsSheet:  "Zuerst ein Sheet zum Umfärben öffnen",
+	msgRecolorFailed:      "Umfärben fehlgeschlagen: %v",
+	msgKeyNeedsSheet:      "Zuerst ein Sheet öffnen, um seinen Hintergrund auszublenden",
+	msgKeyFailed:          "Hintergrund ausblenden fehlgeschlagen: %v",
+	msgKeyedBackground:    "%s ausgeblendet: %d Pixel",
+	msgKeySummary:         "Hintergrund %s ausgeblendet: %d Pixel innerhalb %d",
+	msgPickingKey:         "Ein Pixel des Sheets anklicken, um seine Farbe auszublenden",
+	msgPickKey:            "Pipette",
+	msgRecolored:          "%d Pixel mit %d Farbtauschen umgefärbt",
+	msgNoRecolor:          "Keine Palettenzuordnung geladen",
+	msgBadColor:           "\"%s\" ist keine Farbe wie #RRGGBB oder #RRGGBBAA",
+	msgBadPaletteLine:     "Zeile %d: alte und neue Farbe erwartet: %s",
+	msgEmptyPalette:       "%s enthält keine Farbtausche",
+	msgRecolorSummary:     "Palette %s: %d Pixel durch %d Tausche umgefärbt, zeigt %s",
+	msgRecolorAfter:       "nachher",
+	msgRecolorBefore:      "vorher",
+	msgShowOriginal:       "Vorher",
+	msgShowRecolored:      "Nachher",
+	msgDiffSummary:        "%d von %d Sprites geändert, %d hinzugefügt, %d entfernt",
+	msgDiffSizes:          "Größen unterscheiden sich: %dx%d gegenüber %dx%d",
+	msgDiffVersus:         "gegenüber %s: %s",
+	msgExportList:         "Liste exportieren",
+	msgWroteChangeList:    "Änderungsliste nach %s geschrieben",
+	msgUsagesIn:           "Sprite-Namen in %s",
+	msgUsagesFound:        "Gefunden (%d)",
+	msgUsagesMissing:      "Fehlen im Sheet (%d)",
+	msgUsagesUnused:       "Nie verwendet (%d)",
+	msgUsagesMore:         "... und %d weitere",
+	msgSelectFound:        "Gefundene auswählen",
+	msgWroteUsages:        "Verwendungsbericht nach %s geschrieben",
+	msgOpenSheetForUsages: "Zuerst ein Sheet zum Abgleichen öffnen",
+	msgUsagesReadFailed:   "Datei konnte nicht gelesen werden: %v",
+	msgClipboard:          "Zwischenablage",
+	msgSaveChanges:        "Änderungen an den Sheet-Metadaten speichern?",
+	msgSaveMetaFailed:     "Sheet-Metadaten konnten nicht gespeichert werden: %w",
+
+	msgSheetInfo:          "Sheet-Info",
+	msgContentSizes:       "Inhaltsgrößen",
+	msgOtherSizes:         "andere",
+	msgEmptySize:          "leer",
+	msgFullCellSize:       "%dx%d (ganze Zelle)",
+	msgShowAll:            "Alle zeigen",
+	msgFiltered:           "%d Sprites %s",
+	msgFilteredNoArrange:  "Alle Sprites anzeigen, um sie umzuordnen",
+	msgOpenSheetForInfo:   "Ein Sheet öffnen, um seine Infos zu sehen",
+	msgInspectFailed:      "Pixel konnten nicht untersucht werden: %v",
+	msgInfoCopied:         "Sheet-Info in die Zwischenablage kopiert",
+	msgReportSheet:        "Sheet: %s",
+	msgReportFile:         "Datei: %s, geändert %s",
+	msgReportFileError:    "Datei: %v",
+	msgReportHeader:       "Header: %s %dx%d, %s",
+	msgReportFormat:       "Header: %s",
+	msgReportColor:        "Farbe: %s",
+	msgReportNoColor:      "Farbe: kein Gamma oder Farbprofil gesetzt",
+	msgReportGammaWarning: "(nicht sRGB; ohne \"Dateigamma ausgleichen\" unkorrigiert angezeigt)",
+	msgReportSize:         "Größe: %dx%d px",
+	msgReportTexture:      "Texturspeicher: %s",
+	msgReportMipmaps:      "Mipmaps: in %d ms erzeugt",
+	msgReportNoMipmaps:    "Mipmaps: aus",
+	msgReportCells:        "Zellen: %dx%d px, Rand %d px",
+	msgReportLayout:       "Aufteilung: %d Spalten x %d Zeilen",
+	msgReportCellCheck:    "Rasterprüfung: %s",
+	msgCellCheckOK:        "alle %d erwarteten Zellen vorhanden",
+	msgCellCheckCounts:    "%d Zellen erwartet, Sheet enthält %d",
+	msgMissingColumn:      "Spalte %d fehlt (Zeilen 0-%d)",
+	msgMissingRow:         "Zeile %d fehlt (Spalten 0-%d)",
+	msgMissingCells:       "%d weitere Zellen fehlen",
+	msgUnexpectedCells:    "%d unerwartete Zellen",
+	msgCellCheckGridOnly:  "Die Rasterprüfung gilt nur für nach Rastergröße geteilte Sheets",
+	msgLegendExpected:     "erwartetes Raster",
+	msgLegendPresent:      "Zellen im Sheet",
+	msgReportSprites:      "Sprites: %d",
+	msgReportEmpty:        "Leere Zellen: %d",
+	msgReportDuplicates:   "Duplikatgruppen: %d (%d Sprites)",
+	msgReportMirrors:      "Spiegelpaare: %d",
+	msgReportMirrorPair:   "  M%d: %s Spiegelbild von %s",
+	msgReportMoreMirrors:  "  ... und %d weitere",
+}
+
+// catalogs holds every language the viewer is translated into, keyed by
+// ISO 639-1 code.
+var catalogs = map[string]catalog{
+	"en": english,
+	"de": german,
+}
+
+// uiMessages is the catalog of the viewer currently drawing. Like uiText it
+// is set for the duration of Update, Draw and Load, and is only touched from
+// the goroutine that owns the window, so background export work reports in
+// English.
+var uiMessages = english
+
+// tr returns the string for id in the current language, falling back to
+// English.
+func tr(id msgID) string {
+	if text, ok := uiMessages[id]; ok {
+		return text
+	}
+	return english[id]
+}
+
+// trf formats the string for id with args, like fmt.Sprintf.
+func trf(id msgID, args ...any) string {
+	return fmt.Sprintf(tr(id), args...)
+}
+
+// lookupCatalog returns the catalog for a language name such as "de",
+// "de_DE" or "de_DE.UTF-8", or English if the language isn't translated.
+func lookupCatalog(lang string) catalog {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if i := strings.IndexAny(lang, "_-"); i >= 0 {
+		lang = lang[:i]
+	}
+	if c, ok := catalogs[lang]; ok {
+		return c
+	}
+	return english
+}
+
+// envLanguage returns the language the environment asks for, checking the
+// locale variables in the order POSIX gives them precedence.
+func envLanguage() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang := os.Getenv(name); lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
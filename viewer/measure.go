@@ -0,0 +1,56 @@
+package viewer
+
+import (
+	"image/color"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// defaultMeasurePitch is the spacing of the measurement grid in sheet
+// pixels, the most common tile size.
+const defaultMeasurePitch int32 = 8
+
+// measureColors are the colors the settings panel cycles through for the
+// measurement grid. They are saturated so it never reads as the slicing
+// grid, which is drawn in the theme's muted cell border color.
+var measureColors = []color.RGBA{rl.SkyBlue, rl.Magenta, rl.Orange, rl.Lime}
+
+// measureColor returns the color the measurement grid is drawn in.
+func (s *UIState) measureColor() color.RGBA {
+	return measureColors[s.measureInk%len(measureColors)]
+}
+
+// drawMeasureGrid draws the measurement grid over dest, which shows the part
+// src of the sheet. Lines fall on multiples of the pitch counted from the
+// sheet's top left corner, whatever the slicing, so alignment to a tile
+// standard can be checked on its own. It is left out when the lines would be
+// too close together to tell apart.
+func (s *UIState) drawMeasureGrid(src, dest rl.Rectangle) {
+	pitch := float32(s.measurePitch)
+	scale := dest.Width / src.Width
+	if pitch <= 0 || pitch*scale < 4 {
+		return
+	}
+	col := rl.ColorAlpha(s.measureColor(), 0.7)
+	first := func(from float32) float32 {
+		return float32(math.Ceil(float64(from/pitch))) * pitch
+	}
+	for x := first(src.X); x <= src.X+src.Width; x += pitch {
+		sx := dest.X + (x-src.X)*scale
+		rl.DrawLineV(rl.Vector2{X: sx, Y: dest.Y}, rl.Vector2{X: sx, Y: dest.Y + dest.Height}, col)
+	}
+	for y := first(src.Y); y <= src.Y+src.Height; y += pitch {
+		sy := dest.Y + (y-src.Y)*scale
+		rl.DrawLineV(rl.Vector2{X: dest.X, Y: sy}, rl.Vector2{X: dest.X + dest.Width, Y: sy}, col)
+	}
+}
+
+// toggleMeasureGrid shows or hides the measurement grid, switching to the
+// sheet view to show it unless the inspector is open.
+func (s *UIState) toggleMeasureGrid() {
+	s.measureGrid = !s.measureGrid
+	if s.measureGrid && !s.inspect.visible {
+		s.viewMode = sheetView
+	}
+}
@@ -0,0 +1,88 @@
+package viewer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// sheetMemory remembers the slicing and view settings last used with each
+// sheet, by absolute path, so a familiar sheet opens the way it was left
+// even if it has no sidecar. It is shared by all tabs and written to
+// Options.SettingsFile when the viewer closes.
+type sheetMemory struct {
+	path   string
+	sheets map[string]rememberedSheet
+}
+
+// rememberedSheet is what sheetMemory keeps for one sheet. Columns and Rows
+// are set when it was sliced by count.
+type rememberedSheet struct {
+	Margin   int32    `json:"margin"`
+	GridSize int32    `json:"gridSize"`
+	Columns  int32    `json:"columns,omitempty"`
+	Rows     int32    `json:"rows,omitempty"`
+	View     viewMode `json:"view,omitempty"`
+}
+
+// loadSheetMemory reads the settings remembered in the file at path. A
+// missing or unreadable file starts an empty memory.
+func loadSheetMemory(path string) *sheetMemory {
+	m := &sheetMemory{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &m.sheets)
+	}
+	if m.sheets == nil {
+		m.sheets = make(map[string]rememberedSheet)
+	}
+	return m
+}
+
+// save writes the memory back to its file.
+func (m *sheetMemory) save() error {
+	if m == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.sheets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, data, 0o644)
+}
+
+// memoryKey returns the key a sheet is remembered under.
+func memoryKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// rememberSheet records the settings the current sheet is shown with.
+func (s *UIState) rememberSheet() {
+	if s.memory == nil || s.sheet == nil || s.currentFile == "" {
+		return
+	}
+	r := rememberedSheet{Margin: s.margin, GridSize: s.gridSize, View: s.viewMode}
+	if s.sliceByCount {
+		r.Columns, r.Rows = s.columns, s.rows
+	}
+	s.memory.sheets[memoryKey(s.currentFile)] = r
+}
+
+// recall returns the settings remembered for the sheet at path, and reports
+// whether there were any.
+func (m *sheetMemory) recall(path string) (rememberedSheet, bool) {
+	if m == nil {
+		return rememberedSheet{}, false
+	}
+	r, ok := m.sheets[memoryKey(path)]
+	return r, ok
+}
+
+// meta returns the remembered slicing as sidecar metadata, which like a
+// sidecar without them clears the custom order and other metadata of the
+// sheet shown before when applied.
+func (r rememberedSheet) meta() sheetMeta {
+	return sheetMeta{Margin: r.Margin, GridSize: r.GridSize, Columns: r.Columns, Rows: r.Rows}
+}
@@ -0,0 +1,154 @@
+package viewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// sheetMeta is the per-sheet metadata saved next to the image as a JSON
+// sidecar, so a tuned sheet opens the same way next time.
+type sheetMeta struct {
+	Margin   int32 `json:"margin"`
+	GridSize int32 `json:"gridSize"`
+	// Columns and Rows are set when the sheet is sliced by count rather than
+	// by grid size.
+	Columns int32 `json:"columns,omitempty"`
+	Rows    int32 `json:"rows,omitempty"`
+	// Order is the custom display order of sprites, if one was set.
+	Order []string `json:"order,omitempty"`
+	// Durations are the animation preview's frame durations in
+	// milliseconds, by sprite name.
+	Durations map[string]int32 `json:"durations,omitempty"`
+	// Names are the names sprites were renamed to, by the name slicing
+	// gives them.
+	Names map[string]string `json:"names,omitempty"`
+	// PresetA and PresetB are the slicing presets stored to flip between.
+	PresetA *slicingPreset `json:"presetA,omitempty"`
+	PresetB *slicingPreset `json:"presetB,omitempty"`
+	// Locked keeps the slicing settings from being edited.
+	Locked bool `json:"locked,omitempty"`
+	// RightToLeft reads the sheet's frames from right to left.
+	RightToLeft bool `json:"rightToLeft,omitempty"`
+}
+
+// metaPath returns the sidecar file used for the sheet at path.
+func metaPath(path string) string {
+	return path + ".viewer.json"
+}
+
+// loadMeta reads the sidecar for the sheet at path. It reports false if
+// there is no usable sidecar.
+func loadMeta(path string) (sheetMeta, bool) {
+	data, err := os.ReadFile(metaPath(path))
+	if err != nil {
+		return sheetMeta{}, false
+	}
+
+	var meta sheetMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return sheetMeta{}, false
+	}
+	return meta, true
+}
+
+// currentMeta returns the metadata of the current settings.
+func (s *UIState) currentMeta() sheetMeta {
+	meta := sheetMeta{
+		Margin:      s.margin,
+		GridSize:    s.gridSize,
+		Order:       s.order,
+		Durations:   s.durations,
+		Names:       s.renames,
+		PresetA:     s.presets[0],
+		PresetB:     s.presets[1],
+		Locked:      s.slicingLocked,
+		RightToLeft: s.rightToLeft,
+	}
+	if s.sliceByCount {
+		meta.Columns, meta.Rows = s.columns, s.rows
+	}
+	return meta
+}
+
+// applyMeta restores the settings saved in meta.
+func (s *UIState) applyMeta(meta sheetMeta) {
+	s.margin, s.gridSize, s.order = meta.Margin, meta.GridSize, meta.Order
+	s.durations, s.renames = meta.Durations, meta.Names
+	s.presets = [2]*slicingPreset{meta.PresetA, meta.PresetB}
+	s.slicingLocked = meta.Locked
+	s.rightToLeft = meta.RightToLeft
+	s.sliceByCount = meta.Columns > 0 && meta.Rows > 0
+	if s.sliceByCount {
+		s.columns, s.rows = meta.Columns, meta.Rows
+	}
+}
+
+// saveMeta writes the current sheet's metadata to its sidecar.
+func (s *UIState) saveMeta() error {
+	if s.currentFile == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.currentMeta(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(metaPath(s.currentFile), data, 0o644); err != nil {
+		return fmt.Errorf(tr(msgSaveMetaFailed), err)
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// save writes the sidecar and reports the outcome with a toast.
+func (s *UIState) save() bool {
+	if err := s.saveMeta(); err != nil {
+		s.notifyText(err.Error())
+		return false
+	}
+	s.notify(msgSaved, metaPath(s.currentFile))
+	return true
+}
+
+// requestClose is called when the window is asked to close. Unsaved
+// metadata holds the close until the user decides what to do with it.
+func (s *UIState) requestClose() {
+	if s.dirty {
+		s.confirmClose = true
+		return
+	}
+	s.quit = true
+}
+
+// renderCloseConfirm draws the unsaved changes dialog shown on close.
+func (s *UIState) renderCloseConfirm(cfg Config) rl.Rectangle {
+	title := tr(msgSaveChanges)
+	saveWidth := buttonWidth(tr(msgSave), 90)
+	discardWidth := buttonWidth(tr(msgDiscard), 90)
+	cancelWidth := buttonWidth(tr(msgCancel), 90)
+	width := max(320, float32(measureText(title, 15)+20), saveWidth+discardWidth+cancelWidth+50)
+
+	panel := rl.Rectangle{X: (float32(cfg.width) - width) / 2, Y: float32(cfg.height)/2 - 80, Width: width, Height: 100}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(title, int32(panel.X)+10, int32(panel.Y)+15, 15, s.theme.Text)
+
+	buttonY := panel.Y + panel.Height - 35
+	if drawButton(rl.Rectangle{X: panel.X + 10, Y: buttonY, Width: saveWidth, Height: 25}, tr(msgSave)) {
+		if s.save() {
+			s.quit = true
+		}
+		s.confirmClose = false
+	}
+	if drawButton(rl.Rectangle{X: panel.X + saveWidth + 25, Y: buttonY, Width: discardWidth, Height: 25}, tr(msgDiscard)) {
+		s.quit = true
+	}
+	if drawButton(rl.Rectangle{X: panel.X + panel.Width - 10 - cancelWidth, Y: buttonY, Width: cancelWidth, Height: 25}, tr(msgCancel)) {
+		s.confirmClose = false
+	}
+	return panel
+}
@@ -0,0 +1,70 @@
+package viewer
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// nativeLayout is where the native view puts each sprite, in grid
+// coordinates before scrolling, for the layout and sprite list it was worked
+// out for.
+type nativeLayout struct {
+	cfg    Config
+	names  []string
+	cells  []rl.Rectangle
+	height int32
+}
+
+// nativeCells lays the sprites out at their own pixel size, packed left to
+// right in rows that wrap at the window width, each row as tall as its
+// tallest sprite. The layout is kept until the window or the sprites change,
+// and it returns the height of the content.
+func (s *UIState) nativeCells(cfg Config) ([]rl.Rectangle, int32) {
+	l := &s.native
+	if l.cfg == cfg && len(l.names) == len(s.spriteNames) && (len(l.names) == 0 || &l.names[0] == &s.spriteNames[0]) {
+		return l.cells, l.height
+	}
+	l.cfg, l.names = cfg, s.spriteNames
+	l.cells = make([]rl.Rectangle, len(s.spriteNames))
+	right := cfg.width - cfg.startX
+	x, y, rowHeight := cfg.startX, cfg.startY, int32(0)
+	for i, name := range s.spriteNames {
+		rect := s.sheet.Sprites[name]
+		if x > cfg.startX && x+rect.Width > right {
+			x, y, rowHeight = cfg.startX, y+rowHeight+cfg.labelHeight()+cfg.padding, 0
+		}
+		l.cells[i] = rl.Rectangle{X: float32(x), Y: float32(y), Width: float32(rect.Width), Height: float32(rect.Height)}
+		x += rect.Width + cfg.padding
+		rowHeight = max(rowHeight, rect.Height)
+	}
+	l.height = y + rowHeight + cfg.labelHeight() + cfg.padding
+	return l.cells, l.height
+}
+
+// nativeCellRect returns the on-screen cell of the sprite at index i in the
+// native view. It is kept on whole pixels, so each sprite pixel covers
+// exactly one screen pixel.
+func (s *UIState) nativeCellRect(cfg Config, i int) rl.Rectangle {
+	cells, _ := s.nativeCells(cfg)
+	cell := cells[i]
+	cell.Y -= float32(int32(s.scrollOffset))
+	return cell
+}
+
+// nativeCellAt returns the index of the sprite under p in the native view,
+// or -1 if there is none.
+func (s *UIState) nativeCellAt(cfg Config, p rl.Vector2) int {
+	cells, _ := s.nativeCells(cfg)
+	for i := range cells {
+		if rl.CheckCollisionPointRec(p, s.nativeCellRect(cfg, i)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggleNativeView switches between the grid and the native view.
+func (s *UIState) toggleNativeView() {
+	if s.viewMode == nativeView {
+		s.viewMode = gridView
+	} else {
+		s.viewMode = nativeView
+	}
+}
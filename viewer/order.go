@@ -0,0 +1,181 @@
+package viewer
+
+import (
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// dragThreshold is how far, in pixels, the mouse has to move with the button
+// held on a thumbnail before it is picked up for reordering.
+const dragThreshold = 5
+
+// spriteDrag tracks a thumbnail being dragged to a new position.
+type spriteDrag struct {
+	pressed bool
+	active  bool
+	from    int
+	start   rl.Vector2
+}
+
+// applyOrder arranges names, given in natural order, by a custom order.
+// Names listed in order come first in that sequence; any others follow in
+// their natural order. Entries of order that are not in names are ignored,
+// so an order survives reslicing the sheet and back.
+func applyOrder(order, names []string) []string {
+	present := make(map[string]bool, len(names))
+	for _, name := range names {
+		present[name] = true
+	}
+
+	arranged := make([]string, 0, len(names))
+	placed := make(map[string]bool, len(names))
+	for _, name := range order {
+		if present[name] && !placed[name] {
+			arranged = append(arranged, name)
+			placed[name] = true
+		}
+	}
+	for _, name := range names {
+		if !placed[name] {
+			arranged = append(arranged, name)
+		}
+	}
+	return arranged
+}
+
+// setOrder replaces the custom display order. A nil order restores the
+// natural sort order.
+func (s *UIState) setOrder(order []string) {
+	s.order = order
+	s.dirty = true
+	if s.sheet != nil {
+		s.updateSpriteNames()
+	}
+}
+
+// orderEdit records a change of the custom display order.
+func orderEdit(desc string, from, to []string) edit {
+	return edit{
+		desc: desc,
+		undo: func(s *UIState) { s.setOrder(from) },
+		redo: func(s *UIState) { s.setOrder(to) },
+	}
+}
+
+// moveSprite moves the sprite at display index from to index to, shifting
+// the sprites in between.
+func (s *UIState) moveSprite(from, to int) {
+	if from == to {
+		return
+	}
+	if s.filter != nil {
+		// The filtered grid leaves sprites out, and an order taken from
+		// it would drop them.
+		s.notify(msgFilteredNoArrange)
+		return
+	}
+	order := append([]string(nil), s.spriteNames...)
+	name := order[from]
+	order = append(order[:from], order[from+1:]...)
+	order = append(order[:to], append([]string{name}, order[to:]...)...)
+
+	prev := s.order
+	s.setOrder(order)
+	s.record(orderEdit(trf(msgMoveSprite, name), prev, order))
+}
+
+// resetOrder drops the custom display order.
+func (s *UIState) resetOrder() {
+	if s.order == nil {
+		s.notify(msgAlreadyInOrder)
+		return
+	}
+	prev := s.order
+	s.setOrder(nil)
+	s.record(orderEdit(tr(msgResetOrder), prev, nil))
+}
+
+// sortRightToLeft sorts names, given in natural order, by where their sprites
+// sit on the sheet: top row first, and right to left within a row. Sprites
+// at the same position keep their natural order.
+func sortRightToLeft(names []string, sprites map[string]resources.Rectangle) {
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := sprites[names[i]], sprites[names[j]]
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.X > b.X
+	})
+}
+
+// toggleRightToLeft switches between reading the sheet's frames left to
+// right and right to left. Reading right to left orders the sprites by
+// position from the right and fills the grid's rows from the right, so the
+// grid, the animation preview and exports in display order follow the
+// sheet. The setting is saved with the sheet.
+func (s *UIState) toggleRightToLeft() {
+	s.rightToLeft = !s.rightToLeft
+	s.dirty = true
+	if s.sheet != nil {
+		s.updateSpriteNames()
+		s.anim.reset(int32(len(s.spriteNames)))
+	}
+	if s.rightToLeft {
+		s.notify(msgRightToLeftOn)
+	} else {
+		s.notify(msgRightToLeftOff)
+	}
+}
+
+// updateDrag picks up, moves and drops thumbnails. hovered is the index of
+// the thumbnail under the cursor, or -1.
+func (s *UIState) updateDrag(cfg Config, hovered int) {
+	mouse := mousePosition()
+	if rl.IsMouseButtonPressed(rl.MouseLeftButton) && hovered >= 0 {
+		s.drag = spriteDrag{pressed: true, from: hovered, start: mouse}
+	}
+	if !s.drag.pressed {
+		return
+	}
+	if s.drag.from >= len(s.spriteNames) {
+		s.drag = spriteDrag{}
+		return
+	}
+
+	if !s.drag.active && rl.Vector2Distance(mouse, s.drag.start) >= dragThreshold {
+		s.drag.active = true
+	}
+
+	if rl.IsMouseButtonReleased(rl.MouseLeftButton) || !rl.IsMouseButtonDown(rl.MouseLeftButton) {
+		if s.drag.active && outsideWindow() {
+			s.dragOut(s.spriteNames[s.drag.from])
+		} else if s.drag.active && hovered >= 0 {
+			s.moveSprite(s.drag.from, hovered)
+		}
+		if s.drag.active {
+			s.snapScroll(cfg)
+		}
+		s.drag = spriteDrag{}
+		return
+	}
+	if !s.drag.active {
+		return
+	}
+
+	if hovered >= 0 && hovered != s.drag.from {
+		target := s.cellRect(cfg, hovered)
+		x := target.X - float32(cfg.padding)/2
+		if hovered > s.drag.from {
+			x = target.X + target.Width + float32(cfg.padding)/2
+		}
+		rl.DrawLineEx(rl.Vector2{X: x, Y: target.Y - 2}, rl.Vector2{X: x, Y: target.Y + target.Height + 2}, 2, s.selectionColor())
+	}
+
+	rect := s.sheet.Sprites[s.spriteNames[s.drag.from]]
+	source := rl.Rectangle{X: float32(rect.X), Y: float32(rect.Y), Width: float32(rect.Width), Height: float32(rect.Height)}
+	w, h := cfg.cellSize()
+	dest := rl.Rectangle{X: mouse.X - float32(w)/2, Y: mouse.Y - float32(h)/2, Width: float32(w), Height: float32(h)}
+	rl.DrawTexturePro(s.drawTexture(), s.texSource(source), dest, rl.Vector2{}, 0, rl.ColorAlpha(rl.White, 0.7))
+}
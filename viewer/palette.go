@@ -0,0 +1,156 @@
+package viewer
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// paletteRows is how many matching actions the command palette lists.
+const paletteRows = 10
+
+// commandPalette is the state of the open command palette.
+type commandPalette struct {
+	query    string
+	selected int
+}
+
+// togglePalette opens the command palette, or closes it if it is open.
+func (s *UIState) togglePalette() {
+	if s.palette != nil {
+		s.palette = nil
+		return
+	}
+	s.palette = &commandPalette{}
+	s.widgets.blur()
+}
+
+// fuzzyScore reports whether every character of query appears in name in
+// order, ignoring case, and scores the match. Consecutive characters and
+// characters at the start of a word score higher.
+func fuzzyScore(query, name string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	score, qi := 0, 0
+	prevMatch := false
+	var prev rune = ' '
+	for _, r := range strings.ToLower(name) {
+		if qi < len(q) && r == q[qi] {
+			score++
+			if prevMatch {
+				score += 2
+			}
+			if !unicode.IsLetter(prev) {
+				score += 3
+			}
+			qi++
+			prevMatch = true
+		} else {
+			prevMatch = false
+		}
+		prev = r
+	}
+	return score, qi == len(q)
+}
+
+// matches returns the actions matching the query, best first. Actions with
+// the same score keep their registry order.
+func (p *commandPalette) matches() []action {
+	type scored struct {
+		action action
+		score  int
+	}
+	var found []scored
+	for _, a := range actions {
+		if score, ok := fuzzyScore(p.query, tr(a.name)); ok {
+			found = append(found, scored{a, score})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+
+	list := make([]action, len(found))
+	for i, f := range found {
+		list[i] = f.action
+	}
+	return list
+}
+
+// handlePaletteInput edits the query and runs the selected action on Enter.
+// It takes all keyboard input while the palette is open.
+func (s *UIState) handlePaletteInput() {
+	p := s.palette
+	for ch := rl.GetCharPressed(); ch > 0; ch = rl.GetCharPressed() {
+		if ch >= ' ' && len(p.query) < 40 {
+			p.query += string(ch)
+			p.selected = 0
+		}
+	}
+	if rl.IsKeyPressed(rl.KeyBackspace) && len(p.query) > 0 {
+		p.query = p.query[:len(p.query)-1]
+		p.selected = 0
+	}
+
+	list := p.matches()
+	if rl.IsKeyPressed(rl.KeyDown) && p.selected < min(len(list), paletteRows)-1 {
+		p.selected++
+	}
+	if rl.IsKeyPressed(rl.KeyUp) && p.selected > 0 {
+		p.selected--
+	}
+	if rl.IsKeyPressed(rl.KeyEscape) || (rl.IsKeyPressed(rl.KeyP) && (rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl))) {
+		s.palette = nil
+		return
+	}
+	if (rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter)) && p.selected < len(list) {
+		s.palette = nil
+		list[p.selected].run(s)
+	}
+}
+
+// renderPalette draws the command palette below the header.
+func (s *UIState) renderPalette(cfg Config) rl.Rectangle {
+	p := s.palette
+	list := p.matches()
+	if len(list) > paletteRows {
+		list = list[:paletteRows]
+	}
+
+	panel := rl.Rectangle{
+		X:      float32(cfg.width-360) / 2,
+		Y:      float32(cfg.headerHeight + 10),
+		Width:  360,
+		Height: float32(40 + max(len(list), 1)*20 + 5),
+	}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.97))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+
+	input := rl.Rectangle{X: panel.X + 5, Y: panel.Y + 5, Width: panel.Width - 10, Height: 25}
+	rl.DrawRectangleRec(input, rl.White)
+	rl.DrawRectangleLinesEx(input, 1, rl.Gray)
+	query := p.query
+	if int(rl.GetTime()*2)%2 == 0 {
+		query += "_"
+	}
+	if p.query == "" {
+		drawText(tr(msgTypeCommand), int32(input.X)+6, int32(input.Y)+7, 10, s.theme.MutedText)
+	} else {
+		drawText(query, int32(input.X)+6, int32(input.Y)+7, 10, rl.Black)
+	}
+
+	if len(list) == 0 {
+		drawText(tr(msgNoMatchingCommands), int32(panel.X)+10, int32(panel.Y)+45, 10, s.theme.MutedText)
+		return panel
+	}
+	for i, a := range list {
+		row := rl.Rectangle{X: panel.X + 5, Y: panel.Y + 35 + float32(i)*20, Width: panel.Width - 10, Height: 20}
+		if i == p.selected {
+			rl.DrawRectangleRec(row, rl.ColorAlpha(s.selectionColor(), 0.3))
+		}
+		drawText(tr(a.name), int32(row.X)+6, int32(row.Y)+5, 10, s.theme.Text)
+		if key := a.shortcut(); key != "" {
+			drawText(key, int32(row.X+row.Width)-6-measureText(key, 10), int32(row.Y)+5, 10, s.theme.MutedText)
+		}
+	}
+	return panel
+}
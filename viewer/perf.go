@@ -0,0 +1,73 @@
+package viewer
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// frameStats are the timings and counters of one frame, for the performance
+// overlay. They are reset at the start of every Update.
+type frameStats struct {
+	// update is the time spent in Update, sorting the time spent ordering
+	// sprite names and sprites the time spent drawing the grid.
+	update, sorting, sprites time.Duration
+	// drawn and culled count the thumbnails drawn and those skipped for
+	// being out of view.
+	drawn, culled int
+	// uploads counts the textures uploaded to the GPU.
+	uploads int
+}
+
+// loadTimes are how long the phases of the last load of the sheet took. The
+// file is read, decoded and uploaded to the GPU in one call into the resource
+// manager, so those are timed together as load. A reslice only cuts and
+// orders the sprites again, so it leaves load and texture as they were.
+type loadTimes struct {
+	// load reads, decodes and uploads the sheet, texture reduces it for the
+	// preview and builds its mipmaps, slicing cuts it into sprites and
+	// names puts their names in order.
+	load, texture, slicing, names time.Duration
+}
+
+// logLoadTimes writes the phases of the last load to the log, if asked to
+// with Options.Verbose.
+func (s *UIState) logLoadTimes() {
+	if !s.verbose {
+		return
+	}
+	t := s.loadTimes
+	log.Printf("%s: load %v, texture %v, slicing %v, names %v (%d sprites)",
+		filepath.Base(s.currentFile), t.load, t.texture, t.slicing, t.names, len(s.spriteNames))
+}
+
+// renderPerf draws the performance overlay in the top right corner of the
+// grid. It only reports, so it doesn't take the mouse from what is under it.
+func (s *UIState) renderPerf(cfg Config) {
+	p := s.perf
+	ms := func(d time.Duration) string {
+		return fmt.Sprintf("%.2f ms", float64(d.Microseconds())/1000)
+	}
+	lines := []string{
+		trf(msgPerfFPS, rl.GetFPS(), rl.GetFrameTime()*1000),
+		trf(msgPerfThumbnails, p.drawn, p.culled),
+		trf(msgPerfSprites, ms(p.sprites)),
+		trf(msgPerfUpdate, ms(p.update)),
+		trf(msgPerfSorting, ms(p.sorting)),
+		trf(msgPerfTextures, p.uploads, formatBytes(s.textureMemory())),
+		trf(msgPerfLoad, ms(s.loadTimes.load), ms(s.loadTimes.texture)),
+		trf(msgPerfSlicing, ms(s.loadTimes.slicing), ms(s.loadTimes.names)),
+	}
+	width := int32(0)
+	for _, line := range lines {
+		width = max(width, measureText(line, 10))
+	}
+	x, y := cfg.width-width-40, cfg.headerHeight+10
+	rl.DrawRectangle(x, y, width+20, int32(len(lines))*14+12, rl.ColorAlpha(s.theme.Panel, 0.85))
+	for i, line := range lines {
+		drawText(line, x+10, y+7+int32(i)*14, 10, s.theme.Text)
+	}
+}
@@ -0,0 +1,129 @@
+package viewer
+
+import (
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Pinned sprites are shown in boxes pinSize pixels square, and at most
+// maxPins of them fit along the bottom of the grid.
+const (
+	pinSize = 96
+	maxPins = 6
+)
+
+// pinnedSprites returns the pinned sprites the current sheet still has, in
+// the order they were pinned.
+func (s *UIState) pinnedSprites() []string {
+	if s.sheet == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range s.pinned {
+		if _, ok := s.sheet.Sprites[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// togglePins pins the selected sprites, or unpins them if they are all
+// pinned already.
+func (s *UIState) togglePins() {
+	names := s.selectedNames()
+	if len(names) == 0 {
+		s.notify(msgSelectSpriteToPin)
+		return
+	}
+	var unpinned []string
+	for _, name := range names {
+		if !slices.Contains(s.pinned, name) {
+			unpinned = append(unpinned, name)
+		}
+	}
+	prev := slices.Clone(s.pinned)
+	if len(unpinned) == 0 {
+		s.pinned = slices.DeleteFunc(s.pinned, func(name string) bool { return s.selected[name] })
+		s.record(pinEdit(trf(msgUnpinnedSprites, len(names)), prev, s.pinned))
+		s.notify(msgUnpinnedSprites, len(names))
+		return
+	}
+	if len(s.pinned)+len(unpinned) > maxPins {
+		s.notify(msgTooManyPins, maxPins)
+		return
+	}
+	s.pinned = append(s.pinned, unpinned...)
+	s.record(pinEdit(trf(msgPinnedSprites, len(unpinned)), prev, s.pinned))
+	s.notify(msgPinnedSprites, len(unpinned))
+}
+
+// unpin removes name from the pinned sprites.
+func (s *UIState) unpin(name string) {
+	prev := slices.Clone(s.pinned)
+	s.pinned = slices.DeleteFunc(s.pinned, func(pinned string) bool { return pinned == name })
+	s.record(pinEdit(trf(msgUnpinnedSprites, 1), prev, s.pinned))
+}
+
+// pinEdit records a change of the pinned sprites. It keeps copies of from
+// and to, as the pinned sprites are changed in place.
+func pinEdit(desc string, from, to []string) edit {
+	from, to = slices.Clone(from), slices.Clone(to)
+	return edit{
+		desc: desc,
+		undo: func(s *UIState) { s.pinned = slices.Clone(from) },
+		redo: func(s *UIState) { s.pinned = slices.Clone(to) },
+	}
+}
+
+// renderPins draws the pinned sprites docked in the bottom right corner of
+// the grid, where they stay while it scrolls, and returns the area they
+// take. Each is scaled to fit its box, in whole steps when enlarged, so a
+// reference frame can be compared against sprites far down the sheet.
+// Clicking one selects it and scrolls the grid to it.
+func (s *UIState) renderPins(cfg Config) rl.Rectangle {
+	names := s.pinnedSprites()
+	if len(names) == 0 {
+		return rl.Rectangle{}
+	}
+	width := float32(len(names)*(pinSize+10) + 10)
+	height := float32(pinSize + 50)
+	panel := rl.Rectangle{
+		X:      float32(cfg.width) - width - 30,
+		Y:      float32(cfg.startY+cfg.viewportHeight) - height - 10,
+		Width:  width,
+		Height: height,
+	}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(tr(msgPins), int32(panel.X)+10, int32(panel.Y)+6, 10, s.theme.Text)
+
+	mouse := mousePosition()
+	for i, name := range names {
+		box := rl.Rectangle{X: panel.X + 10 + float32(i*(pinSize+10)), Y: panel.Y + 22, Width: pinSize, Height: pinSize}
+		rl.DrawRectangleLinesEx(box, 1, s.theme.CellBorder)
+		rect := s.sheet.Sprites[name]
+		scale := min(box.Width/float32(rect.Width), box.Height/float32(rect.Height))
+		if scale >= 1 {
+			scale = float32(int(scale))
+		}
+		w, h := float32(rect.Width)*scale, float32(rect.Height)*scale
+		dest := rl.Rectangle{X: box.X + (box.Width-w)/2, Y: box.Y + (box.Height-h)/2, Width: w, Height: h}
+		rl.DrawTexturePro(s.drawTexture(), s.texSource(spriteSource(rect)), dest, rl.Vector2{}, 0, rl.White)
+		if s.selected[name] {
+			rl.DrawRectangleLinesEx(box, 2, s.selectionColor())
+		}
+		if lines := wrapText(name, 10, pinSize); len(lines) > 0 {
+			drawText(lines[0], int32(box.X), int32(box.Y+box.Height)+4, 10, s.theme.Text)
+		}
+
+		unpin := rl.Rectangle{X: box.X + box.Width - 17, Y: box.Y + 1, Width: 16, Height: 16}
+		if drawButton(unpin, "x") {
+			s.unpin(name)
+		} else if rl.IsMouseButtonPressed(rl.MouseLeftButton) && rl.CheckCollisionPointRec(mouse, box) {
+			s.selected = map[string]bool{name: true}
+			s.reveal = name
+		}
+	}
+	return panel
+}
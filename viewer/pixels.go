@@ -0,0 +1,290 @@
+package viewer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"path/filepath"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// sheetPixels is a CPU-side copy of an image's pixels, used by analysis
+// features that need to look at sprite contents.
+type sheetPixels struct {
+	width  int32
+	height int32
+	pix    []color.RGBA
+	// alpha is the alpha at or below which a pixel counts as transparent
+	// when looking for empty cells and content bounds.
+	alpha uint8
+}
+
+// loadPixels reads the image at path into memory.
+func loadPixels(path string) (*sheetPixels, error) {
+	img := rl.LoadImage(path)
+	if !rl.IsImageValid(img) {
+		return nil, fmt.Errorf(tr(msgCouldNotRead), filepath.Base(path))
+	}
+	defer rl.UnloadImage(img)
+	return imagePixels(img, 0), nil
+}
+
+// imagePixels copies the pixels of an image already in memory, with alpha as
+// the alpha at or below which a pixel counts as transparent.
+func imagePixels(img *rl.Image, alpha uint8) *sheetPixels {
+	colors := rl.LoadImageColors(img)
+	defer rl.UnloadImageColors(colors)
+
+	p := &sheetPixels{width: img.Width, height: img.Height, pix: make([]color.RGBA, len(colors)), alpha: alpha}
+	copy(p.pix, colors)
+	return p
+}
+
+// at returns the pixel at x, y.
+func (p *sheetPixels) at(x, y int32) color.RGBA {
+	return p.pix[y*p.width+x]
+}
+
+// contains reports whether rect lies entirely inside the image.
+func (p *sheetPixels) contains(rect resources.Rectangle) bool {
+	return rect.X >= 0 && rect.Y >= 0 && rect.X+rect.Width <= p.width && rect.Y+rect.Height <= p.height
+}
+
+// contentSize returns the size of the non-transparent area of the named
+// sprite, caching it until the next reload. ok is false if the sheet's pixels
+// can't be read.
+func (s *UIState) contentSize(name string) (w, h int32, ok bool) {
+	if b, ok := s.contentSizes[name]; ok {
+		return b.W, b.H, true
+	}
+	p, err := s.sheetPixels()
+	rect := s.sheet.Sprites[name]
+	if err != nil || !p.contains(rect) {
+		return 0, 0, false
+	}
+	if s.contentSizes == nil {
+		s.contentSizes = make(map[string]atlasRect)
+	}
+	b := p.contentBounds(rect)
+	s.contentSizes[name] = b
+	return b.W, b.H, true
+}
+
+// sheetPixels returns the pixels of the loaded sheet, reading the file the
+// first time they are needed after a reload. They use the current alpha
+// threshold.
+func (s *UIState) sheetPixels() (*sheetPixels, error) {
+	if s.pixels == nil {
+		p, err := loadPixels(s.currentFile)
+		if err != nil {
+			return nil, err
+		}
+		s.pixels = p
+	}
+	s.pixels.alpha = s.alphaCutoff()
+	return s.pixels, nil
+}
+
+// alphaCutoff returns the alpha threshold as a pixel alpha.
+func (s *UIState) alphaCutoff() uint8 {
+	return uint8(max(min(s.alphaThreshold, 255), 0))
+}
+
+// setAlphaThreshold changes the alpha at or below which pixels count as
+// transparent, and brings everything measured with it up to date.
+func (s *UIState) setAlphaThreshold(alpha int32) {
+	s.alphaThreshold = alpha
+	s.contentSizes = nil
+	if s.sheet != nil {
+		s.refreshReport(false)
+		s.refreshHistogram(false)
+	}
+}
+
+// alphaThresholdEdit records a change of the alpha threshold.
+func alphaThresholdEdit(from, to int32) edit {
+	apply := func(alpha int32) func(s *UIState) {
+		return func(s *UIState) { s.setAlphaThreshold(alpha) }
+	}
+	return edit{
+		desc: fmt.Sprintf("%s %d → %d", tr(msgAlphaThreshold), from, to),
+		undo: apply(from),
+		redo: apply(to),
+	}
+}
+
+// transparent reports whether c counts as transparent.
+func (p *sheetPixels) transparent(c color.RGBA) bool {
+	return c.A <= p.alpha
+}
+
+// isEmpty reports whether every pixel of rect is transparent.
+func (p *sheetPixels) isEmpty(rect resources.Rectangle) bool {
+	for y := rect.Y; y < rect.Y+rect.Height; y++ {
+		for x := rect.X; x < rect.X+rect.Width; x++ {
+			if !p.transparent(p.at(x, y)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// contentBounds returns the non-transparent area of rect, relative to it. A
+// fully transparent rect has an empty result, as does one reaching outside
+// the image.
+func (p *sheetPixels) contentBounds(rect resources.Rectangle) atlasRect {
+	if !p.contains(rect) {
+		return atlasRect{}
+	}
+	minX, minY, maxX, maxY := rect.Width, rect.Height, int32(-1), int32(-1)
+	for y := int32(0); y < rect.Height; y++ {
+		for x := int32(0); x < rect.Width; x++ {
+			if p.transparent(p.at(rect.X+x, rect.Y+y)) {
+				continue
+			}
+			minX, minY = min(minX, x), min(minY, y)
+			maxX, maxY = max(maxX, x), max(maxY, y)
+		}
+	}
+	if maxX < 0 {
+		return atlasRect{}
+	}
+	return atlasRect{X: minX, Y: minY, W: maxX - minX + 1, H: maxY - minY + 1}
+}
+
+// cellHash returns a hash of the pixels in rect. Cells with the same size and
+// contents hash to the same value.
+func (p *sheetPixels) cellHash(rect resources.Rectangle) uint64 {
+	return p.hashCell(rect, false)
+}
+
+// flippedHash returns the hash rect's pixels would have if mirrored
+// horizontally, so a cell's mirror image can be looked up by cellHash.
+func (p *sheetPixels) flippedHash(rect resources.Rectangle) uint64 {
+	return p.hashCell(rect, true)
+}
+
+// hashCell hashes the pixels in rect row by row, reading each row right to
+// left when flipped is set.
+func (p *sheetPixels) hashCell(rect resources.Rectangle, flipped bool) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%dx%d:", rect.Width, rect.Height)
+	row := make([]byte, 0, rect.Width*4)
+	for y := rect.Y; y < rect.Y+rect.Height; y++ {
+		row = row[:0]
+		for i := int32(0); i < rect.Width; i++ {
+			x := rect.X + i
+			if flipped {
+				x = rect.X + rect.Width - 1 - i
+			}
+			c := p.at(x, y)
+			row = append(row, c.R, c.G, c.B, c.A)
+		}
+		h.Write(row)
+	}
+	return h.Sum64()
+}
+
+// emptyCells returns the names of the sprites whose cells are transparent
+// throughout, in the order given.
+func (p *sheetPixels) emptyCells(rects map[string]resources.Rectangle, names []string) []string {
+	var empty []string
+	for _, name := range names {
+		if rect := rects[name]; p.contains(rect) && p.isEmpty(rect) {
+			empty = append(empty, name)
+		}
+	}
+	return empty
+}
+
+// sameCell reports whether a and b have the same size and pixels.
+func (p *sheetPixels) sameCell(a, b resources.Rectangle) bool {
+	if a.Width != b.Width || a.Height != b.Height {
+		return false
+	}
+	for y := int32(0); y < a.Height; y++ {
+		for x := int32(0); x < a.Width; x++ {
+			if p.at(a.X+x, a.Y+y) != p.at(b.X+x, b.Y+y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// mirroredCell reports whether b is the horizontal mirror image of a.
+func (p *sheetPixels) mirroredCell(a, b resources.Rectangle) bool {
+	if a.Width != b.Width || a.Height != b.Height {
+		return false
+	}
+	for y := int32(0); y < a.Height; y++ {
+		for x := int32(0); x < a.Width; x++ {
+			if p.at(a.X+x, a.Y+y) != p.at(b.X+b.Width-1-x, b.Y+y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// mirrorPairs returns pairs of sprites where the second is the horizontal
+// mirror of the first, in the order given. Each cell's flipped hash is
+// looked up among the cells before it and candidates are compared pixel by
+// pixel. Empty cells are skipped, and so are identical pairs of symmetric
+// sprites, which duplicateGroups already reports.
+func (p *sheetPixels) mirrorPairs(rects map[string]resources.Rectangle, names []string) [][2]string {
+	buckets := make(map[uint64][]string)
+	var pairs [][2]string
+	for _, name := range names {
+		rect := rects[name]
+		if !p.contains(rect) || p.isEmpty(rect) {
+			continue
+		}
+		for _, other := range buckets[p.flippedHash(rect)] {
+			if p.mirroredCell(rects[other], rect) && !p.sameCell(rects[other], rect) {
+				pairs = append(pairs, [2]string{other, name})
+			}
+		}
+		h := p.cellHash(rect)
+		buckets[h] = append(buckets[h], name)
+	}
+	return pairs
+}
+
+// duplicateGroups returns groups of two or more sprites with identical pixels.
+// Sprites keep the order given, both within a group and across groups. Empty
+// cells are not considered duplicates of each other.
+func (p *sheetPixels) duplicateGroups(rects map[string]resources.Rectangle, names []string) [][]string {
+	buckets := make(map[uint64][]int)
+	var groups [][]string
+	for _, name := range names {
+		rect := rects[name]
+		if !p.contains(rect) || p.isEmpty(rect) {
+			continue
+		}
+		h := p.cellHash(rect)
+		found := false
+		for _, i := range buckets[h] {
+			if p.sameCell(rects[groups[i][0]], rect) {
+				groups[i] = append(groups[i], name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			buckets[h] = append(buckets[h], len(groups))
+			groups = append(groups, []string{name})
+		}
+	}
+
+	var dups [][]string
+	for _, g := range groups {
+		if len(g) > 1 {
+			dups = append(dups, g)
+		}
+	}
+	return dups
+}
@@ -0,0 +1,122 @@
+package viewer
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// slicingPreset is a snapshot of the slicing settings, kept as preset A or B
+// to flip between two candidate slicings of a sheet.
+type slicingPreset struct {
+	Margin   int32 `json:"margin"`
+	GridSize int32 `json:"gridSize"`
+	// Columns and Rows are set when the preset slices by count.
+	Columns int32 `json:"columns,omitempty"`
+	Rows    int32 `json:"rows,omitempty"`
+}
+
+// presetNames labels the two presets.
+var presetNames = [2]string{"A", "B"}
+
+// currentPreset returns the current slicing settings as a preset.
+func (s *UIState) currentPreset() slicingPreset {
+	p := slicingPreset{Margin: s.margin, GridSize: s.gridSize}
+	if s.sliceByCount {
+		p.Columns, p.Rows = s.columns, s.rows
+	}
+	return p
+}
+
+// summary describes the slicing the preset stands for.
+func (p slicingPreset) summary() string {
+	if p.Columns > 0 && p.Rows > 0 {
+		return trf(msgPresetCount, p.Columns, p.Rows, p.Margin)
+	}
+	return trf(msgPresetGrid, p.GridSize, p.Margin)
+}
+
+// storePreset snapshots the current slicing settings as preset i. Presets are
+// saved with the sheet's metadata.
+func (s *UIState) storePreset(i int) {
+	p := s.currentPreset()
+	s.presets[i] = &p
+	s.dirty = true
+	s.notify(msgPresetStored, presetNames[i], p.summary())
+}
+
+// activePreset returns which preset the current settings match, or -1 if
+// neither does, for example after a field was changed by hand.
+func (s *UIState) activePreset() int {
+	current := s.currentPreset()
+	for i, p := range s.presets {
+		if p != nil && *p == current {
+			return i
+		}
+	}
+	return -1
+}
+
+// togglePreset switches to the other preset, or to A if the settings match
+// neither. The sheet is resliced right away rather than after the usual
+// debounce, so flipping back and forth compares the two instantly.
+func (s *UIState) togglePreset() {
+	if s.presets[0] == nil || s.presets[1] == nil {
+		s.notify(msgStorePresetsFirst)
+		return
+	}
+	if s.slicingLocked {
+		s.notify(msgSlicingLocked)
+		return
+	}
+	i := 0
+	if s.activePreset() == 0 {
+		i = 1
+	}
+	p := s.presets[i]
+	s.margin, s.gridSize = p.Margin, p.GridSize
+	s.sliceByCount = p.Columns > 0 && p.Rows > 0
+	if s.sliceByCount {
+		s.columns, s.rows = p.Columns, p.Rows
+	}
+	s.dirty = true
+	if s.sheet != nil {
+		s.reslice()
+	}
+	s.notify(msgPresetActive, presetNames[i], p.summary())
+}
+
+// toggleSlicingLock locks the slicing settings against edits, or unlocks
+// them. The lock is saved with the sheet's metadata.
+func (s *UIState) toggleSlicingLock() {
+	s.slicingLocked = !s.slicingLocked
+	s.dirty = true
+	if s.slicingLocked {
+		s.notify(msgSlicingLockedOn)
+	} else {
+		s.notify(msgSlicingUnlocked)
+	}
+}
+
+// presetStatus returns the status bar text naming the active preset, once
+// both presets are stored.
+func (s *UIState) presetStatus() string {
+	if s.presets[0] == nil || s.presets[1] == nil {
+		return ""
+	}
+	if i := s.activePreset(); i >= 0 {
+		return trf(msgPresetLabel, presetNames[i])
+	}
+	return ""
+}
+
+// drawPresetButton draws the button storing preset i at r, with a label
+// above it that is highlighted while the preset is active.
+func (s *UIState) drawPresetButton(r rl.Rectangle, i int) {
+	col := rl.Black
+	label := trf(msgPresetLabel, presetNames[i])
+	if s.presets[i] != nil && s.activePreset() == i {
+		col = s.selectionColor()
+		label = trf(msgPresetActiveLabel, presetNames[i])
+	}
+	drawText(label, int32(r.X), int32(r.Y-15), 10, col)
+	if drawButton(r, trf(msgStorePreset, presetNames[i])) {
+		s.storePreset(i)
+	}
+}
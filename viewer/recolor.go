@@ -0,0 +1,280 @@
+package viewer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// colorSwap replaces one color of a sheet by another. A color written
+// without alpha matches, or keeps, whatever alpha the pixel has.
+type colorSwap struct {
+	from, to           color.RGBA
+	fromAlpha, toAlpha bool
+}
+
+// parseHexColor reads a color written as RRGGBB or RRGGBBAA, with or without
+// a leading "#". hasAlpha reports which of the two it was.
+func parseHexColor(text string) (c color.RGBA, hasAlpha bool, err error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(text), "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.RGBA{}, false, fmt.Errorf(tr(msgBadColor), text)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false, fmt.Errorf(tr(msgBadColor), text)
+	}
+	if len(hex) == 6 {
+		return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, false, nil
+	}
+	return color.RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true, nil
+}
+
+// newColorSwap builds the swap from one color to another, both written as
+// parseHexColor reads them.
+func newColorSwap(from, to string) (colorSwap, error) {
+	var sw colorSwap
+	var err error
+	if sw.from, sw.fromAlpha, err = parseHexColor(from); err != nil {
+		return colorSwap{}, err
+	}
+	if sw.to, sw.toAlpha, err = parseHexColor(to); err != nil {
+		return colorSwap{}, err
+	}
+	return sw, nil
+}
+
+// parsePalette reads a palette map. A JSON file is an object from old colors
+// to new ones:
+//
+//	{"#3c2a1e": "#1e2a3c", "#ffd700": "#c0c0c0"}
+//
+// Any other file has one swap per line, the old color first, separated by
+// spaces, "->" or "=". Blank lines and lines starting with "//" are skipped.
+func parsePalette(path string, data []byte) ([]colorSwap, error) {
+	var swaps []colorSwap
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var m map[string]string
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		for from, to := range m {
+			sw, err := newColorSwap(from, to)
+			if err != nil {
+				return nil, err
+			}
+			swaps = append(swaps, sw)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "//") {
+				continue
+			}
+			text = strings.NewReplacer("->", " ", "=", " ").Replace(text)
+			fields := strings.Fields(text)
+			if len(fields) != 2 {
+				return nil, fmt.Errorf(tr(msgBadPaletteLine), line, scanner.Text())
+			}
+			sw, err := newColorSwap(fields[0], fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", line, err)
+			}
+			swaps = append(swaps, sw)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(swaps) == 0 {
+		return nil, fmt.Errorf(tr(msgEmptyPalette), filepath.Base(path))
+	}
+	return swaps, nil
+}
+
+// recolor returns a copy of the pixels with the swaps applied, and how many
+// pixels changed. A swap whose old color has alpha wins over one without for
+// the pixels both match.
+func (p *sheetPixels) recolor(swaps []colorSwap) ([]color.RGBA, int) {
+	exact := make(map[color.RGBA]colorSwap)
+	opaque := make(map[color.RGBA]colorSwap)
+	for _, sw := range swaps {
+		if sw.fromAlpha {
+			exact[sw.from] = sw
+		} else {
+			opaque[sw.from] = sw
+		}
+	}
+
+	out := make([]color.RGBA, len(p.pix))
+	changed := 0
+	for i, c := range p.pix {
+		sw, ok := exact[c]
+		if !ok {
+			sw, ok = opaque[color.RGBA{R: c.R, G: c.G, B: c.B, A: 255}]
+		}
+		if !ok || c.A == 0 {
+			out[i] = c
+			continue
+		}
+		to := sw.to
+		if !sw.toAlpha {
+			to.A = c.A
+		}
+		if to != c {
+			changed++
+		}
+		out[i] = to
+	}
+	return out, changed
+}
+
+// recolorPreview shows the sheet with a palette map applied. The recolored
+// copy lives in its own texture, so the sheet itself stays untouched and
+// exports keep writing the original colors.
+type recolorPreview struct {
+	path    string
+	swaps   int
+	changed int
+	tex     rl.Texture2D
+	// before shows the original colors again without dropping the map.
+	before bool
+}
+
+func (r *recolorPreview) close() {
+	if r.tex.ID != 0 {
+		rl.UnloadTexture(r.tex)
+	}
+}
+
+// texture returns the recolored texture, or an empty one if there is none.
+func (r *recolorPreview) texture() rl.Texture2D {
+	if r == nil {
+		return rl.Texture2D{}
+	}
+	return r.tex
+}
+
+// loadPalette recolors the sheet with the palette map at path, replacing the
+// current map if there is one.
+func (s *UIState) loadPalette(path string) {
+	if s.sheet == nil {
+		s.notify(msgRecolorNeedsSheet)
+		return
+	}
+	r, err := s.buildRecolor(path)
+	if err != nil {
+		s.notify(msgRecolorFailed, err)
+		return
+	}
+	s.closeRecolor()
+	s.recolor = r
+	s.notify(msgRecolored, r.changed, r.swaps)
+}
+
+// buildRecolor reads the palette map at path and uploads the recolored sheet,
+// gamma corrected like the sheet if that is on.
+func (s *UIState) buildRecolor(path string) (*recolorPreview, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	swaps, err := parsePalette(path, data)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.sheetPixels()
+	if err != nil {
+		return nil, err
+	}
+
+	pix, changed := p.recolor(swaps)
+	r := &recolorPreview{path: path, swaps: len(swaps), changed: changed}
+	r.tex = s.uploadPixels(s.gammaCorrected(pix), p.width, p.height)
+	return r, nil
+}
+
+// refreshRecolor recolors the sheet again after a reload. The palette map is
+// read again too, so edits to it show up on the next reload.
+func (s *UIState) refreshRecolor() {
+	if s.recolor == nil {
+		return
+	}
+	r, err := s.buildRecolor(s.recolor.path)
+	if err != nil {
+		s.notify(msgRecolorFailed, err)
+		s.closeRecolor()
+		return
+	}
+	r.before = s.recolor.before
+	s.recolor.close()
+	s.recolor = r
+}
+
+// toggleRecolorBefore switches between the recolored and the original sheet.
+func (s *UIState) toggleRecolorBefore() {
+	if s.recolor == nil {
+		s.notify(msgNoRecolor)
+		return
+	}
+	s.recolor.before = !s.recolor.before
+}
+
+// closeRecolor drops the palette map and shows the original sheet.
+func (s *UIState) closeRecolor() {
+	if s.recolor != nil {
+		s.recolor.close()
+		s.recolor = nil
+	}
+}
+
+// choosePalette asks for a palette map and recolors the sheet with it.
+func (s *UIState) choosePalette() {
+	if s.sheet == nil {
+		s.notify(msgRecolorNeedsSheet)
+		return
+	}
+	if path := openTextFileDialog(); path != "" {
+		s.loadPalette(path)
+	}
+}
+
+// renderRecolorBar draws the palette map summary under the header, below the
+// comparison bar if one is shown.
+func (s *UIState) renderRecolorBar(cfg Config) rl.Rectangle {
+	y := float32(cfg.headerHeight + 1)
+	if s.diff != nil {
+		y += 25
+	}
+	rl.DrawRectangle(0, int32(y), cfg.width, 24, rl.ColorAlpha(s.theme.Panel, 0.95))
+	state := tr(msgRecolorAfter)
+	if s.recolor.before {
+		state = tr(msgRecolorBefore)
+	}
+	text := trf(msgRecolorSummary, filepath.Base(s.recolor.path), s.recolor.changed, s.recolor.swaps, state)
+	drawText(text, 10, int32(y)+7, 10, s.theme.Text)
+
+	closeWidth := buttonWidth(tr(msgClose), 80)
+	closeX := float32(cfg.width-10) - closeWidth
+	toggle := tr(msgShowOriginal)
+	if s.recolor.before {
+		toggle = tr(msgShowRecolored)
+	}
+	toggleWidth := buttonWidth(toggle, 80)
+	if drawButton(rl.Rectangle{X: closeX - 10 - toggleWidth, Y: y + 2, Width: toggleWidth, Height: 20}, toggle) {
+		s.toggleRecolorBefore()
+	}
+	if drawButton(rl.Rectangle{X: closeX, Y: y + 2, Width: closeWidth, Height: 20}, tr(msgClose)) {
+		s.closeRecolor()
+	}
+	return rl.Rectangle{Y: y, Width: float32(cfg.width), Height: 24}
+}
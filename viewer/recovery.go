@@ -0,0 +1,173 @@
+package viewer
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// recoveryInterval is how often, in seconds, unsaved metadata is written to
+// the recovery file.
+const recoveryInterval = 10
+
+// recoverySnapshot is the unsaved metadata of every open sheet, written to
+// Options.RecoveryFile while the viewer runs so a crash or forced close
+// doesn't lose it.
+type recoverySnapshot struct {
+	Sheets []recoveredSheet `json:"sheets"`
+}
+
+// recoveredSheet is the metadata of one sheet with unsaved changes.
+type recoveredSheet struct {
+	Path string    `json:"path"`
+	Meta sheetMeta `json:"meta"`
+}
+
+// loadRecovery reads the recovery file at path. It reports false if there is
+// none, or it holds no sheets.
+func loadRecovery(path string) (*recoverySnapshot, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var snap recoverySnapshot
+	if err := json.Unmarshal(data, &snap); err != nil || len(snap.Sheets) == 0 {
+		return nil, false
+	}
+	return &snap, true
+}
+
+// snapshot returns the metadata of the tabs with unsaved changes.
+func (v *Viewer) snapshot() recoverySnapshot {
+	var snap recoverySnapshot
+	for _, tab := range v.tabs {
+		if tab.dirty && tab.currentFile != "" {
+			snap.Sheets = append(snap.Sheets, recoveredSheet{Path: tab.currentFile, Meta: tab.currentMeta()})
+		}
+	}
+	return snap
+}
+
+// updateRecovery writes the unsaved metadata to the recovery file every
+// recoveryInterval seconds, and removes the file once nothing is unsaved. A
+// file left by an earlier session is kept until the user has chosen whether
+// to restore it.
+func (v *Viewer) updateRecovery() {
+	if v.recoveryFile == "" || v.recoveryPending() || rl.GetTime() < v.recoveryAt {
+		return
+	}
+	v.recoveryAt = rl.GetTime() + recoveryInterval
+
+	snap := v.snapshot()
+	if len(snap.Sheets) == 0 {
+		if v.recoveryOnDisk {
+			os.Remove(v.recoveryFile)
+			v.recoveryOnDisk, v.recoveryData = false, nil
+		}
+		return
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil || string(data) == string(v.recoveryData) {
+		return
+	}
+	// The snapshot is written next to the file and moved over it, so a
+	// crash while writing leaves the last snapshot whole.
+	tmp := v.recoveryFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err == nil {
+		err = os.Rename(tmp, v.recoveryFile)
+	}
+	if err != nil {
+		// It is tried again once there are other changes, rather than
+		// reporting the same failure every interval.
+		v.recoveryData = data
+		v.state.notify(msgRecoveryWriteFailed, err)
+		return
+	}
+	v.recoveryOnDisk, v.recoveryData = true, data
+}
+
+// recoveryPending reports whether a snapshot left by an earlier session is
+// still waiting for the user to restore or discard it, including one whose
+// prompt was dismissed to be asked about later.
+func (v *Viewer) recoveryPending() bool {
+	for _, tab := range v.tabs {
+		if tab.recovery != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// clearRecovery removes the recovery file when the viewer closes cleanly,
+// unless the user hasn't yet been asked about a snapshot left by an earlier
+// session.
+func (v *Viewer) clearRecovery() {
+	if v.recoveryFile == "" || v.recoveryPending() {
+		return
+	}
+	os.Remove(v.recoveryFile)
+}
+
+// restoreRecovery opens the sheets of a recovery snapshot, in the tab that
+// already has one open, the current tab if it is empty or a new tab, and
+// applies the unsaved metadata over what was saved with them. They stay
+// unsaved until the user saves them.
+func (v *Viewer) restoreRecovery(snap *recoverySnapshot) {
+	restored := 0
+	for _, sheet := range snap.Sheets {
+		open := slices.IndexFunc(v.tabs, func(tab *UIState) bool { return tab.currentFile == sheet.Path })
+		switch {
+		case open >= 0:
+			v.activate(open)
+		case v.state.currentFile == "":
+			v.state.openFile(sheet.Path)
+		default:
+			v.openTab(sheet.Path)
+		}
+		s := v.state
+		if s.sheet == nil || s.currentFile != sheet.Path {
+			continue
+		}
+		s.applyMeta(sheet.Meta)
+		s.reslice()
+		s.dirty = true
+		restored++
+	}
+	v.state.notify(msgRestoredSheets, restored, len(snap.Sheets))
+}
+
+// askRecoveryLater closes the recovery prompt without restoring or
+// discarding the snapshot, as when it is dismissed with Escape or a click
+// outside it. The file is kept, and the next session asks again.
+func (s *UIState) askRecoveryLater() {
+	s.recoveryLater = true
+	s.notify(msgRecoveryKept)
+}
+
+// renderRecoveryPrompt draws the dialog offering to restore the unsaved
+// changes a session that didn't close cleanly left behind.
+func (s *UIState) renderRecoveryPrompt(cfg Config) rl.Rectangle {
+	title := tr(msgRecoveryTitle)
+	text := trf(msgRecoveryFound, len(s.recovery.Sheets))
+	restoreWidth := buttonWidth(tr(msgRestore), 90)
+	discardWidth := buttonWidth(tr(msgDiscard), 90)
+	width := max(360, float32(measureText(title, 15)+20), float32(measureText(text, 10)+20), restoreWidth+discardWidth+30)
+
+	panel := rl.Rectangle{X: (float32(cfg.width) - width) / 2, Y: float32(cfg.height)/2 - 80, Width: width, Height: 110}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(title, int32(panel.X)+10, int32(panel.Y)+15, 15, s.theme.Text)
+	drawText(text, int32(panel.X)+10, int32(panel.Y)+40, 10, s.theme.MutedText)
+
+	buttonY := panel.Y + panel.Height - 35
+	if drawButton(rl.Rectangle{X: panel.X + 10, Y: buttonY, Width: restoreWidth, Height: 25}, tr(msgRestore)) {
+		s.tabRequest = &tabRequest{kind: tabRestore, recovery: s.recovery}
+		s.recovery = nil
+	}
+	if drawButton(rl.Rectangle{X: panel.X + panel.Width - 10 - discardWidth, Y: buttonY, Width: discardWidth, Height: 25}, tr(msgDiscard)) {
+		s.recovery = nil
+	}
+	return panel
+}
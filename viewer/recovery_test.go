@@ -0,0 +1,37 @@
+package viewer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecoveryDismissKeepsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.json")
+	data := `{"sheets": [{"path": "hero.png", "meta": {"margin": 1, "gridSize": 16}}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := &Viewer{state: initUI(), recoveryFile: path}
+	v.tabs = []*UIState{v.state}
+	v.state.recovery, v.recoveryOnDisk = loadRecovery(path)
+	if v.state.recovery == nil {
+		t.Fatal("recovery file not loaded")
+	}
+
+	// Escape or a click outside the prompt asks again next time.
+	v.state.askRecoveryLater()
+	v.updateRecovery()
+	v.clearRecovery()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("dismissing the prompt removed the recovery file: %v", err)
+	}
+
+	// Only discarding removes it.
+	v.state.recovery = nil
+	v.clearRecovery()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("discarding kept the recovery file: %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package viewer
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// renameRows is how many sprites the bulk rename dialog lists at once.
+const renameRows = 10
+
+// renamePrompt is the state of the bulk rename dialog.
+type renamePrompt struct {
+	// names are the sprites being renamed, in display order.
+	names []string
+	// number renumbers the sprites with pattern, counting up from start,
+	// instead of replacing find with replace in their names.
+	number        bool
+	find, replace string
+	pattern       string
+	start         int32
+	// offset is how far the preview is scrolled, in rows.
+	offset int
+}
+
+// renameRow is one sprite in the preview of a bulk rename: the name it is
+// shown with, the name it would get and, if that name can't be used, why.
+type renameRow struct {
+	from, to string
+	problem  string
+}
+
+// displayName returns the name the sprite is shown with, which is the name
+// slicing gave it unless it was renamed.
+func (s *UIState) displayName(name string) string {
+	if r, ok := s.renames[name]; ok {
+		return r
+	}
+	return name
+}
+
+// openRename opens the bulk rename dialog for the selected sprites, or for
+// every sprite in the grid, which the filter may limit, if none are selected.
+func (s *UIState) openRename() {
+	if s.sheet == nil || len(s.spriteNames) == 0 {
+		s.notify(msgNothingToRename)
+		return
+	}
+	names := s.selectedNames()
+	if len(names) == 0 {
+		names = slices.Clone(s.spriteNames)
+	}
+	s.rename = &renamePrompt{names: names, pattern: "sprite_{n}"}
+	s.widgets.blur()
+}
+
+// globPattern compiles a find pattern holding * or ? into an expression
+// matching whole names, with a group for the text each wildcard matches.
+func globPattern(find string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range find {
+		switch r {
+		case '*':
+			b.WriteString("(.*?)")
+		case '?':
+			b.WriteString("(.)")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// renamed returns the name the sprite at position i of the dialog, shown as
+// name, would get. A find pattern with wildcards has to match the whole name,
+// and each * in the replacement is replaced by the text the wildcards matched,
+// in order; without wildcards every occurrence of find is replaced.
+func (p *renamePrompt) renamed(i int, name string) string {
+	if p.number {
+		return strings.ReplaceAll(p.pattern, "{n}", strconv.Itoa(int(p.start)+i))
+	}
+	if p.find == "" {
+		return name
+	}
+	if !strings.ContainsAny(p.find, "*?") {
+		return strings.ReplaceAll(name, p.find, p.replace)
+	}
+	m := globPattern(p.find).FindStringSubmatch(name)
+	if m == nil {
+		return name
+	}
+	groups := m[1:]
+	var b strings.Builder
+	for _, r := range p.replace {
+		if r != '*' {
+			b.WriteRune(r)
+		} else if len(groups) > 0 {
+			b.WriteString(groups[0])
+			groups = groups[1:]
+		}
+	}
+	return b.String()
+}
+
+// validName reports whether name can be exported as a file name: it holds
+// no path separator and isn't a parent directory, so a sprite can't be
+// written outside the export folder.
+func validName(name string) bool {
+	return !strings.ContainsAny(name, `/\`) && name != ".."
+}
+
+// renamePreview works out the rename for every sprite of the dialog, and
+// counts the sprites whose name changes and those whose new name is empty,
+// not a file name or shared with another sprite of the sheet.
+func (s *UIState) renamePreview(p *renamePrompt) (rows []renameRow, renamed, conflicts int) {
+	renaming := make(map[string]bool, len(p.names))
+	for _, name := range p.names {
+		renaming[name] = true
+	}
+	taken := make(map[string]int, len(s.sheet.Sprites))
+	for name := range s.sheet.Sprites {
+		if !renaming[name] {
+			taken[s.displayName(name)]++
+		}
+	}
+
+	rows = make([]renameRow, len(p.names))
+	for i, name := range p.names {
+		from := s.displayName(name)
+		rows[i] = renameRow{from: from, to: p.renamed(i, from)}
+		taken[rows[i].to]++
+	}
+	for i := range rows {
+		row := &rows[i]
+		switch {
+		case row.to == "":
+			row.problem = tr(msgNameEmpty)
+		case !validName(row.to):
+			row.problem = tr(msgNameInvalid)
+		case taken[row.to] > 1:
+			row.problem = tr(msgNameTaken)
+		}
+		if row.problem != "" {
+			conflicts++
+		} else if row.to != row.from {
+			renamed++
+		}
+	}
+	return rows, renamed, conflicts
+}
+
+// setRenames replaces the names sprites were renamed to.
+func (s *UIState) setRenames(renames map[string]string) {
+	s.renames = renames
+	s.dirty = true
+}
+
+// applyRename renames the sprites of the dialog as previewed, as one edit.
+// Nothing is renamed while any new name is in conflict.
+func (s *UIState) applyRename(p *renamePrompt) bool {
+	rows, renamed, conflicts := s.renamePreview(p)
+	if conflicts > 0 || renamed == 0 {
+		return false
+	}
+	from := s.renames
+	to := maps.Clone(from)
+	if to == nil {
+		to = make(map[string]string, len(p.names))
+	}
+	for i, name := range p.names {
+		if rows[i].to == name {
+			delete(to, name)
+		} else {
+			to[name] = rows[i].to
+		}
+	}
+	if len(to) == 0 {
+		to = nil
+	}
+	s.setRenames(to)
+	s.record(edit{
+		desc: trf(msgRenameTitle, renamed),
+		undo: func(s *UIState) { s.setRenames(from) },
+		redo: func(s *UIState) { s.setRenames(to) },
+	})
+	s.notify(msgRenamedSprites, renamed)
+	return true
+}
+
+// renderRenamePrompt draws the bulk rename dialog, with a preview of the old
+// and new name of each sprite that marks the names that can't be used.
+func (s *UIState) renderRenamePrompt(cfg Config) rl.Rectangle {
+	p := s.rename
+	width := float32(480)
+	panel := rl.Rectangle{X: (float32(cfg.width) - width) / 2, Y: float32(cfg.height)/2 - 170, Width: width, Height: 345}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(trf(msgRenameTitle, len(p.names)), int32(panel.X)+10, int32(panel.Y)+10, 15, s.theme.Text)
+
+	if drawCheckbox(rl.Rectangle{X: panel.X + 10, Y: panel.Y + 50, Width: 15, Height: 15}, tr(msgFindReplace), !p.number) {
+		p.number = false
+	}
+	if drawCheckbox(rl.Rectangle{X: panel.X + 240, Y: panel.Y + 50, Width: 15, Height: 15}, tr(msgRenumber), p.number) {
+		p.number = true
+	}
+
+	left := rl.Rectangle{X: panel.X + 10, Y: panel.Y + 90, Width: 220, Height: 20}
+	right := rl.Rectangle{X: panel.X + 240, Y: panel.Y + 90, Width: 220, Height: 20}
+	if p.number {
+		p.pattern = s.drawTextField(left, tr(msgRenamePattern), p.pattern)
+		right.Width = 60
+		p.start = s.drawInputField(right, tr(msgStartAt), p.start, 0, 99999, 0)
+	} else {
+		p.find = s.drawTextField(left, tr(msgFind), p.find)
+		p.replace = s.drawTextField(right, tr(msgReplaceWith), p.replace)
+		drawText(tr(msgGlobHint), int32(panel.X)+10, int32(panel.Y)+117, 10, s.theme.MutedText)
+	}
+
+	rows, renamed, conflicts := s.renamePreview(p)
+	summary := s.theme.MutedText
+	if conflicts > 0 {
+		summary = s.theme.Error
+	}
+	drawText(trf(msgRenameSummary, renamed, conflicts), int32(panel.X)+10, int32(panel.Y)+137, 10, summary)
+
+	table := rl.Rectangle{X: panel.X + 10, Y: panel.Y + 155, Width: panel.Width - 20, Height: renameRows * 14}
+	rl.DrawRectangleRec(table, rl.ColorAlpha(s.theme.Background, 0.6))
+	if rl.CheckCollisionPointRec(mousePosition(), table) {
+		p.offset -= int(rl.GetMouseWheelMove())
+	}
+	p.offset = max(0, min(p.offset, len(rows)-renameRows))
+	column := (table.Width - 20) / 2
+	for i, row := range rows[p.offset:min(len(rows), p.offset+renameRows)] {
+		y := int32(table.Y) + int32(i)*14 + 2
+		col := s.theme.MutedText
+		switch {
+		case row.problem != "":
+			col = s.theme.Error
+		case row.to != row.from:
+			col = s.theme.Text
+		}
+		to := row.to
+		if row.problem != "" {
+			to += " (" + row.problem + ")"
+		}
+		drawText(elide(row.from, 10, column-5), int32(table.X)+5, y, 10, col)
+		drawText("->", int32(table.X+column), y, 10, col)
+		drawText(elide(to, 10, column-5), int32(table.X+column)+20, y, 10, col)
+	}
+	if more := len(rows) - p.offset - renameRows; more > 0 {
+		drawText(trf(msgRenameMore, more), int32(panel.X)+10, int32(table.Y+table.Height)+3, 10, s.theme.MutedText)
+	}
+
+	buttonY := panel.Y + panel.Height - 35
+	renameWidth := buttonWidth(tr(msgRename), 90)
+	cancelWidth := buttonWidth(tr(msgCancel), 90)
+	if drawButton(rl.Rectangle{X: panel.X + 10, Y: buttonY, Width: renameWidth, Height: 25}, tr(msgRename)) && s.applyRename(p) {
+		s.rename = nil
+		return panel
+	}
+	if drawButton(rl.Rectangle{X: panel.X + panel.Width - 10 - cancelWidth, Y: buttonY, Width: cancelWidth, Height: 25}, tr(msgCancel)) {
+		s.rename = nil
+	}
+	return panel
+}
@@ -0,0 +1,36 @@
+package viewer
+
+import (
+	"testing"
+
+	"github.com/ztkent/beam/resources"
+)
+
+func TestRenamePreviewProblems(t *testing.T) {
+	s := initUI()
+	s.sheet = &resources.SpriteSheet{Sprites: newSlicing(34, 17, 16, 1).sprites()}
+	s.renames = map[string]string{"0_1": "taken"}
+
+	tests := []struct {
+		to   string
+		want string
+	}{
+		{"hero", ""},
+		{"", tr(msgNameEmpty)},
+		{"taken", tr(msgNameTaken)},
+		{"../../x", tr(msgNameInvalid)},
+		{"a/b", tr(msgNameInvalid)},
+		{`a\b`, tr(msgNameInvalid)},
+		{"..", tr(msgNameInvalid)},
+	}
+	for _, tt := range tests {
+		p := &renamePrompt{names: []string{"0_0"}, number: true, pattern: tt.to}
+		rows, _, conflicts := s.renamePreview(p)
+		if rows[0].problem != tt.want {
+			t.Errorf("renaming to %q: problem %q, want %q", tt.to, rows[0].problem, tt.want)
+		}
+		if ok := conflicts == 0; ok != (tt.want == "") {
+			t.Errorf("renaming to %q: %d conflicts", tt.to, conflicts)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+//go:build gputest
+
+package viewer
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// These tests draw the viewer into a render texture under a hidden window,
+// so they need a GL context and only build with the gputest tag:
+//
+//	go test -tags=gputest ./viewer
+
+// mainThread runs functions on the thread that owns the GL context. Tests
+// run on goroutines of their own, and raylib may only be called from the
+// thread the window was opened on.
+var mainThread = make(chan func())
+
+// gpuReady reports whether the hidden window, and with it the GL context,
+// could be created.
+var gpuReady bool
+
+func TestMain(m *testing.M) {
+	// raylib carries on after GLFW fails to start and crashes, so on Linux
+	// a window is only opened with a display to open it on.
+	if runtime.GOOS != "linux" || os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != "" {
+		rl.SetTraceLogLevel(rl.LogWarning)
+		rl.SetConfigFlags(rl.FlagWindowHidden)
+		rl.InitWindow(800, 600, "viewer tests")
+		gpuReady = rl.IsWindowReady()
+	}
+
+	done := make(chan int)
+	go func() { done <- m.Run() }()
+	for {
+		select {
+		case f := <-mainThread:
+			f()
+		case code := <-done:
+			if gpuReady {
+				rl.CloseWindow()
+			}
+			os.Exit(code)
+		}
+	}
+}
+
+// onMain runs f on the GL thread and waits for it. f must not call t.Fatal
+// or anything else that stops its goroutine.
+func onMain(t *testing.T, f func()) {
+	t.Helper()
+	if !gpuReady {
+		t.Skip("no GL context")
+	}
+	done := make(chan struct{})
+	mainThread <- func() {
+		defer close(done)
+		f()
+	}
+	<-done
+}
+
+// fixtureColor is the color the fixture sheet fills the cell at col, row
+// with.
+func fixtureColor(col, row int) color.RGBA {
+	return color.RGBA{R: uint8(40 + col*60), G: uint8(40 + row*60), B: 200, A: 255}
+}
+
+// writeFixtureSheet writes a sheet of cols x rows cells of size pixels,
+// separated by margin transparent pixels, each filled with its fixtureColor.
+func writeFixtureSheet(t *testing.T, cols, rows, size, margin int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, cols*(size+margin), rows*(size+margin)))
+	for row := range rows {
+		for col := range cols {
+			c := fixtureColor(col, row)
+			for y := range size {
+				for x := range size {
+					img.SetRGBA(col*(size+margin)+x, row*(size+margin)+y, c)
+				}
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// renderedThumb is a thumbnail as laid out and as drawn.
+type renderedThumb struct {
+	name      string
+	rect      rl.Rectangle
+	want      rl.Rectangle
+	col, row  int
+	center    color.RGBA
+	beside    color.RGBA
+	hasBeside bool
+}
+
+func TestRenderGrid(t *testing.T) {
+	const (
+		cols, rows = 4, 4
+		size       = 16
+		margin     = 1
+	)
+	path := writeFixtureSheet(t, cols, rows, size, margin)
+
+	var (
+		loadErr    error
+		thumbs     []renderedThumb
+		background color.RGBA
+	)
+	onMain(t, func() {
+		v := New(Options{Margin: margin, GridSize: size})
+		defer v.Close()
+		if loadErr = v.Load(path, Options{}); loadErr != nil {
+			return
+		}
+
+		bounds := rl.Rectangle{Width: 800, Height: 600}
+		target := rl.LoadRenderTexture(int32(bounds.Width), int32(bounds.Height))
+		defer rl.UnloadRenderTexture(target)
+		rl.BeginDrawing()
+		rl.BeginTextureMode(target)
+		v.Draw(bounds)
+		rl.EndTextureMode()
+		rl.EndDrawing()
+
+		img := rl.LoadImageFromTexture(target.Texture)
+		defer rl.UnloadImage(img)
+		// Render textures are stored bottom up.
+		rl.ImageFlipVertical(img)
+		pixels := imagePixels(img, 0)
+		at := func(x, y float32) color.RGBA {
+			return pixels.pix[int(y)*int(pixels.width)+int(x)]
+		}
+
+		s, cfg := v.state, v.cfg
+		background = color.RGBA(s.theme.Background)
+		w, h := cfg.cellSize()
+		perRow := cfg.spritesPerRow()
+		for i, name := range s.spriteNames {
+			rect := s.sheet.Sprites[name]
+			thumb := renderedThumb{
+				name: name,
+				rect: s.thumbnailRect(cfg, i, rect),
+				want: rl.Rectangle{
+					X:      float32(cfg.startX + int32(i%perRow)*cfg.columnWidth()),
+					Y:      float32(cfg.startY + int32(i/perRow)*cfg.rowHeight()),
+					Width:  float32(w),
+					Height: float32(h),
+				},
+				col: int(rect.X) / (size + margin),
+				row: int(rect.Y) / (size + margin),
+			}
+			thumb.center = at(thumb.rect.X+thumb.rect.Width/2, thumb.rect.Y+thumb.rect.Height/2)
+			if x := thumb.rect.X + thumb.rect.Width + float32(cfg.padding)/2; x < bounds.Width {
+				thumb.beside, thumb.hasBeside = at(x, thumb.rect.Y+thumb.rect.Height/2), true
+			}
+			thumbs = append(thumbs, thumb)
+		}
+	})
+	if loadErr != nil {
+		t.Fatalf("Load: %v", loadErr)
+	}
+
+	if len(thumbs) != cols*rows {
+		t.Fatalf("got %d thumbnails, want %d", len(thumbs), cols*rows)
+	}
+	for _, thumb := range thumbs {
+		if thumb.rect != thumb.want {
+			t.Errorf("%s: thumbnail at %v, want %v", thumb.name, thumb.rect, thumb.want)
+		}
+		if want := fixtureColor(thumb.col, thumb.row); !closeColor(thumb.center, want) {
+			t.Errorf("%s: center pixel %v, want %v", thumb.name, thumb.center, want)
+		}
+		if thumb.hasBeside && !closeColor(thumb.beside, background) {
+			t.Errorf("%s: pixel beside the thumbnail %v, want the background %v", thumb.name, thumb.beside, background)
+		}
+	}
+}
+
+// closeColor reports whether a and b differ by at most 2 in every channel,
+// which leaves room for the rounding of texture filtering.
+func closeColor(a, b color.RGBA) bool {
+	near := func(x, y uint8) bool { return max(x, y)-min(x, y) <= 2 }
+	return near(a.R, b.R) && near(a.G, b.G) && near(a.B, b.B) && near(a.A, b.A)
+}
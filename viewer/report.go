@@ -0,0 +1,225 @@
+package viewer
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// sheetReport summarizes the loaded sheet for a quick audit of an asset.
+type sheetReport struct {
+	file          string
+	width, height int32
+	cols, rows    int32
+	cellWidth     int32
+	cellHeight    int32
+	margin        int32
+	sprites       int
+	// cellCheck compares the sprites with the expected grid, when the
+	// resources package sliced the sheet.
+	cellCheck       string
+	empty           int
+	duplicateGroups int
+	duplicates      int
+	// fileLines describe the file on disk, from fileInfo.
+	fileLines []string
+	// groups numbers the duplicate groups from 1, by sprite name, so their
+	// members can be marked in the grid.
+	groups map[string]int
+	// mirrors are the pairs of sprites that are horizontal flips of each
+	// other. mirrorPair numbers them from 1 and mirrorOf names a partner,
+	// both by sprite name.
+	mirrors    [][2]string
+	mirrorPair map[string]int
+	mirrorOf   map[string]string
+
+	// textureMemory is the GPU memory the sheet takes as shown, which is
+	// less than its size suggests when the preview is downscaled.
+	textureMemory int64
+	mipmapTime    time.Duration
+	mipmaps       bool
+}
+
+// maxMirrorLines caps how many mirror pairs the report lists one by one.
+const maxMirrorLines = 8
+
+// group returns the number of the duplicate group name belongs to, or 0 if
+// it has no duplicate or there is no report.
+func (r *sheetReport) group(name string) int {
+	if r == nil {
+		return 0
+	}
+	return r.groups[name]
+}
+
+// mirror returns the number of the mirror pair name belongs to and the
+// sprite it mirrors, or 0 if it has no mirror or there is no report.
+func (r *sheetReport) mirror(name string) (int, string) {
+	if r == nil {
+		return 0, ""
+	}
+	return r.mirrorPair[name], r.mirrorOf[name]
+}
+
+// buildReport gathers the report for the loaded sheet. Empty and duplicate
+// cells need the sheet's pixels; if those can't be read the counts are left
+// at zero and the error is returned alongside the rest of the report.
+func (s *UIState) buildReport() (*sheetReport, error) {
+	tex := s.sheet.Texture
+	r := &sheetReport{
+		file:          filepath.Base(s.currentFile),
+		width:         tex.Width,
+		height:        tex.Height,
+		textureMemory: s.textureMemory(),
+		mipmapTime:    s.mipmapTime,
+		mipmaps:       s.mipmaps,
+		cols:          s.slicing.cols,
+		rows:          s.slicing.rows,
+		cellWidth:     s.slicing.cellWidth,
+		cellHeight:    s.slicing.cellHeight,
+		margin:        s.slicing.margin,
+		sprites:       len(s.spriteNames),
+	}
+	if c := s.cellCheck(); c != nil {
+		r.cellCheck = c.summary()
+	}
+	if s.fileInfoErr != nil {
+		r.fileLines = []string{trf(msgReportFileError, s.fileInfoErr)}
+	} else {
+		r.fileLines = s.fileInfo.lines()
+	}
+
+	p, err := s.sheetPixels()
+	if err != nil {
+		return r, err
+	}
+	r.empty = len(p.emptyCells(s.sheet.Sprites, s.spriteNames))
+	r.groups = make(map[string]int)
+	for _, g := range p.duplicateGroups(s.sheet.Sprites, s.spriteNames) {
+		r.duplicateGroups++
+		r.duplicates += len(g)
+		for _, name := range g {
+			r.groups[name] = r.duplicateGroups
+		}
+	}
+
+	r.mirrors = p.mirrorPairs(s.sheet.Sprites, s.spriteNames)
+	r.mirrorPair = make(map[string]int)
+	r.mirrorOf = make(map[string]string)
+	for i, pair := range r.mirrors {
+		for j, name := range pair {
+			if r.mirrorPair[name] == 0 {
+				r.mirrorPair[name] = i + 1
+				r.mirrorOf[name] = pair[1-j]
+			}
+		}
+	}
+	return r, nil
+}
+
+// lines returns the report as one line per entry.
+func (r *sheetReport) lines() []string {
+	lines := []string{trf(msgReportSheet, r.file)}
+	lines = append(lines, r.fileLines...)
+	lines = append(lines,
+		trf(msgReportSize, r.width, r.height),
+		trf(msgReportTexture, formatBytes(r.textureMemory)),
+		r.mipmapLine(),
+		trf(msgReportCells, r.cellWidth, r.cellHeight, r.margin),
+		trf(msgReportLayout, r.cols, r.rows),
+		trf(msgReportSprites, r.sprites),
+	)
+	if r.cellCheck != "" {
+		lines = append(lines, trf(msgReportCellCheck, r.cellCheck))
+	}
+	lines = append(lines,
+		trf(msgReportEmpty, r.empty),
+		trf(msgReportDuplicates, r.duplicateGroups, r.duplicates),
+		trf(msgReportMirrors, len(r.mirrors)),
+	)
+	for i, pair := range r.mirrors {
+		if i == maxMirrorLines {
+			lines = append(lines, trf(msgReportMoreMirrors, len(r.mirrors)-maxMirrorLines))
+			break
+		}
+		lines = append(lines, trf(msgReportMirrorPair, i+1, pair[1], pair[0]))
+	}
+	return lines
+}
+
+// mipmapLine says whether the sheet has mipmaps and how long building them
+// took.
+func (r *sheetReport) mipmapLine() string {
+	if !r.mipmaps {
+		return tr(msgReportNoMipmaps)
+	}
+	return trf(msgReportMipmaps, r.mipmapTime.Milliseconds())
+}
+
+// String returns the report as plain text, as copied to the clipboard.
+func (r *sheetReport) String() string {
+	return strings.Join(r.lines(), "\n") + "\n"
+}
+
+// toggleReport shows the sheet info overlay, or hides it if it is open.
+func (s *UIState) toggleReport() {
+	if s.report != nil {
+		s.report = nil
+		return
+	}
+	if s.sheet == nil {
+		s.notify(msgOpenSheetForInfo)
+		return
+	}
+	s.refreshReport(true)
+}
+
+// refreshReport rebuilds the report after the sheet was resliced. It does
+// nothing unless the overlay is open or force is set.
+func (s *UIState) refreshReport(force bool) {
+	if s.report == nil && !force {
+		return
+	}
+	r, err := s.buildReport()
+	if err != nil {
+		s.notify(msgInspectFailed, err)
+	}
+	s.report = r
+}
+
+// renderReport draws the sheet info overlay.
+func (s *UIState) renderReport(cfg Config) rl.Rectangle {
+	lines := s.report.lines()
+	width := int32(360)
+	for _, line := range lines {
+		width = max(width, measureText(line, 10)+20)
+	}
+	panel := rl.Rectangle{
+		X:      float32(cfg.width-width) / 2,
+		Y:      float32(cfg.headerHeight + 30),
+		Width:  float32(width),
+		Height: float32(45 + len(lines)*18 + 40),
+	}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(tr(msgSheetInfo), int32(panel.X)+10, int32(panel.Y)+10, 15, s.theme.Text)
+
+	for i, line := range lines {
+		drawText(line, int32(panel.X)+10, int32(panel.Y)+40+int32(i)*18, 10, s.theme.Text)
+	}
+
+	buttonY := panel.Y + panel.Height - 35
+	closeWidth := buttonWidth(tr(msgClose), 90)
+	closeX := panel.X + panel.Width - 10 - closeWidth
+	copyWidth := buttonWidth(tr(msgCopy), 90)
+	if drawButton(rl.Rectangle{X: closeX - 10 - copyWidth, Y: buttonY, Width: copyWidth, Height: 25}, tr(msgCopy)) {
+		rl.SetClipboardText(s.report.String())
+		s.notify(msgInfoCopied)
+	}
+	if drawButton(rl.Rectangle{X: closeX, Y: buttonY, Width: closeWidth, Height: 25}, tr(msgClose)) {
+		s.report = nil
+	}
+	return panel
+}
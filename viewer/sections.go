@@ -0,0 +1,207 @@
+package viewer
+
+import (
+	"fmt"
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// sectionHeaderHeight is the height of the bar above each group of sprites
+// when the grid is grouped by name prefix.
+const sectionHeaderHeight = 22
+
+// spriteSection is a run of consecutive sprites in spriteNames that share a
+// name prefix. top is the offset of its header below the top of the grid,
+// set by layoutSections.
+type spriteSection struct {
+	prefix     string
+	start, end int
+	top        int32
+}
+
+// namePrefix returns the part of a sprite name before its first underscore,
+// the same split sortNames compares by, so "walk_3" groups under "walk".
+// The resources package names grid cells "row_col", which groups them by row.
+func namePrefix(name string) string {
+	return nameParts(name)[0]
+}
+
+// groupByPrefix arranges names so sprites sharing a prefix are adjacent and
+// returns the resulting sections. Groups appear in the order their first
+// sprite does, and sprites keep their relative order within a group.
+func groupByPrefix(names []string) ([]string, []spriteSection) {
+	var prefixes []string
+	groups := make(map[string][]string)
+	for _, name := range names {
+		prefix := namePrefix(name)
+		if _, ok := groups[prefix]; !ok {
+			prefixes = append(prefixes, prefix)
+		}
+		groups[prefix] = append(groups[prefix], name)
+	}
+
+	grouped := make([]string, 0, len(names))
+	sections := make([]spriteSection, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		start := len(grouped)
+		grouped = append(grouped, groups[prefix]...)
+		sections = append(sections, spriteSection{prefix: prefix, start: start, end: len(grouped)})
+	}
+	return grouped, sections
+}
+
+// grouped reports whether the grid is currently laid out in sections.
+func (s *UIState) grouped() bool {
+	return s.viewMode == gridView && len(s.sections) > 0
+}
+
+// setGroupByPrefix turns grouping the grid by name prefix on or off.
+func (s *UIState) setGroupByPrefix(on bool) {
+	s.groupPrefix = on
+	if s.sheet != nil {
+		s.updateSpriteNames()
+	}
+}
+
+// toggleSection collapses or expands the section with the given prefix.
+func (s *UIState) toggleSection(prefix string) {
+	if s.collapsed == nil {
+		s.collapsed = make(map[string]bool)
+	}
+	if s.collapsed[prefix] {
+		delete(s.collapsed, prefix)
+	} else {
+		s.collapsed[prefix] = true
+	}
+}
+
+// selectSection selects every sprite of the section at index i, leaving the
+// rest of the selection alone.
+func (s *UIState) selectSection(i int) {
+	for _, name := range s.spriteNames[s.sections[i].start:s.sections[i].end] {
+		s.selected[name] = true
+	}
+}
+
+// layoutSections positions the section headers for the current layout and
+// returns the height of the grid. Collapsed sections only take up their
+// header.
+func (s *UIState) layoutSections(cfg Config) int32 {
+	perRow := cfg.spritesPerRow()
+	var top int32
+	for k := range s.sections {
+		sec := &s.sections[k]
+		sec.top = top
+		top += sectionHeaderHeight
+		if !s.collapsed[sec.prefix] {
+			rows := (sec.end - sec.start + perRow - 1) / perRow
+			top += int32(rows) * cfg.rowHeight()
+		}
+	}
+	return top
+}
+
+// sectionIndex returns the index of the section holding the sprite at index
+// i of spriteNames, or -1 when the grid isn't grouped.
+func (s *UIState) sectionIndex(i int) int {
+	if !s.grouped() {
+		return -1
+	}
+	k := sort.Search(len(s.sections), func(k int) bool { return s.sections[k].end > i })
+	if k == len(s.sections) {
+		return -1
+	}
+	return k
+}
+
+// sectionAt returns the index of the section whose area contains the grid
+// offset y, or -1 above the first one.
+func (s *UIState) sectionAt(y float32) int {
+	return sort.Search(len(s.sections), func(k int) bool { return float32(s.sections[k].top) > y }) - 1
+}
+
+// cellHidden reports whether the sprite at index i is in a collapsed section.
+func (s *UIState) cellHidden(i int) bool {
+	k := s.sectionIndex(i)
+	return k >= 0 && s.collapsed[s.sections[k].prefix]
+}
+
+// gridPosition returns the column and row of the sprite at index i in the
+// grid view, and the offset below the top of the grid its rows start at.
+// Rows are counted from the start of the sprite's section when grouped, and
+// filled from the right when reading right to left.
+func (s *UIState) gridPosition(cfg Config, i int) (col, row int, top int32) {
+	perRow := cfg.spritesPerRow()
+	if k := s.sectionIndex(i); k >= 0 {
+		sec := s.sections[k]
+		i -= sec.start
+		top = sec.top + sectionHeaderHeight
+	}
+	col = i % perRow
+	if s.rightToLeft {
+		col = perRow - 1 - col
+	}
+	return col, i / perRow, top
+}
+
+// sectionHeaderRect returns the on-screen bar of section k, spanning the
+// grid's columns.
+func (s *UIState) sectionHeaderRect(cfg Config, k int) rl.Rectangle {
+	return rl.Rectangle{
+		X:      float32(cfg.startX) - s.scrollOffsetX,
+		Y:      float32(cfg.startY+s.sections[k].top) - s.scrollOffset,
+		Width:  float32(int32(cfg.spritesPerRow())*cfg.columnWidth() - cfg.padding),
+		Height: sectionHeaderHeight - 4,
+	}
+}
+
+// hoveredSection returns the index of the section header under the mouse
+// cursor, or -1.
+func (s *UIState) hoveredSection(cfg Config) int {
+	if !s.grouped() {
+		return -1
+	}
+	mouse := mousePosition()
+	if mouse.Y < float32(cfg.headerHeight) || mouse.Y > float32(cfg.startY+cfg.viewportHeight) {
+		return -1
+	}
+	k := s.sectionAt(mouse.Y + s.scrollOffset - float32(cfg.startY))
+	if k < 0 || !rl.CheckCollisionPointRec(mouse, s.sectionHeaderRect(cfg, k)) {
+		return -1
+	}
+	return k
+}
+
+// drawSectionHeaders draws the header bar of every section in view, with an
+// arrow showing whether it is collapsed and the number of sprites in it.
+func (s *UIState) drawSectionHeaders(cfg Config, hovered int) {
+	for k, sec := range s.sections {
+		bar := s.sectionHeaderRect(cfg, k)
+		if bar.Y+bar.Height < 0 || bar.Y > float32(cfg.height) {
+			continue
+		}
+		fill := rl.ColorAlpha(s.theme.Panel, 0.6)
+		if k == hovered {
+			fill = s.theme.Panel
+		}
+		rl.DrawRectangleRec(bar, fill)
+
+		x, y := bar.X+6, bar.Y+bar.Height/2
+		if s.collapsed[sec.prefix] {
+			rl.DrawTriangle(
+				rl.Vector2{X: x + 6, Y: y},
+				rl.Vector2{X: x, Y: y + 4},
+				rl.Vector2{X: x, Y: y - 4},
+				s.theme.Text)
+		} else {
+			rl.DrawTriangle(
+				rl.Vector2{X: x + 3, Y: y + 3},
+				rl.Vector2{X: x - 1, Y: y - 3},
+				rl.Vector2{X: x + 7, Y: y - 3},
+				s.theme.Text)
+		}
+		label := fmt.Sprintf("%s (%d)", sec.prefix, sec.end-sec.start)
+		drawText(label, int32(x)+14, int32(y)-5, 10, s.theme.Text)
+	}
+}
@@ -0,0 +1,195 @@
+package viewer
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// Magnifier settings: the lens is lensSize pixels square and shows the sheet
+// at lensZoom screen pixels per sheet pixel, adjustable with the wheel
+// between minLensZoom and maxLensZoom while the lens is up.
+const (
+	lensSize         = 160
+	lensOffset       = 20
+	defaultLensZoom  = 6
+	minLensZoom      = 4
+	maxLensZoom      = 8
+	sheetViewPadding = 10
+)
+
+// sheetViewRect returns where the whole sheet is drawn in the sheet view and
+// the scale it is drawn at. Sheets smaller than the viewport are scaled up by
+// a whole factor so their pixels stay square.
+func (s *UIState) sheetViewRect(cfg Config) (rl.Rectangle, float32) {
+	tex := s.sheet.Texture
+	areaWidth := float32(cfg.width - 2*sheetViewPadding)
+	areaHeight := float32(cfg.viewportHeight - 2*sheetViewPadding)
+	scale := min(areaWidth/float32(tex.Width), areaHeight/float32(tex.Height))
+	if scale >= 1 {
+		scale = float32(int(scale))
+	}
+	width, height := float32(tex.Width)*scale, float32(tex.Height)*scale
+	return rl.Rectangle{
+		X:      (float32(cfg.width) - width) / 2,
+		Y:      float32(cfg.startY) + (float32(cfg.viewportHeight)-height)/2,
+		Width:  width,
+		Height: height,
+	}, scale
+}
+
+// sheetPoint converts a position in the sheet view to sheet pixel
+// coordinates, and reports whether it falls on the sheet.
+func (s *UIState) sheetPoint(cfg Config, p rl.Vector2) (rl.Vector2, bool) {
+	dest, scale := s.sheetViewRect(cfg)
+	if !rl.CheckCollisionPointRec(p, dest) {
+		return rl.Vector2{}, false
+	}
+	return rl.Vector2{X: (p.X - dest.X) / scale, Y: (p.Y - dest.Y) / scale}, true
+}
+
+// sheetCellAt returns the index in spriteNames of the sprite under the
+// sheet view position p, or -1.
+func (s *UIState) sheetCellAt(cfg Config, p rl.Vector2) int {
+	px, ok := s.sheetPoint(cfg, p)
+	if !ok {
+		return -1
+	}
+	for i, name := range s.spriteNames {
+		if rl.CheckCollisionPointRec(px, spriteSource(s.sheet.Sprites[name])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderSheetView draws the whole sheet fitted to the viewport with the
+// slicing grid over it. Clicking a cell selects its sprite as in the grid,
+// and holding Z or the middle mouse button shows the magnifier.
+func (s *UIState) renderSheetView(cfg Config) {
+	dest, scale := s.sheetViewRect(cfg)
+	tex := s.drawTexture()
+	rl.DrawTexturePro(tex, rl.Rectangle{Width: float32(tex.Width), Height: float32(tex.Height)}, dest, rl.Vector2{}, 0, rl.White)
+
+	cellOnScreen := func(i int) rl.Rectangle {
+		src := spriteSource(s.sheet.Sprites[s.spriteNames[i]])
+		return rl.Rectangle{X: dest.X + src.X*scale, Y: dest.Y + src.Y*scale, Width: src.Width * scale, Height: src.Height * scale}
+	}
+	grid := rl.ColorAlpha(s.theme.CellBorder, 0.6)
+	if s.showCellCheck {
+		grid = s.theme.DiffChanged
+	}
+	playing := s.playingSprite()
+	for i, name := range s.spriteNames {
+		cell := cellOnScreen(i)
+		rl.DrawRectangleLinesEx(cell, 1, grid)
+		if name == playing {
+			s.drawPlayingOutline(cell)
+		}
+		if s.selected[name] {
+			s.drawSelectionOutline(cell)
+		}
+	}
+
+	if s.showCellCheck {
+		s.drawCellCheck(cfg, dest, scale)
+	}
+	if s.measureGrid {
+		s.drawMeasureGrid(rl.Rectangle{Width: dest.Width / scale, Height: dest.Height / scale}, dest)
+	}
+
+	hovered := s.hoveredCell(cfg)
+	lens := s.lensActive()
+	if lens {
+		// The tooltip would cover the lens.
+		s.hover.update(-1)
+	} else {
+		s.hover.update(hovered)
+	}
+	if hovered >= 0 {
+		rl.DrawRectangleLinesEx(cellOnScreen(hovered), 1, s.theme.Text)
+	}
+	mouse := mousePosition()
+	if rl.IsMouseButtonPressed(rl.MouseLeftButton) && mouse.Y > float32(cfg.headerHeight) && mouse.Y < float32(cfg.startY+cfg.viewportHeight) && !s.overInspector(cfg) {
+		if s.pickingKey() {
+			s.pickKey(cfg)
+		} else {
+			s.clickCell(hovered)
+		}
+	}
+
+	if lens {
+		s.drawLens(cfg)
+	}
+}
+
+// lensActive reports whether the magnifier is being held up: Z without Ctrl,
+// so undo doesn't trigger it, or the middle mouse button.
+func (s *UIState) lensActive() bool {
+	if rl.IsMouseButtonDown(rl.MouseMiddleButton) {
+		return true
+	}
+	ctrl := rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl)
+	return rl.IsKeyDown(rl.KeyZ) && !ctrl && s.keyboardFree()
+}
+
+// drawLens draws the magnifier next to the cursor, showing the sheet pixels
+// around it enlarged with nearest-neighbor sampling and the cell borders on
+// top. The magnified area stops at the sheet edges instead of running past
+// them, and the lens is kept inside the viewport. It is drawn only; clicks
+// still go to the view under it.
+func (s *UIState) drawLens(cfg Config) {
+	mouse := mousePosition()
+	px, ok := s.sheetPoint(cfg, mouse)
+	if !ok {
+		return
+	}
+	tex := s.sheet.Texture
+	zoom := float32(s.lensZoom)
+
+	src := rl.Rectangle{Width: min(lensSize/zoom, float32(tex.Width)), Height: min(lensSize/zoom, float32(tex.Height))}
+	src.X = max(0, min(float32(int(px.X-src.Width/2)), float32(tex.Width)-src.Width))
+	src.Y = max(0, min(float32(int(px.Y-src.Height/2)), float32(tex.Height)-src.Height))
+
+	lens := rl.Rectangle{X: mouse.X + lensOffset, Y: mouse.Y + lensOffset, Width: src.Width * zoom, Height: src.Height * zoom}
+	if lens.X+lens.Width > float32(cfg.width) {
+		lens.X = mouse.X - lensOffset - lens.Width
+	}
+	if lens.Y+lens.Height > float32(cfg.startY+cfg.viewportHeight) {
+		lens.Y = mouse.Y - lensOffset - lens.Height
+	}
+	lens.X = max(lens.X, 0)
+	lens.Y = max(lens.Y, float32(cfg.headerHeight))
+
+	rl.DrawRectangleRec(lens, s.theme.Background)
+	rl.DrawTexturePro(s.drawTexture(), s.texSource(src), lens, rl.Vector2{}, 0, rl.White)
+
+	toLens := func(r rl.Rectangle) rl.Rectangle {
+		return rl.Rectangle{X: lens.X + (r.X-src.X)*zoom, Y: lens.Y + (r.Y-src.Y)*zoom, Width: r.Width * zoom, Height: r.Height * zoom}
+	}
+	// Cell borders are clipped to the lens by hand, since the viewer's own
+	// scissor area is already in use. A clipped edge lands under the frame.
+	grid := rl.ColorAlpha(s.theme.CellBorder, 0.8)
+	for _, name := range s.spriteNames {
+		cell := spriteSource(s.sheet.Sprites[name])
+		if rl.CheckCollisionRecs(cell, src) {
+			rl.DrawRectangleLinesEx(rl.GetCollisionRec(toLens(cell), lens), 1, grid)
+		}
+	}
+	pixel := rl.Rectangle{X: float32(int(px.X)), Y: float32(int(px.Y)), Width: 1, Height: 1}
+	rl.DrawRectangleLinesEx(toLens(pixel), 1, s.selectionColor())
+
+	rl.DrawRectangleLinesEx(lens, 2, s.theme.Text)
+	drawBadge(lens, fmt.Sprintf("%dx", s.lensZoom), s.theme.Text)
+}
+
+// spriteSource returns a sprite's source rectangle in the sheet texture.
+func spriteSource(rect resources.Rectangle) rl.Rectangle {
+	return rl.Rectangle{X: float32(rect.X), Y: float32(rect.Y), Width: float32(rect.Width), Height: float32(rect.Height)}
+}
+
+// zoomLens changes the magnification by steps, within its limits.
+func (s *UIState) zoomLens(steps int32) {
+	s.lensZoom = max(minLensZoom, min(maxLensZoom, s.lensZoom+steps))
+}
@@ -0,0 +1,172 @@
+package viewer
+
+import (
+	"fmt"
+
+	"github.com/ztkent/beam/resources"
+)
+
+// sheetSlicing describes how an image is cut into grid cells. It mirrors the
+// math used by the resources package so cells can be computed for previews
+// and headless exports without building a ResourceManager.
+type sheetSlicing struct {
+	cellWidth  int32
+	cellHeight int32
+	margin     int32
+	cols       int32
+	rows       int32
+}
+
+// newSlicing returns the slicing of an image of the given size, applying the
+// same defaults as the resources package for zero grid size or margin.
+func newSlicing(width, height, gridSize, margin int32) sheetSlicing {
+	if gridSize == 0 {
+		gridSize = resources.DefaultGridSize
+	}
+	if margin == 0 {
+		margin = resources.DefaultMargin
+	}
+	return sheetSlicing{
+		cellWidth:  gridSize,
+		cellHeight: gridSize,
+		margin:     margin,
+		cols:       width / (gridSize + margin),
+		rows:       height / (gridSize + margin),
+	}
+}
+
+// newCountSlicing returns the slicing of an image of the given size into
+// cols by rows cells, deriving the cell size from the image. Sheets are
+// accepted both with and without a margin after the last cell. exact reports
+// whether the cells came out to whole pixels; if not, the cell size is
+// rounded down.
+func newCountSlicing(width, height, cols, rows, margin int32) (g sheetSlicing, exact bool) {
+	if margin == 0 {
+		margin = resources.DefaultMargin
+	}
+	cellWidth, exactX := cellExtent(width, cols, margin)
+	cellHeight, exactY := cellExtent(height, rows, margin)
+	g = sheetSlicing{cellWidth: cellWidth, cellHeight: cellHeight, margin: margin, cols: cols, rows: rows}
+	if cellWidth < 1 || cellHeight < 1 {
+		g.cols, g.rows = 0, 0
+	}
+	return g, exactX && exactY
+}
+
+// cellExtent splits size into n cells separated by margin.
+func cellExtent(size, n, margin int32) (int32, bool) {
+	if n < 1 {
+		return 0, false
+	}
+	if size%n == 0 {
+		return size/n - margin, true
+	}
+	inner := size - (n-1)*margin
+	return inner / n, inner%n == 0
+}
+
+// cell returns the source rectangle of the cell at col, row.
+func (g sheetSlicing) cell(col, row int32) resources.Rectangle {
+	return resources.Rectangle{
+		X:      col * (g.cellWidth + g.margin),
+		Y:      row * (g.cellHeight + g.margin),
+		Width:  g.cellWidth,
+		Height: g.cellHeight,
+	}
+}
+
+// position returns the column and row of the cell whose source rectangle
+// starts at rect.
+func (g sheetSlicing) position(rect resources.Rectangle) (col, row int32) {
+	return rect.X / (g.cellWidth + g.margin), rect.Y / (g.cellHeight + g.margin)
+}
+
+// sprites returns every cell keyed by the "row_col" names the resources
+// package assigns.
+func (g sheetSlicing) sprites() map[string]resources.Rectangle {
+	sprites := make(map[string]resources.Rectangle, g.cols*g.rows)
+	for row := int32(0); row < g.rows; row++ {
+		for col := int32(0); col < g.cols; col++ {
+			sprites[fmt.Sprintf("%d_%d", row, col)] = g.cell(col, row)
+		}
+	}
+	return sprites
+}
+
+// slicingFor returns the slicing the current settings give an image of the
+// given size: by pixel grid size, or by column and row count in count mode.
+// exact is false when count mode had to round the cell size down.
+func (s *UIState) slicingFor(width, height int32) (g sheetSlicing, exact bool) {
+	if s.sliceByCount {
+		return newCountSlicing(width, height, s.columns, s.rows, s.margin)
+	}
+	return newSlicing(width, height, s.gridSize, s.margin), true
+}
+
+// gridFitError explains why a square grid yields no cells on a sheet of the
+// given size.
+func gridFitError(g sheetSlicing, width, height int32) error {
+	exceeds, withMargin, size := msgGridExceedsHeight, msgGridMarginExceedsHeight, height
+	if g.cols == 0 {
+		exceeds, withMargin, size = msgGridExceedsWidth, msgGridMarginExceedsWidth, width
+	}
+	if g.cellWidth > size {
+		return fmt.Errorf(tr(exceeds), g.cellWidth, size)
+	}
+	return fmt.Errorf(tr(withMargin), g.cellWidth, g.margin, size)
+}
+
+// maxGridSize returns the largest grid size that still fits at least one
+// cell on a sheet of the given size with the current margin.
+func (s *UIState) maxGridSize(width, height int32) int32 {
+	margin := s.margin
+	if margin == 0 {
+		margin = resources.DefaultMargin
+	}
+	return max(min(width, height)-margin, 1)
+}
+
+// updateGridCap works out the largest grid size the settings panel offers
+// for the loaded sheet with the current margin.
+func (s *UIState) updateGridCap() {
+	s.gridCap = min(gridSizeLimit, s.maxGridSize(s.sheet.Texture.Width, s.sheet.Texture.Height))
+}
+
+// checkTextureSize rejects images the grid can't slice sensibly: ones
+// without pixels, which a corrupt file can decode to, and ones smaller than
+// a single grid cell, such as a 1x1 decoration loaded by mistake.
+func (s *UIState) checkTextureSize(width, height int32) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf(tr(msgImageEmpty), width, height)
+	}
+	if !s.sliceByCount && (width < s.gridSize || height < s.gridSize) {
+		return fmt.Errorf(tr(msgImageTooSmall), width, height, s.gridSize)
+	}
+	return nil
+}
+
+// resliceSheet applies the current settings to a freshly loaded sheet. The
+// resources package only knows square cells, so in count mode the sprites it
+// found are replaced with cells derived from the column and row count.
+func (s *UIState) resliceSheet(sheet *resources.SpriteSheet) (sheetSlicing, error) {
+	if err := s.checkTextureSize(sheet.Texture.Width, sheet.Texture.Height); err != nil {
+		return sheetSlicing{}, err
+	}
+	g, exact := s.slicingFor(sheet.Texture.Width, sheet.Texture.Height)
+	if !s.sliceByCount {
+		if g.cols == 0 || g.rows == 0 {
+			return g, gridFitError(g, sheet.Texture.Width, sheet.Texture.Height)
+		}
+		return g, nil
+	}
+	if g.cols == 0 || g.rows == 0 {
+		return g, fmt.Errorf(tr(msgCountDoesntFit),
+			s.columns, s.rows, sheet.Texture.Width, sheet.Texture.Height)
+	}
+	if !exact {
+		s.notify(msgCountInexact,
+			sheet.Texture.Width, sheet.Texture.Height, s.columns, s.rows, g.cellWidth, g.cellHeight)
+	}
+	sheet.Sprites = g.sprites()
+	return g, nil
+}
@@ -0,0 +1,184 @@
+package viewer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// defaultStripSpacing is the default gap between frames of an exported
+// strip.
+const defaultStripSpacing int32 = 0
+
+// stripInfo describes an exported strip image. Frames are FrameWidth by
+// FrameHeight and Spacing pixels apart, in the order of Names.
+type stripInfo struct {
+	Image       string   `json:"image"`
+	Direction   string   `json:"direction"`
+	Frames      int      `json:"frames"`
+	FrameWidth  int32    `json:"frameWidth"`
+	FrameHeight int32    `json:"frameHeight"`
+	Spacing     int32    `json:"spacing"`
+	Names       []string `json:"names"`
+}
+
+// buildStrip lays the named sprites of src out in one row, or one column
+// when vertical is set, spacing pixels apart. Every frame is as large as
+// the largest cell. With trim set, the frames are cut to the union of the
+// content bounds of all cells instead, so they keep their alignment with
+// each other. It also returns the source rectangle of each frame, clipped
+// to its cell, in the same order. An empty frame size means every sprite
+// is fully transparent.
+func buildStrip(src *rl.Image, image string, rects map[string]resources.Rectangle, names []string, vertical, trim bool, spacing int32, alpha uint8) (stripInfo, []rl.Rectangle) {
+	info := stripInfo{Image: image, Direction: "horizontal", Frames: len(names), Spacing: spacing, Names: names}
+	if vertical {
+		info.Direction = "vertical"
+	}
+
+	var union atlasRect
+	var pixels *sheetPixels
+	if trim {
+		pixels = imagePixels(src, alpha)
+	}
+	for _, name := range names {
+		rect := rects[name]
+		if !trim {
+			info.FrameWidth = max(info.FrameWidth, rect.Width)
+			info.FrameHeight = max(info.FrameHeight, rect.Height)
+			continue
+		}
+		content := pixels.contentBounds(rect)
+		if content.W == 0 || content.H == 0 {
+			continue
+		}
+		if union.W == 0 {
+			union = content
+			continue
+		}
+		right, bottom := max(union.X+union.W, content.X+content.W), max(union.Y+union.H, content.Y+content.H)
+		union.X, union.Y = min(union.X, content.X), min(union.Y, content.Y)
+		union.W, union.H = right-union.X, bottom-union.Y
+	}
+	if trim {
+		info.FrameWidth, info.FrameHeight = union.W, union.H
+	}
+
+	sources := make([]rl.Rectangle, 0, len(names))
+	for _, name := range names {
+		rect := rects[name]
+		source := spriteSource(rect)
+		if trim {
+			source.X += float32(union.X)
+			source.Y += float32(union.Y)
+			source.Width = float32(max(min(union.W, rect.Width-union.X), 0))
+			source.Height = float32(max(min(union.H, rect.Height-union.Y), 0))
+		}
+		sources = append(sources, source)
+	}
+	return info, sources
+}
+
+// size returns the size of the strip image.
+func (info stripInfo) size() (int32, int32) {
+	length := int32(info.Frames)
+	if info.Direction == "vertical" {
+		return info.FrameWidth, length*info.FrameHeight + (length-1)*info.Spacing
+	}
+	return length*info.FrameWidth + (length-1)*info.Spacing, info.FrameHeight
+}
+
+// orderName describes the order sprites are listed in, for messages about
+// exports that follow it.
+func (s *UIState) orderName() string {
+	var order string
+	switch {
+	case s.order != nil:
+		order = tr(msgOrderCustom)
+	case s.aseprite != nil:
+		order = tr(msgOrderAseprite)
+	case s.rightToLeft:
+		order = tr(msgOrderRightToLeft)
+	default:
+		order = tr(msgOrderNatural)
+	}
+	if s.grouped() {
+		order = trf(msgOrderGrouped, order)
+	}
+	return order
+}
+
+// exportStrip asks for a destination folder and writes the selected sprites
+// there as a single row or column of frames, in display order, with a JSON
+// file giving the frame count and size. Shift-clicking a group header
+// selects a whole prefix group for this.
+func (s *UIState) exportStrip() {
+	if s.sheet == nil {
+		s.notify(msgNothingToExport)
+		return
+	}
+	names := s.selectedNames()
+	if len(names) == 0 {
+		s.notify(msgSelectStripFrames)
+		return
+	}
+
+	dir := openDirectoryDialog()
+	if dir == "" {
+		return
+	}
+
+	src := rl.LoadImage(s.currentFile)
+	if !rl.IsImageValid(src) {
+		s.notify(msgExportReadFailed, filepath.Base(s.currentFile))
+		return
+	}
+	defer rl.UnloadImage(src)
+
+	base := strings.TrimSuffix(filepath.Base(s.currentFile), filepath.Ext(s.currentFile)) + "-strip"
+	imagePath := filepath.Join(dir, base+".png")
+	infoPath := filepath.Join(dir, base+".json")
+	_, imageErr := os.Stat(imagePath)
+	_, infoErr := os.Stat(infoPath)
+	if imageErr == nil || infoErr == nil {
+		imagePath = uniquePath(imagePath)
+		infoPath = strings.TrimSuffix(imagePath, ".png") + ".json"
+	}
+
+	info, sources := buildStrip(src, filepath.Base(imagePath), s.sheet.Sprites, names, s.stripVertical, s.stripTrim, s.stripSpacing, s.alphaCutoff())
+	if info.FrameWidth == 0 || info.FrameHeight == 0 {
+		s.notify(msgStripEmpty)
+		return
+	}
+
+	width, height := info.size()
+	strip := rl.GenImageColor(int(width), int(height), rl.Blank)
+	defer rl.UnloadImage(strip)
+	for i, source := range sources {
+		dst := rl.Rectangle{Width: source.Width, Height: source.Height}
+		if info.Direction == "vertical" {
+			dst.Y = float32(int32(i) * (info.FrameHeight + info.Spacing))
+		} else {
+			dst.X = float32(int32(i) * (info.FrameWidth + info.Spacing))
+		}
+		rl.ImageDraw(strip, src, source, dst, rl.White)
+	}
+	if !rl.ExportImage(*strip, imagePath) {
+		s.notify(msgExportWriteFailed, imagePath)
+		return
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		s.notify(msgExportFailed, err)
+		return
+	}
+	if err := os.WriteFile(infoPath, data, 0o644); err != nil {
+		s.notify(msgExportFailed, err)
+		return
+	}
+	s.notify(msgWroteStrip, info.Frames, info.FrameWidth, info.FrameHeight, imagePath, s.orderName())
+}
@@ -0,0 +1,203 @@
+package viewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// subSheetPrompt is the range of cells, counted from 0 and inclusive, being
+// chosen for a sub-sheet export.
+type subSheetPrompt struct {
+	col0, col1 int32
+	row0, row1 int32
+}
+
+// subSheetInfo describes an exported sub-sheet, written next to its image.
+// Sprites lists the cells the sub-sheet holds under the names it gives them
+// when opened with the same slicing, with the name each had in the source
+// sheet and its rectangle in the new image.
+type subSheetInfo struct {
+	Image      string           `json:"image"`
+	Source     string           `json:"source"`
+	Margin     int32            `json:"margin"`
+	GridSize   int32            `json:"gridSize,omitempty"`
+	Columns    int32            `json:"columns"`
+	Rows       int32            `json:"rows"`
+	CellWidth  int32            `json:"cellWidth"`
+	CellHeight int32            `json:"cellHeight"`
+	Sprites    []subSheetSprite `json:"sprites"`
+}
+
+// subSheetSprite is one cell of an exported sub-sheet.
+type subSheetSprite struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	X      int32  `json:"x"`
+	Y      int32  `json:"y"`
+	Width  int32  `json:"width"`
+	Height int32  `json:"height"`
+}
+
+// openSubSheet asks for the range of cells to export as a sub-sheet,
+// starting from the smallest range holding the selected sprites, or the
+// whole sheet if none are selected.
+func (s *UIState) openSubSheet() {
+	if s.sheet == nil {
+		s.notify(msgNothingToExport)
+		return
+	}
+	if s.aseprite != nil || s.slicing.cols == 0 || s.slicing.rows == 0 {
+		s.notify(msgSubSheetNeedsGrid)
+		return
+	}
+	p := &subSheetPrompt{col0: s.slicing.cols - 1, row0: s.slicing.rows - 1}
+	names := s.selectedNames()
+	if len(names) == 0 {
+		p.col0, p.row0 = 0, 0
+		p.col1, p.row1 = s.slicing.cols-1, s.slicing.rows-1
+	}
+	for _, name := range names {
+		col, row := s.slicing.position(s.sheet.Sprites[name])
+		p.col0, p.col1 = min(p.col0, col), max(p.col1, col)
+		p.row0, p.row1 = min(p.row0, row), max(p.row1, row)
+	}
+	s.subSheet = p
+}
+
+// bounds returns the part of the sheet the range covers. Every cell keeps
+// the margin after it, so the sub-sheet slices into the same cells, except
+// where the sheet itself ends first.
+func (p *subSheetPrompt) bounds(g sheetSlicing, width, height int32) rl.Rectangle {
+	x, y := p.col0*(g.cellWidth+g.margin), p.row0*(g.cellHeight+g.margin)
+	w := min((p.col1-p.col0+1)*(g.cellWidth+g.margin), width-x)
+	h := min((p.row1-p.row0+1)*(g.cellHeight+g.margin), height-y)
+	return rl.Rectangle{X: float32(x), Y: float32(y), Width: float32(w), Height: float32(h)}
+}
+
+// exportSubSheet asks for a destination folder and writes the range of cells
+// there as a PNG of its own, with a JSON file listing the sprites in it and
+// a sidecar holding the slicing, so the new image opens cut into exactly
+// those cells.
+func (s *UIState) exportSubSheet(p *subSheetPrompt) {
+	dir := openDirectoryDialog()
+	if dir == "" {
+		return
+	}
+	src := rl.LoadImage(s.currentFile)
+	if !rl.IsImageValid(src) {
+		s.notify(msgExportReadFailed, filepath.Base(s.currentFile))
+		return
+	}
+	defer rl.UnloadImage(src)
+
+	base := strings.TrimSuffix(filepath.Base(s.currentFile), filepath.Ext(s.currentFile))
+	base = fmt.Sprintf("%s-r%d-%d-c%d-%d", base, p.row0, p.row1, p.col0, p.col1)
+	imagePath := filepath.Join(dir, base+".png")
+	infoPath := filepath.Join(dir, base+".json")
+	_, imageErr := os.Stat(imagePath)
+	_, infoErr := os.Stat(infoPath)
+	if imageErr == nil || infoErr == nil {
+		imagePath = uniquePath(imagePath)
+		infoPath = strings.TrimSuffix(imagePath, ".png") + ".json"
+	}
+
+	g := s.slicing
+	crop := p.bounds(g, src.Width, src.Height)
+	img := rl.ImageFromImage(*src, crop)
+	defer rl.UnloadImage(&img)
+	if !rl.ExportImage(img, imagePath) {
+		s.notify(msgExportWriteFailed, imagePath)
+		return
+	}
+
+	cols, rows := p.col1-p.col0+1, p.row1-p.row0+1
+	info := subSheetInfo{
+		Image:      filepath.Base(imagePath),
+		Source:     filepath.Base(s.currentFile),
+		Margin:     g.margin,
+		Columns:    cols,
+		Rows:       rows,
+		CellWidth:  g.cellWidth,
+		CellHeight: g.cellHeight,
+	}
+	meta := sheetMeta{Margin: g.margin, GridSize: g.cellWidth, RightToLeft: s.rightToLeft}
+	if s.sliceByCount {
+		meta.Columns, meta.Rows = cols, rows
+	} else {
+		info.GridSize = g.cellWidth
+	}
+	for row := p.row0; row <= p.row1; row++ {
+		for col := p.col0; col <= p.col1; col++ {
+			name := fmt.Sprintf("%d_%d", row, col)
+			if _, ok := s.sheet.Sprites[name]; !ok {
+				continue
+			}
+			rect := g.cell(col-p.col0, row-p.row0)
+			info.Sprites = append(info.Sprites, subSheetSprite{
+				Name:   fmt.Sprintf("%d_%d", row-p.row0, col-p.col0),
+				Source: name,
+				X:      rect.X,
+				Y:      rect.Y,
+				Width:  rect.Width,
+				Height: rect.Height,
+			})
+		}
+	}
+
+	for path, v := range map[string]any{infoPath: info, metaPath(imagePath): meta} {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			s.notify(msgExportFailed, err)
+			return
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			s.notify(msgExportFailed, err)
+			return
+		}
+	}
+	s.notify(msgWroteSubSheet, len(info.Sprites), cols, rows, imagePath)
+}
+
+// renderSubSheetPrompt draws the dialog choosing the range of cells to export
+// as a sub-sheet.
+func (s *UIState) renderSubSheetPrompt(cfg Config) rl.Rectangle {
+	p := s.subSheet
+	width := float32(320)
+	panel := rl.Rectangle{X: (float32(cfg.width) - width) / 2, Y: float32(cfg.height)/2 - 100, Width: width, Height: 190}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(tr(msgSubSheet), int32(panel.X)+10, int32(panel.Y)+10, 15, s.theme.Text)
+
+	field := func(col, row int) rl.Rectangle {
+		return rl.Rectangle{X: panel.X + 10 + float32(col)*150, Y: panel.Y + 55 + float32(row)*45, Width: 60, Height: 20}
+	}
+	lastCol, lastRow := s.slicing.cols-1, s.slicing.rows-1
+	p.col0 = s.drawInputField(field(0, 0), tr(msgFirstColumn), p.col0, 0, lastCol, 0)
+	p.col1 = s.drawInputField(field(1, 0), tr(msgLastColumn), p.col1, 0, lastCol, lastCol)
+	p.row0 = s.drawInputField(field(0, 1), tr(msgFirstRow), p.row0, 0, lastRow, 0)
+	p.row1 = s.drawInputField(field(1, 1), tr(msgLastRow), p.row1, 0, lastRow, lastRow)
+	p.col0, p.col1 = min(p.col0, p.col1), max(p.col0, p.col1)
+	p.row0, p.row1 = min(p.row0, p.row1), max(p.row0, p.row1)
+
+	crop := p.bounds(s.slicing, s.sheet.Texture.Width, s.sheet.Texture.Height)
+	size := trf(msgSubSheetSize, p.col1-p.col0+1, p.row1-p.row0+1, int32(crop.Width), int32(crop.Height))
+	drawText(size, int32(panel.X)+10, int32(panel.Y)+120, 10, s.theme.MutedText)
+
+	buttonY := panel.Y + panel.Height - 35
+	exportWidth := buttonWidth(tr(msgExport), 90)
+	cancelWidth := buttonWidth(tr(msgCancel), 90)
+	if drawButton(rl.Rectangle{X: panel.X + 10, Y: buttonY, Width: exportWidth, Height: 25}, tr(msgExport)) {
+		s.subSheet = nil
+		s.exportSubSheet(p)
+		return panel
+	}
+	if drawButton(rl.Rectangle{X: panel.X + panel.Width - 10 - cancelWidth, Y: buttonY, Width: cancelWidth, Height: 25}, tr(msgCancel)) {
+		s.subSheet = nil
+	}
+	return panel
+}
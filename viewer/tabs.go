@@ -0,0 +1,275 @@
+package viewer
+
+import (
+	"path/filepath"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Tab strip layout. The strip is only shown while more than one sheet is
+// open, so a single sheet gets the whole area.
+const (
+	tabBarHeight = 26
+	maxTabWidth  = 180
+	maxTabs      = 9
+)
+
+// tabRequestKind says what a tab request asks the viewer to do.
+type tabRequestKind int
+
+const (
+	tabOpen tabRequestKind = iota + 1
+	tabClose
+	tabSwitch
+	tabRestore
+)
+
+// tabRequest is left on a tab's state by an action that acts on the tabs
+// around it, which only the Viewer knows, and handled after input.
+type tabRequest struct {
+	kind  tabRequestKind
+	paths []string
+	index int
+	// recovery is the snapshot a restore request restores.
+	recovery *recoverySnapshot
+}
+
+// openInNewTab asks for sheets to open, each in a new tab.
+func (s *UIState) openInNewTab() {
+	if files := openFilesDialog(); len(files) > 0 {
+		s.tabRequest = &tabRequest{kind: tabOpen, paths: files}
+	}
+}
+
+// closeTab asks for the current tab to be closed.
+func (s *UIState) closeTab() {
+	s.tabRequest = &tabRequest{kind: tabClose}
+}
+
+// tabShortcuts handles Ctrl+1 to Ctrl+9, which switch to the tab with that
+// number.
+func (s *UIState) tabShortcuts() {
+	for n := 0; n < maxTabs; n++ {
+		if (binding{key: rl.KeyOne + int32(n), ctrl: true}).pressed() {
+			s.tabRequest = &tabRequest{kind: tabSwitch, index: n}
+		}
+	}
+}
+
+// tabTitle returns the label of the tab holding s: the sheet's file name,
+// marked when it has unsaved changes.
+func (s *UIState) tabTitle() string {
+	title := tr(msgNewTab)
+	if s.currentFile != "" {
+		title = filepath.Base(s.currentFile)
+	}
+	if s.dirty {
+		title += " *"
+	}
+	return title
+}
+
+// newTab returns an empty tab that shares the viewer-wide preferences of
+// the current one: font, language, text scale, tooltip delay, theme,
+// external editor, version and texture memory settings.
+// Sheet settings start from their defaults.
+func (v *Viewer) newTab() *UIState {
+	s := initUI()
+	cur := v.state
+	s.fontSource = cur.fontSource
+	s.messages = cur.messages
+	s.uiScale = cur.uiScale
+	s.tooltipDelay = cur.tooltipDelay
+	s.editor = cur.editor
+	s.version = cur.version
+	s.previewDownscale = cur.previewDownscale
+	s.textureWarnMB = cur.textureWarnMB
+	s.mipmaps = cur.mipmaps
+	s.alphaThreshold = cur.alphaThreshold
+	s.indexBase = cur.indexBase
+	s.keyTolerance = cur.keyTolerance
+	s.showPerf = cur.showPerf
+	s.verbose = cur.verbose
+	s.memory = cur.memory
+	s.setTheme(cur.highContrast, cur.colorblind)
+	s.updateFonts()
+	return s
+}
+
+// activate makes tab i current.
+func (v *Viewer) activate(i int) {
+	v.active = i
+	v.state = v.tabs[i]
+}
+
+// openTab opens the sheet at path in a new tab, which becomes current. The
+// tab is dropped again if the sheet fails to load, with the error shown in
+// the tab that asked for it.
+func (v *Viewer) openTab(path string) {
+	if len(v.tabs) >= maxTabs {
+		v.state.notify(msgTooManyTabs, maxTabs)
+		return
+	}
+	from := v.state
+	tab := v.newTab()
+	tab.openFile(path)
+	if tab.sheet == nil {
+		from.notifyText(tab.loadError)
+		if from.sheet == nil {
+			from.lastError = tab.lastError
+		}
+		tab.Close()
+		return
+	}
+	v.tabs = append(v.tabs, tab)
+	v.activate(len(v.tabs) - 1)
+}
+
+// removeTab closes tab i and releases its sheet. Closing the last tab
+// leaves an empty one in its place.
+func (v *Viewer) removeTab(i int) {
+	closed := v.tabs[i]
+	if len(v.tabs) == 1 {
+		v.tabs[0] = v.newTab()
+	} else {
+		v.tabs = append(v.tabs[:i], v.tabs[i+1:]...)
+	}
+	closed.Close()
+	if v.active > i {
+		v.active--
+	}
+	v.activate(min(v.active, len(v.tabs)-1))
+}
+
+// requestCloseTab closes tab i, asking first if it has unsaved changes.
+func (v *Viewer) requestCloseTab(i int) {
+	v.activate(i)
+	v.state.requestClose()
+	if v.state.quit {
+		v.removeTab(i)
+	}
+}
+
+// handleTabRequest carries out a tab request left by an action this frame.
+func (v *Viewer) handleTabRequest() {
+	req := v.state.tabRequest
+	v.state.tabRequest = nil
+	if req == nil {
+		return
+	}
+	switch req.kind {
+	case tabOpen:
+		for _, path := range req.paths {
+			v.openTab(path)
+		}
+	case tabClose:
+		v.requestCloseTab(v.active)
+	case tabSwitch:
+		if req.index < len(v.tabs) {
+			v.activate(req.index)
+		}
+	case tabRestore:
+		v.restoreRecovery(req.recovery)
+	}
+}
+
+// updateClosing follows up on a close that waited for the unsaved changes
+// dialog: a confirmed tab close removes the tab, and while the whole viewer
+// is closing, the next tab with unsaved changes is asked about. Cancelling
+// the dialog cancels closing the viewer, and the tabs already let go of
+// stay open.
+func (v *Viewer) updateClosing() {
+	switch {
+	case v.state.quit && v.closing:
+		v.closeNext()
+	case v.state.quit:
+		v.removeTab(v.active)
+	case v.closing && !v.state.confirmClose:
+		v.closing = false
+		for _, tab := range v.tabs {
+			tab.quit = false
+		}
+	}
+}
+
+// closeNext asks each tab in turn to close, stopping at the first one that
+// waits for the user.
+func (v *Viewer) closeNext() {
+	for i, tab := range v.tabs {
+		if tab.quit {
+			continue
+		}
+		v.activate(i)
+		tab.requestClose()
+		if !tab.quit {
+			return
+		}
+	}
+}
+
+// tabWidth returns the width of each tab in the strip.
+func (v *Viewer) tabWidth(width float32) float32 {
+	return min(maxTabWidth, (width-10)/float32(len(v.tabs)))
+}
+
+// renderTabs draws the tab strip along the top of the viewer's area, width
+// wide. Clicking a tab makes it current; its x button or a middle click
+// closes it.
+func (v *Viewer) renderTabs(width float32) {
+	theme := v.state.theme
+	rl.DrawRectangle(0, 0, int32(width), tabBarHeight, theme.Panel)
+	tabWidth := v.tabWidth(width)
+	mouse := mousePosition()
+
+	closing := -1
+	for i, tab := range v.tabs {
+		r := rl.Rectangle{X: 5 + float32(i)*tabWidth, Y: 3, Width: tabWidth - 4, Height: tabBarHeight - 3}
+		if i == v.active {
+			rl.DrawRectangleRec(r, theme.Background)
+		}
+		rl.DrawRectangleLinesEx(r, 1, theme.CellBorder)
+
+		closeBox := rl.Rectangle{X: r.X + r.Width - 18, Y: r.Y + 4, Width: 14, Height: 14}
+		over := rl.CheckCollisionPointRec(mouse, r)
+		overClose := rl.CheckCollisionPointRec(mouse, closeBox)
+		col := theme.MutedText
+		if overClose {
+			col = theme.Text
+		}
+		drawText("x", int32(closeBox.X)+4, int32(closeBox.Y)+1, 10, col)
+
+		title := elide(tab.tabTitle(), 10, r.Width-28)
+		col = theme.MutedText
+		if i == v.active {
+			col = theme.Text
+		}
+		drawText(title, int32(r.X)+6, int32(r.Y)+7, 10, col)
+
+		switch {
+		case over && rl.IsMouseButtonPressed(rl.MouseMiddleButton),
+			overClose && rl.IsMouseButtonPressed(rl.MouseLeftButton):
+			closing = i
+		case over && rl.IsMouseButtonPressed(rl.MouseLeftButton):
+			v.activate(i)
+		}
+	}
+	if closing >= 0 {
+		v.requestCloseTab(closing)
+	}
+}
+
+// elide shortens text with an ellipsis so it fits in width when drawn at
+// size.
+func elide(text string, size int32, width float32) string {
+	if float32(measureText(text, size)) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for n := len(runes) - 1; n > 0; n-- {
+		short := string(runes[:n]) + "..."
+		if float32(measureText(short, size)) <= width {
+			return short
+		}
+	}
+	return "..."
+}
@@ -0,0 +1,180 @@
+package viewer
+
+import (
+	_ "embed"
+	"image/color"
+	"math"
+	"os"
+	"runtime"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// fontSizes are the pixel sizes a custom UI font is rasterized at, before
+// DPI scaling. Text is drawn with the smallest one at least as large as
+// needed, so small labels aren't blurred by scaling a large atlas down.
+var fontSizes = []int32{12, 20, 32}
+
+// defaultUIScale is the initial text scale, in percent.
+const defaultUIScale int32 = 100
+
+// textStyle is the font and scale text is drawn with.
+type textStyle struct {
+	// fonts holds the custom font at each of fontSizes. When empty, raylib's
+	// built-in font is used.
+	fonts []rl.Font
+	scale float32
+}
+
+// uiText is the style of the viewer currently drawing. Like mouseOrigin it is
+// set for the duration of Update and Draw.
+var uiText = textStyle{scale: 1}
+
+// fontCodepoints are the characters rasterized from a custom font: Latin-1,
+// which covers the translated strings as well as raylib's built-in font does.
+var fontCodepoints = func() []rune {
+	var runes []rune
+	for r := rune(32); r < 256; r++ {
+		if r < 127 || r >= 160 {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}()
+
+// fontSource is where the UI font comes from: TTF or OTF data held in
+// memory, or a font file.
+type fontSource struct {
+	data []byte
+	path string
+}
+
+// bundledFont is Fira Sans, licensed under the SIL Open Font License (see
+// fonts/OFL.txt). It is used when no system font is found, or the chosen
+// font can't be loaded, so text isn't left to raylib's built-in font.
+//
+//go:embed fonts/FiraSans-Regular.ttf
+var bundledFont []byte
+
+// systemFonts lists common sans-serif fonts, by operating system, that are
+// used when the host doesn't provide a font.
+var systemFonts = map[string][]string{
+	"windows": {`C:\Windows\Fonts\segoeui.ttf`, `C:\Windows\Fonts\arial.ttf`},
+	"darwin":  {"/System/Library/Fonts/Supplemental/Arial.ttf", "/Library/Fonts/Arial.ttf"},
+	"linux": {
+		"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+		"/usr/share/fonts/dejavu/DejaVuSans.ttf",
+		"/usr/share/fonts/TTF/DejaVuSans.ttf",
+		"/usr/share/fonts/truetype/liberation/LiberationSans-Regular.ttf",
+	},
+}
+
+// systemFont returns the first of systemFonts installed on this machine, or
+// the bundled font if none is.
+func systemFont() fontSource {
+	for _, path := range systemFonts[runtime.GOOS] {
+		if _, err := os.Stat(path); err == nil {
+			return fontSource{path: path}
+		}
+	}
+	return fontSource{data: bundledFont}
+}
+
+// load rasterizes the font at size pixels.
+func (f fontSource) load(size int32) rl.Font {
+	if f.data != nil {
+		return rl.LoadFontFromMemory(".ttf", f.data, size, fontCodepoints)
+	}
+	return rl.LoadFontEx(f.path, size, fontCodepoints)
+}
+
+// windowDPI returns the DPI scale of the monitor the window is on.
+func windowDPI() float32 {
+	dpi := rl.GetWindowScaleDPI().X
+	if dpi <= 0 {
+		return 1
+	}
+	return dpi
+}
+
+// loadFonts rasterizes the font at each of fontSizes, multiplied by the DPI
+// scale so glyphs map one to one onto physical pixels. A font that can't be
+// loaded is replaced by the bundled one. It returns nil, leaving text to
+// raylib's built-in font, only if that can't be loaded either.
+func loadFonts(src fontSource, dpi float32) []rl.Font {
+	if fonts := loadFontSizes(src, dpi); fonts != nil {
+		return fonts
+	}
+	return loadFontSizes(fontSource{data: bundledFont}, dpi)
+}
+
+// loadFontSizes rasterizes src at each of fontSizes for loadFonts, or
+// returns nil if it can't be loaded.
+func loadFontSizes(src fontSource, dpi float32) []rl.Font {
+	if src.data == nil && src.path == "" {
+		return nil
+	}
+	var fonts []rl.Font
+	for _, size := range fontSizes {
+		font := src.load(int32(math.Round(float64(float32(size) * dpi))))
+		// raylib hands back its built-in font when a file can't be read.
+		if font.Texture.ID == 0 || font.Texture.ID == rl.GetFontDefault().Texture.ID {
+			unloadFonts(fonts)
+			return nil
+		}
+		rl.SetTextureFilter(font.Texture, rl.FilterBilinear)
+		fonts = append(fonts, font)
+	}
+	return fonts
+}
+
+// unloadFonts releases fonts returned by loadFonts.
+func unloadFonts(fonts []rl.Font) {
+	for _, font := range fonts {
+		rl.UnloadFont(font)
+	}
+}
+
+// font returns the font to draw text of the given pixel size with, and the
+// spacing between its characters.
+func (t textStyle) font(px float32) (rl.Font, float32) {
+	if len(t.fonts) == 0 {
+		// The built-in font is 10px tall and spaced one pixel per 10px.
+		return rl.GetFontDefault(), px / 10
+	}
+	for i, size := range fontSizes {
+		if float32(size) >= px {
+			return t.fonts[i], 0
+		}
+	}
+	return t.fonts[len(t.fonts)-1], 0
+}
+
+// drawText draws text at x, y with the UI font, size scaled by the UI scale.
+// It takes the same arguments as rl.DrawText and replaces it everywhere in
+// the viewer.
+func drawText(text string, x, y, size int32, col color.RGBA) {
+	px := float32(size) * uiText.scale
+	font, spacing := uiText.font(px)
+	rl.DrawTextEx(font, text, rl.Vector2{X: float32(x), Y: float32(y)}, px, spacing, col)
+}
+
+// measureText returns the width of text as drawn by drawText.
+func measureText(text string, size int32) int32 {
+	px := float32(size) * uiText.scale
+	font, spacing := uiText.font(px)
+	return int32(rl.MeasureTextEx(font, text, px, spacing).X)
+}
+
+// updateFonts rasterizes the UI font for the DPI scale of the window's
+// monitor, and again whenever the window moves to a monitor with a different
+// scale.
+func (s *UIState) updateFonts() {
+	dpi := windowDPI()
+	if dpi == s.fontDPI {
+		return
+	}
+	unloadFonts(s.fonts)
+	s.fonts = loadFonts(s.fontSource, dpi)
+	s.fontDPI = dpi
+}
@@ -0,0 +1,219 @@
+package viewer
+
+import (
+	"image/color"
+	"path/filepath"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// defaultTextureWarnMB is the size, in megabytes, above which loading a
+// sheet's texture is warned about.
+const defaultTextureWarnMB = 256
+
+// previewDownscales are the factors the preview texture can be reduced by.
+var previewDownscales = []int32{1, 2, 4}
+
+// textureBytes estimates the GPU memory a texture takes: four bytes per
+// pixel, as drivers store most formats, for each of its mipmap levels.
+func textureBytes(width, height, mipmaps int32) int64 {
+	var total int64
+	for level := int32(0); level < max(mipmaps, 1); level++ {
+		total += int64(max(width>>level, 1)) * int64(max(height>>level, 1)) * 4
+	}
+	return total
+}
+
+// textureMemory returns the GPU memory taken by the current sheet: the sheet
+// itself or its reduced preview, plus the recolored copy if there is one.
+func (s *UIState) textureMemory() int64 {
+	if s.sheet == nil {
+		return 0
+	}
+	var total int64
+	for _, tex := range []rl.Texture2D{s.sheet.Texture, s.preview, s.gammaTex, s.recolor.texture(), s.bgKey.texture()} {
+		if tex.ID != 0 {
+			total += textureBytes(tex.Width, tex.Height, tex.Mipmaps)
+		}
+	}
+	return total
+}
+
+// drawTexture returns the texture thumbnails are drawn from: the copy with
+// the background keyed out, then the recolored copy while one is shown, then
+// the gamma corrected one, then the reduced preview if the sheet is
+// downscaled, otherwise the sheet itself.
+func (s *UIState) drawTexture() rl.Texture2D {
+	if s.bgKey != nil && s.bgKey.tex.ID != 0 {
+		return s.bgKey.tex
+	}
+	if s.recolor != nil && !s.recolor.before && s.recolor.tex.ID != 0 {
+		return s.recolor.tex
+	}
+	if s.gammaTex.ID != 0 {
+		return s.gammaTex
+	}
+	if s.preview.ID != 0 {
+		return s.preview
+	}
+	return s.sheet.Texture
+}
+
+// texSource maps a rectangle in sheet pixels to the texture returned by
+// drawTexture. Everything else, from the grid to exported metadata, keeps
+// working in sheet pixels.
+func (s *UIState) texSource(r rl.Rectangle) rl.Rectangle {
+	tex := s.drawTexture()
+	if tex.Width == s.sheet.Texture.Width && tex.Height == s.sheet.Texture.Height {
+		return r
+	}
+	fx := float32(tex.Width) / float32(s.sheet.Texture.Width)
+	fy := float32(tex.Height) / float32(s.sheet.Texture.Height)
+	return rl.Rectangle{X: r.X * fx, Y: r.Y * fy, Width: r.Width * fx, Height: r.Height * fy}
+}
+
+// prepareTexture runs after a sheet was loaded. It warns when the upload
+// was larger than the warning limit, once per file rather than on every
+// reslice, and with a downscale set, replaces the sheet's texture by a
+// reduced copy. The sheet keeps its full width and height, so coordinates
+// stay in sheet pixels.
+func (s *UIState) prepareTexture() {
+	s.unloadPreview()
+	tex := s.sheet.Texture
+	bytes := textureBytes(tex.Width, tex.Height, tex.Mipmaps)
+	if bytes > int64(s.textureWarnMB)<<20 && s.warnedTexture != s.currentFile {
+		s.warnedTexture = s.currentFile
+		s.notify(msgLargeTexture, filepath.Base(s.currentFile), formatBytes(bytes), s.textureWarnMB)
+	}
+	if s.previewDownscale > 1 {
+		s.loadPreview()
+	}
+	s.mipmapTime = 0
+	if s.mipmaps {
+		s.generateMipmaps()
+	}
+}
+
+// loadPreview replaces the sheet's texture by a copy reduced by the preview
+// downscale.
+func (s *UIState) loadPreview() {
+	tex := s.sheet.Texture
+	img := rl.LoadImage(s.currentFile)
+	if !rl.IsImageValid(img) {
+		return
+	}
+	defer rl.UnloadImage(img)
+	rl.ImageResize(img, max(tex.Width/s.previewDownscale, 1), max(tex.Height/s.previewDownscale, 1))
+	s.preview = rl.LoadTextureFromImage(img)
+	s.perf.uploads++
+
+	// The full-size texture is no longer needed on the GPU. The sheet is
+	// marked unloaded so the resource manager doesn't release it again.
+	rl.UnloadTexture(s.sheet.Texture)
+	s.sheet.Texture.ID = 0
+	s.sheet.Loaded = false
+}
+
+// generateMipmaps builds mipmaps for the texture thumbnails are drawn from,
+// so a large sheet shown small is averaged instead of shimmering. Only
+// minification uses them: magnified views such as the inspector and the
+// magnifier keep sharp, nearest-neighbor pixels. How long it took is kept
+// for the sheet info, as it can be slow on weak GPUs.
+func (s *UIState) generateMipmaps() {
+	start := time.Now()
+	tex := &s.sheet.Texture
+	if s.preview.ID != 0 {
+		tex = &s.preview
+	}
+	mipmap(tex)
+	s.mipmapTime = time.Since(start)
+}
+
+// uploadPixels uploads a modified copy of the sheet's pixels, reduced like
+// the preview when the sheet is downscaled and mipmapped like the sheet.
+func (s *UIState) uploadPixels(pix []color.RGBA, width, height int32) rl.Texture2D {
+	img := rl.NewImage(colorBytes(pix), width, height, 1, rl.UncompressedR8g8b8a8)
+	var tex rl.Texture2D
+	if s.previewDownscale > 1 {
+		// raylib frees the pixels of a resized image, so it works on a copy
+		// it allocated itself.
+		small := rl.ImageCopy(img)
+		rl.ImageResize(small, max(width/s.previewDownscale, 1), max(height/s.previewDownscale, 1))
+		tex = rl.LoadTextureFromImage(small)
+		rl.UnloadImage(small)
+	} else {
+		tex = rl.LoadTextureFromImage(img)
+	}
+	if s.mipmaps {
+		mipmap(&tex)
+	}
+	s.perf.uploads++
+	return tex
+}
+
+// mipmap builds the mipmaps of tex and has only minification use them.
+func mipmap(tex *rl.Texture2D) {
+	rl.GenTextureMipmaps(tex)
+	rl.TextureParameters(tex.ID, rl.TextureMinFilter, rl.TextureFilterMipLinear)
+	rl.TextureParameters(tex.ID, rl.TextureMagFilter, rl.TextureFilterNearest)
+}
+
+// toggleMipmaps turns mipmapped thumbnails on or off. The sheet is reloaded
+// either way, as mipmaps can't be taken off a texture.
+func (s *UIState) toggleMipmaps() {
+	s.mipmaps = !s.mipmaps
+	if s.sheet == nil || !s.reload() {
+		return
+	}
+	if s.mipmaps {
+		s.notify(msgMipmapsOn, s.mipmapTime.Milliseconds())
+	} else {
+		s.notify(msgMipmapsOff)
+	}
+}
+
+// unloadPreview releases the reduced preview texture, if any.
+func (s *UIState) unloadPreview() {
+	if s.preview.ID != 0 {
+		rl.UnloadTexture(s.preview)
+		s.preview = rl.Texture2D{}
+	}
+}
+
+// cyclePreviewDownscale switches to the next preview downscale and reloads
+// the sheet with it.
+func (s *UIState) cyclePreviewDownscale() {
+	next := previewDownscales[0]
+	for i, f := range previewDownscales {
+		if f == s.previewDownscale && i+1 < len(previewDownscales) {
+			next = previewDownscales[i+1]
+		}
+	}
+	s.previewDownscale = next
+	if s.sheet != nil {
+		s.reload()
+	}
+	if next == 1 {
+		s.notify(msgPreviewFullSize)
+	} else {
+		s.notify(msgPreviewDownscale, next)
+	}
+}
+
+// memoryStatus returns the status bar text for texture memory: the current
+// sheet's, and the total across tabs when there are several.
+func (s *UIState) memoryStatus() string {
+	mem := s.textureMemory()
+	if mem == 0 {
+		return ""
+	}
+	text := trf(msgTextureMemory, formatBytes(mem))
+	if s.previewDownscale > 1 {
+		text += trf(msgDownscaled, s.previewDownscale)
+	}
+	if s.tabCount > 1 {
+		text += trf(msgTextureMemoryTabs, formatBytes(s.tabsMemory), s.tabCount)
+	}
+	return text
+}
@@ -0,0 +1,134 @@
+package viewer
+
+import (
+	"image/color"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Theme holds the colors used to draw the viewer.
+type Theme struct {
+	Background color.RGBA
+	Panel      color.RGBA
+	Text       color.RGBA
+	MutedText  color.RGBA
+	CellBorder color.RGBA
+	Error      color.RGBA
+	// Warning marks problems the viewer recovered from, such as a failed
+	// reload that left the last good sheet on screen.
+	Warning color.RGBA
+	// Stripe tints the cell backgrounds of alternate rows when zebra
+	// striping is on.
+	Stripe color.RGBA
+	// SelectionHalo is drawn just outside the selection outline so the
+	// accent color stays readable over both light and dark pixels.
+	SelectionHalo color.RGBA
+	// Duplicate outlines sprites that have an identical twin elsewhere in
+	// the sheet while the sheet info is open.
+	Duplicate color.RGBA
+	// Mirror outlines sprites that are the horizontal flip of another one,
+	// likewise.
+	Mirror color.RGBA
+	// DiffChanged and DiffRemoved mark the two groups of a comparison:
+	// sprites whose pixels changed, and sprites the other image doesn't
+	// have.
+	DiffChanged color.RGBA
+	DiffRemoved color.RGBA
+	// Playing outlines the sprite the animation preview is showing.
+	Playing color.RGBA
+	// Accents are the selection outline colors the user can cycle through.
+	Accents []color.RGBA
+}
+
+var lightTheme = Theme{
+	Background:    rl.RayWhite,
+	Panel:         rl.LightGray,
+	Text:          rl.Black,
+	MutedText:     rl.DarkGray,
+	CellBorder:    rl.Gray,
+	Error:         rl.Red,
+	Warning:       rl.Orange,
+	Stripe:        color.RGBA{R: 200, G: 200, B: 200, A: 90},
+	SelectionHalo: rl.Black,
+	Duplicate:     rl.Purple,
+	Mirror:        rl.DarkGreen,
+	DiffChanged:   rl.Red,
+	DiffRemoved:   rl.Blue,
+	Playing:       color.RGBA{R: 0, G: 170, B: 200, A: 255},
+	Accents:       []color.RGBA{rl.Orange, rl.Blue, rl.Magenta, rl.Lime, rl.Gold},
+}
+
+// highContrastTheme keeps the light layout but drops the mid grays, so text,
+// borders and markers stand out at full contrast.
+var highContrastTheme = Theme{
+	Background:    rl.White,
+	Panel:         color.RGBA{R: 235, G: 235, B: 235, A: 255},
+	Text:          rl.Black,
+	MutedText:     rl.Black,
+	CellBorder:    rl.Black,
+	Error:         color.RGBA{R: 200, G: 0, B: 0, A: 255},
+	Warning:       color.RGBA{R: 170, G: 85, B: 0, A: 255},
+	Stripe:        color.RGBA{R: 0, G: 0, B: 0, A: 40},
+	SelectionHalo: rl.White,
+	Duplicate:     color.RGBA{R: 110, G: 0, B: 160, A: 255},
+	Mirror:        color.RGBA{R: 0, G: 110, B: 0, A: 255},
+	DiffChanged:   color.RGBA{R: 200, G: 0, B: 0, A: 255},
+	DiffRemoved:   color.RGBA{R: 0, G: 0, B: 200, A: 255},
+	Playing:       color.RGBA{R: 0, G: 120, B: 150, A: 255},
+	Accents: []color.RGBA{
+		{R: 0, G: 0, B: 255, A: 255},
+		{R: 255, G: 0, B: 255, A: 255},
+		{R: 0, G: 0, B: 0, A: 255},
+		{R: 255, G: 110, B: 0, A: 255},
+	},
+}
+
+// Colors of the Okabe-Ito palette, which stay distinct from one another for
+// the common forms of color blindness.
+var (
+	okabeOrange    = color.RGBA{R: 230, G: 159, B: 0, A: 255}
+	okabeSkyBlue   = color.RGBA{R: 86, G: 180, B: 233, A: 255}
+	okabeGreen     = color.RGBA{R: 0, G: 158, B: 115, A: 255}
+	okabeYellow    = color.RGBA{R: 240, G: 228, B: 66, A: 255}
+	okabeBlue      = color.RGBA{R: 0, G: 114, B: 178, A: 255}
+	okabeVermilion = color.RGBA{R: 213, G: 94, B: 0, A: 255}
+	okabePurple    = color.RGBA{R: 204, G: 121, B: 167, A: 255}
+)
+
+// colorblindSafe returns t with every semantic color taken from the
+// Okabe-Ito palette, so no two meanings rely on telling red from green.
+func (t Theme) colorblindSafe() Theme {
+	t.Error = okabeVermilion
+	t.Warning = okabeOrange
+	t.Duplicate = okabePurple
+	t.Mirror = okabeGreen
+	t.DiffChanged = okabeVermilion
+	t.DiffRemoved = okabeBlue
+	t.Playing = okabeSkyBlue
+	t.Accents = []color.RGBA{okabeOrange, okabeSkyBlue, okabeGreen, okabeYellow, okabeBlue, okabePurple}
+	return t
+}
+
+// themeFor returns the theme for the accessibility options.
+func themeFor(highContrast, colorblind bool) *Theme {
+	t := lightTheme
+	if highContrast {
+		t = highContrastTheme
+	}
+	if colorblind {
+		t = t.colorblindSafe()
+	}
+	return &t
+}
+
+// setTheme switches the accessibility options and the theme they select.
+func (s *UIState) setTheme(highContrast, colorblind bool) {
+	s.highContrast, s.colorblind = highContrast, colorblind
+	s.theme = themeFor(highContrast, colorblind)
+}
+
+// selectionColor returns the accent color currently used for selection
+// outlines.
+func (s *UIState) selectionColor() color.RGBA {
+	return s.theme.Accents[s.selectionAccent%len(s.theme.Accents)]
+}
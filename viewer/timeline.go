@@ -0,0 +1,152 @@
+package viewer
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Frame durations are edited in milliseconds, within these limits.
+const (
+	minFrameMS     int32 = 10
+	maxFrameMS     int32 = 10000
+	defaultFrameMS int32 = 100
+)
+
+// timelineRowHeight is the vertical space one frame takes in the duration
+// column, its label included. The column is up to timelineHeight tall.
+const (
+	timelineRowHeight = 34
+	timelineHeight    = 420
+)
+
+// frameDuration returns how long, in milliseconds, the named sprite is shown
+// in the animation preview: the duration set for it, or else the one from
+// the Aseprite sidecar. It reports false if the sprite has neither and plays
+// at the preview's FPS.
+func (s *UIState) frameDuration(name string) (int32, bool) {
+	if ms, ok := s.durations[name]; ok {
+		return ms, true
+	}
+	return s.asepriteDuration(name)
+}
+
+// setDurations replaces the per-frame durations.
+func (s *UIState) setDurations(durations map[string]int32) {
+	s.durations = durations
+	s.dirty = true
+}
+
+// durationsEdit records a change of the per-frame durations.
+func durationsEdit(desc string, from, to map[string]int32) edit {
+	return edit{
+		desc: desc,
+		undo: func(s *UIState) { s.setDurations(from) },
+		redo: func(s *UIState) { s.setDurations(to) },
+	}
+}
+
+// changeDurations applies change to a copy of the durations and records it.
+func (s *UIState) changeDurations(desc string, change func(durations map[string]int32)) {
+	prev := s.durations
+	next := make(map[string]int32, len(prev))
+	for name, ms := range prev {
+		next[name] = ms
+	}
+	change(next)
+	s.setDurations(next)
+	s.record(durationsEdit(desc, prev, next))
+}
+
+// renderTimeline draws the duration column next to the animation panel: a
+// field per frame of the preview range and a "set all" field that gives
+// every frame in the range the same duration. The column pages through long
+// ranges, following the current frame while playing, and the loop markers
+// show as a bar along the frames they loop over. It shares its bottom edge
+// with the animation panel.
+func (s *UIState) renderTimeline(cfg Config, anim rl.Rectangle) {
+	bottom := anim.Y + anim.Height
+	height := max(min(timelineHeight, bottom-float32(cfg.startY)-10), anim.Height)
+	panel := rl.Rectangle{X: anim.X - 160, Y: bottom - height, Width: 150, Height: height}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(tr(msgDurations), int32(panel.X)+10, int32(panel.Y)+8, 15, s.theme.Text)
+
+	x := panel.X + 10
+	s.anim.setAll = s.drawInputField(rl.Rectangle{X: x, Y: panel.Y + 50, Width: 60, Height: 20}, tr(msgAllFrames), s.anim.setAll, minFrameMS, maxFrameMS, defaultFrameMS)
+	if drawButton(rl.Rectangle{X: x + 65, Y: panel.Y + 50, Width: panel.Width - 85, Height: 20}, tr(msgSetAll)) {
+		ms := s.anim.setAll
+		s.changeDurations(trf(msgSetAllDurations, ms), func(d map[string]int32) {
+			for i := s.anim.start; i <= s.anim.end; i++ {
+				d[s.spriteNames[i]] = ms
+			}
+		})
+	}
+
+	top := panel.Y + 100
+	perPage := max(int32((panel.Y+panel.Height-30-top)/timelineRowHeight), 1)
+	pages := (s.anim.length() + perPage - 1) / perPage
+	if s.anim.playing {
+		s.anim.page = (s.anim.frame - s.anim.start) / perPage
+	}
+	s.anim.page = max(0, min(s.anim.page, pages-1))
+
+	first := s.anim.start + s.anim.page*perPage
+	for i := first; i <= s.anim.end && i < first+perPage; i++ {
+		name := s.spriteNames[i]
+		field := rl.Rectangle{X: x + 10, Y: top + float32(i-first)*timelineRowHeight, Width: 60, Height: 20}
+		if loopFirst, loopLast := s.anim.playRange(); s.anim.looping() && i >= loopFirst && i <= loopLast {
+			bar := rl.Rectangle{X: panel.X + 3, Y: field.Y - 7, Width: 3, Height: timelineRowHeight}
+			if i == loopFirst {
+				bar.Y, bar.Height = field.Y, bar.Height-7
+			}
+			if i == loopLast {
+				bar.Height = field.Y + field.Height - bar.Y
+			}
+			rl.DrawRectangleRec(bar, s.selectionColor())
+		}
+		if i == s.anim.frame {
+			rl.DrawTriangle(
+				rl.Vector2{X: x + 6, Y: field.Y + 10},
+				rl.Vector2{X: x, Y: field.Y + 14},
+				rl.Vector2{X: x, Y: field.Y + 6},
+				s.selectionColor())
+		}
+
+		ms, ok := s.frameDuration(name)
+		def := int32(1000 / s.anim.fps)
+		if aseMS, fromAse := s.asepriteDuration(name); fromAse {
+			def = aseMS
+		}
+		if !ok {
+			ms = def
+		}
+		label := fmt.Sprintf("%d %s", i, name)
+		if next := s.drawInputField(field, label, ms, minFrameMS, maxFrameMS, def); next != ms {
+			s.changeDurations(trf(msgFrameDuration, name), func(d map[string]int32) { d[name] = next })
+		}
+		drawText(tr(msgMS), int32(field.X+field.Width)+4, int32(field.Y)+5, 10, s.theme.MutedText)
+	}
+
+	if pages > 1 {
+		y := panel.Y + panel.Height - 28
+		if drawButton(rl.Rectangle{X: x, Y: y, Width: 24, Height: 20}, "<") {
+			s.anim.page--
+		}
+		if drawButton(rl.Rectangle{X: panel.X + panel.Width - 34, Y: y, Width: 24, Height: 20}, ">") {
+			s.anim.page++
+		}
+		pageText := fmt.Sprintf("%d/%d", s.anim.page+1, pages)
+		drawText(pageText, int32(panel.X+panel.Width/2)-measureText(pageText, 10)/2, int32(y)+5, 10, s.theme.MutedText)
+	}
+}
+
+// asepriteDuration returns the duration the Aseprite sidecar gives the named
+// frame, if any.
+func (s *UIState) asepriteDuration(name string) (int32, bool) {
+	if s.aseprite == nil {
+		return 0, false
+	}
+	ms, ok := s.aseprite.durations[name]
+	return ms, ok
+}
@@ -0,0 +1,50 @@
+package viewer
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// toastDuration is how long a notification stays on screen, in seconds.
+const toastDuration = 3.0
+
+// toast is a short-lived notification drawn above the status bar.
+type toast struct {
+	message string
+	expires float64
+}
+
+// notify queues a toast with the message id in the current language,
+// formatted with args.
+func (s *UIState) notify(id msgID, args ...any) {
+	s.notifyText(trf(id, args...))
+}
+
+// notifyText queues a toast with a message that is already translated.
+func (s *UIState) notifyText(message string) {
+	s.toasts = append(s.toasts, toast{
+		message: message,
+		expires: rl.GetTime() + toastDuration,
+	})
+}
+
+// renderToasts drops expired notifications and draws the remaining ones,
+// newest at the bottom, stacked upwards from the status bar.
+func (s *UIState) renderToasts(cfg Config) {
+	now := rl.GetTime()
+	live := s.toasts[:0]
+	for _, t := range s.toasts {
+		if t.expires > now {
+			live = append(live, t)
+		}
+	}
+	s.toasts = live
+
+	y := cfg.startY + cfg.viewportHeight - 30
+	for i := len(s.toasts) - 1; i >= 0; i-- {
+		width := measureText(s.toasts[i].message, 10) + 20
+		x := cfg.width - width - 10
+		rl.DrawRectangle(x, y, width, 24, rl.ColorAlpha(rl.Black, 0.75))
+		drawText(s.toasts[i].message, x+10, y+7, 10, rl.White)
+		y -= 28
+	}
+}
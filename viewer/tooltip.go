@@ -0,0 +1,60 @@
+package viewer
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// defaultTooltipDelay is how long, in seconds, the mouse has to rest on a
+// thumbnail before its tooltip appears.
+const defaultTooltipDelay = 0.4
+
+// hoverTimer measures how long the mouse has rested on the same thumbnail.
+type hoverTimer struct {
+	cell    int
+	elapsed float32
+}
+
+// update restarts the timer when the hovered cell changes and otherwise
+// accumulates the frame time.
+func (h *hoverTimer) update(cell int) {
+	if cell != h.cell {
+		h.cell, h.elapsed = cell, 0
+		return
+	}
+	h.elapsed += rl.GetFrameTime()
+}
+
+// renderTooltip draws the tooltip of the hovered thumbnail once the hover
+// delay has passed. It is drawn last so it sits on top of the panels.
+func (s *UIState) renderTooltip(cfg Config) {
+	i := s.hover.cell
+	if i < 0 || i >= len(s.spriteNames) || s.hover.elapsed < s.tooltipDelay || s.drag.active || s.band.active {
+		return
+	}
+
+	name := s.spriteNames[i]
+	rect := s.sheet.Sprites[name]
+	lines := []string{name, trf(msgTooltipIndex, s.indices[name]), trf(msgTooltipRect, s.coord(rect.Width), s.coord(rect.Height), s.coord(rect.X), s.coord(rect.Y))}
+	if _, other := s.report.mirror(name); other != "" {
+		lines = append(lines, trf(msgMirrorOf, other))
+	}
+
+	width := int32(0)
+	for _, line := range lines {
+		width = max(width, measureText(line, 10))
+	}
+	width += 12
+	height := int32(len(lines))*14 + 8
+
+	mouse := mousePosition()
+	x, y := int32(mouse.X)+14, int32(mouse.Y)+18
+	if x+width > cfg.width {
+		x = int32(mouse.X) - width - 4
+	}
+	if y+height > cfg.height {
+		y = int32(mouse.Y) - height - 4
+	}
+
+	rl.DrawRectangle(x, y, width, height, rl.ColorAlpha(rl.Black, 0.8))
+	for j, line := range lines {
+		drawText(line, x+6, y+5+int32(j)*14, 10, rl.White)
+	}
+}
@@ -0,0 +1,1935 @@
+package viewer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/beam/resources"
+)
+
+// viewMode selects how thumbnails are laid out in the viewport.
+type viewMode int
+
+const (
+	// gridView wraps thumbnails into rows that fit the window width.
+	gridView viewMode = iota
+	// stripView lays every frame out in a single horizontally scrolling row.
+	stripView
+	// sheetView shows the whole sheet with the slicing grid drawn over it.
+	sheetView
+	// nativeView draws every sprite at its own pixel size, without scaling,
+	// in rows that wrap at the window width.
+	nativeView
+)
+
+// Default values of the user-adjustable settings.
+const (
+	defaultMargin             int32 = 1
+	defaultGridSize           int32 = 16
+	defaultSelectionThickness int32 = 2
+	defaultExportScale        int32 = 1
+)
+
+// gridSizeLimit is the largest grid size the settings panel offers. A loaded
+// sheet smaller than that caps it further, to what still fits a cell.
+const gridSizeLimit int32 = 64
+
+// UIState holds the application state and configuration.
+type UIState struct {
+	showFileDialog bool
+	margin         int32
+	gridSize       int32
+	gridCap        int32
+	sliceByCount   bool
+	columns        int32
+	rows           int32
+	slicing        sheetSlicing
+	reloadAt       float64
+	currentFile    string
+	rm             *resources.ResourceManager
+	sheet          *resources.SpriteSheet
+	spriteNames    []string
+	sections       []spriteSection
+	collapsed      map[string]bool
+	scrollOffset   float32
+	scrollOffsetX  float32
+	viewMode       viewMode
+	loadError      string
+	lastError      *errorReport
+	showAbout      bool
+	showPerf       bool
+	perf           frameStats
+	names          nameCache
+	native         nativeLayout
+	loadTimes      loadTimes
+	verbose        bool
+	memory         *sheetMemory
+	version        string
+	debugInfo      string
+	toasts         []toast
+	export         *exportJob
+	watch          *folderWatch
+	tabRequest     *tabRequest
+	usages         *usageReport
+	histogram      []sizeBucket
+	filter         *spriteFilter
+	editor         string
+	hexCoords      bool
+	preview        rl.Texture2D
+	warnedTexture  string
+	warnedGamma    string
+	tabsMemory     int64
+	tabCount       int
+	editWatch      *editWatch
+	exportPrompt   *exportPrompt
+	subSheet       *subSheetPrompt
+	rename         *renamePrompt
+	theme          *Theme
+	selected       map[string]bool
+	history        history
+	dirty          bool
+	confirmClose   bool
+	recovery       *recoverySnapshot
+	recoveryLater  bool
+	showSettings   bool
+	settingsShown  float32
+	settingsScroll float32
+	quit           bool
+	widgets        widgetState
+	layers         layerStack
+
+	selectionAccent    int
+	selectionThickness int32
+	exportScale        int32
+	exportNaming       ExportNaming
+	fontSpacing        int32
+	fontBaseline       int32
+	atlasTrim          bool
+	stripSpacing       int32
+	stripVertical      bool
+	stripTrim          bool
+	contactLabels      bool
+	contactGrid        bool
+	alphaTest          bool
+	silhouette         bool
+	silhouetteInk      int
+	silhouettePaper    int
+	measureGrid        bool
+	measurePitch       int32
+	measureInk         int
+	alphaThreshold     int32
+	zebra              bool
+	labelOverlay       bool
+	thumbAspect        float32
+	presets            [2]*slicingPreset
+	slicingLocked      bool
+	rightToLeft        bool
+	reveal             string
+	pinned             []string
+	indexBase          indexBase
+	indices            map[string]int
+	showCellCheck      bool
+	cells              *cellCheck
+	dragOutDir         string
+	trueSize           bool
+	snapRows           bool
+	groupPrefix        bool
+	highContrast       bool
+	colorblind         bool
+	uiScale            int32
+	fonts              []rl.Font
+	fontSource         fontSource
+	fontDPI            float32
+	messages           catalog
+	alphaShader        rl.Shader
+	silhouetteShader   rl.Shader
+	gammaCorrect       bool
+	gammaTex           rl.Texture2D
+	bgKey              *backgroundKey
+	keyTolerance       int32
+	pixels             *sheetPixels
+	aseprite           *asepriteSheet
+	asepriteErr        string
+	contentSizes       map[string]atlasRect
+	fileInfo           fileInfo
+	fileInfoErr        error
+	diff               *sheetDiff
+	recolor            *recolorPreview
+	report             *sheetReport
+	order              []string
+	durations          map[string]int32
+	renames            map[string]string
+	drag               spriteDrag
+	band               selectionBand
+	palette            *commandPalette
+	hover              hoverTimer
+	tooltipDelay       float32
+	anim               animation
+	inspect            inspector
+	lensZoom           int32
+	previewDownscale   int32
+	mipmaps            bool
+	mipmapTime         time.Duration
+	textureWarnMB      int32
+}
+
+// Config holds the layout of the viewer. width and height are the size of the
+// area the viewer draws into; every other position is relative to its top-left.
+type Config struct {
+	width          int32
+	height         int32
+	displaySize    int32
+	padding        int32
+	startX         int32
+	startY         int32
+	viewportHeight int32
+	headerHeight   int32
+	labelFontSize  int32
+	labelLines     int32
+	// labelOverlay draws sprite names over the thumbnails instead of below
+	// them, so rows need no room for a label.
+	labelOverlay bool
+	// cellAspect is the width to height ratio of the sheet's cells, which
+	// thumbnail cells keep. Zero means square.
+	cellAspect float32
+}
+
+// labelGap is the space kept above and below the sprite name under each
+// thumbnail.
+const labelGap = 4
+
+// reload attempts to load or reload the current sprite sheet with the specified
+// margin and grid size settings. It updates the internal state with any errors
+// or debug information. The new sheet is only swapped in once it has loaded
+// successfully and the old one is released after the swap, so a failed reload
+// keeps the last good sheet on screen and reports the error with a toast.
+func (s *UIState) reload() bool {
+	s.reloadAt = 0
+	if s.currentFile == "" {
+		return false
+	}
+
+	var times loadTimes
+	start := time.Now()
+	rm, sheet, err := loadSheet(s.currentFile, s.margin, s.gridSize)
+	times.load = time.Since(start)
+	start = time.Now()
+	var slicing sheetSlicing
+	var ase *asepriteSheet
+	if err == nil {
+		ase = s.loadAsepriteFor(sheet)
+		if ase != nil {
+			// Aseprite names every frame, so the slicing settings don't
+			// apply.
+			sheet.Sprites = ase.sprites
+			slicing, _ = s.slicingFor(sheet.Texture.Width, sheet.Texture.Height)
+		} else {
+			slicing, err = s.resliceSheet(sheet)
+		}
+		times.slicing = time.Since(start)
+		if err == nil && len(sheet.Sprites) == 0 {
+			err = errors.New(tr(msgNoSprites))
+		}
+		if err != nil {
+			rm.Close()
+		}
+	}
+	if err != nil {
+		s.loadFailed(err)
+		return false
+	}
+
+	if s.rm != nil {
+		s.rm.Close()
+	}
+	s.rm = rm
+	s.sheet = sheet
+	s.updateGridCap()
+	start = time.Now()
+	s.prepareTexture()
+	times.texture = time.Since(start)
+	s.loadTimes = times
+	s.slicing = slicing
+	s.aseprite = ase
+	s.pixels = nil
+	s.fileInfo, s.fileInfoErr = readFileInfo(s.currentFile)
+	s.refreshGamma()
+	s.refreshRecolor()
+	s.refreshBackgroundKey()
+	s.sheetSliced()
+	return true
+}
+
+// reslice cuts the loaded sheet again with the current settings. Unlike
+// reload it keeps the texture and pixels already in memory, so a change of
+// settings shows up within the frame. Aseprite sheets, whose frames don't
+// depend on the settings, and sheets that aren't loaded go through reload.
+func (s *UIState) reslice() bool {
+	if s.sheet == nil || s.aseprite != nil {
+		return s.reload()
+	}
+	s.reloadAt = 0
+	s.updateGridCap()
+
+	// The sheet is cut on a copy, so settings that don't fit leave the
+	// current sprites alone.
+	start := time.Now()
+	sheet := *s.sheet
+	sheet.Sprites = newSlicing(sheet.Texture.Width, sheet.Texture.Height, s.gridSize, s.margin).sprites()
+	slicing, err := s.resliceSheet(&sheet)
+	if err == nil && len(sheet.Sprites) == 0 {
+		err = errors.New(tr(msgNoSprites))
+	}
+	if err != nil {
+		s.loadFailed(err)
+		return false
+	}
+
+	s.sheet.Sprites = sheet.Sprites
+	s.slicing = slicing
+	s.loadTimes.slicing = time.Since(start)
+	s.sheetSliced()
+	return true
+}
+
+// sheetSliced brings everything derived from the sheet's sprites up to date
+// after it was loaded or resliced.
+func (s *UIState) sheetSliced() {
+	s.contentSizes = nil
+	s.cells = nil
+	s.filter = nil
+	s.thumbAspect = cellAspect(s.sheet.Sprites)
+
+	start := time.Now()
+	s.updateSpriteNames()
+	s.loadTimes.names = time.Since(start)
+	s.logLoadTimes()
+	s.anim.clampRange(int32(len(s.spriteNames)))
+	s.debugInfo = trf(msgLoadedSprites, len(s.spriteNames))
+	if s.aseprite != nil {
+		s.debugInfo = trf(msgLoadedAseprite, len(s.spriteNames), len(s.aseprite.tags), filepath.Base(s.aseprite.file))
+	}
+	s.loadError = ""
+	s.refreshDiff()
+	s.refreshReport(false)
+	s.refreshHistogram(false)
+}
+
+// reloadDebounce is how long, in seconds, slicing settings have to stay
+// unchanged before the sheet is resliced, so holding a key down on a field
+// reslices the sheet once instead of on every step.
+const reloadDebounce = 0.25
+
+// scheduleReload reloads the sheet once the settings have been stable for
+// reloadDebounce. Each call pushes the reload back.
+func (s *UIState) scheduleReload() {
+	s.reloadAt = rl.GetTime() + reloadDebounce
+}
+
+// reloadPending reports whether a scheduled reload hasn't run yet.
+func (s *UIState) reloadPending() bool {
+	return s.reloadAt != 0
+}
+
+// pollReload reslices the sheet once a scheduled reload is due.
+func (s *UIState) pollReload() {
+	if s.reloadPending() && rl.GetTime() >= s.reloadAt {
+		s.reslice()
+	}
+}
+
+// loadSheet builds a resource manager for the sprite sheet at path. The caller
+// owns the returned manager; on error nothing is left loaded. The sheet may
+// have no sprites if the grid doesn't fit it.
+func loadSheet(path string, margin, gridSize int32) (*resources.ResourceManager, *resources.SpriteSheet, error) {
+	newSprites := []resources.Resource{
+		{
+			Name:        "spritesheet",
+			Path:        path,
+			IsSheet:     true,
+			SheetMargin: margin,
+			GridSize:    gridSize,
+		},
+	}
+
+	// The second argument is the font of the always loaded default scene,
+	// not a shared texture. The viewer draws text with its own fonts, so it
+	// has no use for one.
+	rm := resources.NewResourceManagerWithGlobal(newSprites, nil)
+	if rm == nil {
+		return nil, nil, errors.New(tr(msgResourceManagerFailed))
+	}
+
+	if len(rm.Scenes) == 0 || len(rm.Scenes[0].SpriteSheets) == 0 {
+		rm.Close()
+		return nil, nil, errors.New(tr(msgNoSprites))
+	}
+
+	sheet := rm.Scenes[0].SpriteSheets[0]
+	if sheet.Texture.ID == 0 {
+		rm.Close()
+		return nil, nil, errors.New(tr(msgInvalidTexture))
+	}
+	return rm, sheet, nil
+}
+
+// openFile switches the viewer to the sprite sheet at path. If it fails to
+// load, the previously loaded sheet stays current.
+func (s *UIState) openFile(path string) {
+	prev, prevMeta, prevView := s.currentFile, s.currentMeta(), s.viewMode
+	if path != prev {
+		s.rememberSheet()
+	}
+	if meta, ok := loadMeta(path); ok {
+		s.applyMeta(meta)
+	} else if r, ok := s.memory.recall(path); ok {
+		s.applyMeta(r.meta())
+		s.viewMode = r.View
+	} else if meta, ok := loadFolderMeta(filepath.Dir(path)); ok {
+		s.applyMeta(meta)
+	} else if path != prev {
+		s.order, s.durations, s.renames = nil, nil, nil
+		s.presets = [2]*slicingPreset{}
+		s.slicingLocked = false
+		s.rightToLeft = false
+	}
+
+	s.currentFile = path
+	if !s.reload() {
+		if s.sheet != nil {
+			s.currentFile = prev
+			s.applyMeta(prevMeta)
+			s.viewMode = prevView
+		}
+		return
+	}
+	s.selected = make(map[string]bool)
+	s.subSheet, s.rename = nil, nil
+	if path != prev {
+		s.history = history{}
+		s.dirty = false
+		s.collapsed = nil
+		s.pinned = nil
+		s.usages = nil
+		s.closeDiff()
+		s.closeRecolor()
+		s.closeBackgroundKey()
+		s.anim.reset(int32(len(s.spriteNames)))
+	}
+
+	if s.viewMode == gridView && s.isStripSheet() {
+		s.notify(msgSingleRow)
+	}
+}
+
+// isStripSheet reports whether the loaded sheet holds a single row of frames,
+// as is common for horizontally authored animation strips.
+func (s *UIState) isStripSheet() bool {
+	return s.sheet != nil && len(s.sheet.Sprites) > 1 && s.slicing.rows == 1
+}
+
+// updateSpriteNames refreshes the list of sprite names from the current sheet,
+// in natural order, right-to-left position order or Aseprite's frame order,
+// unless a custom order has been set. When grouping by prefix, the names are
+// rearranged into their sections. The sprites' indices are worked out first,
+// so the order shown doesn't change them.
+func (s *UIState) updateSpriteNames() {
+	start := time.Now()
+	defer func() { s.perf.sorting += time.Since(start) }()
+	natural := s.naturalNames()
+	s.indices = s.spriteIndices(natural, s.sheet.Sprites)
+	if s.aseprite != nil {
+		s.spriteNames = append([]string(nil), s.aseprite.names...)
+	} else {
+		s.spriteNames = natural
+		if s.rightToLeft {
+			sortRightToLeft(s.spriteNames, s.sheet.Sprites)
+		}
+	}
+	if s.order != nil {
+		s.spriteNames = applyOrder(s.order, s.spriteNames)
+	}
+	if s.filter != nil {
+		s.spriteNames = s.filter.apply(s.spriteNames)
+	}
+	s.sections = nil
+	if s.groupPrefix {
+		s.spriteNames, s.sections = groupByPrefix(s.spriteNames)
+	}
+}
+
+// nameCache holds the sprite names of a sheet in natural order along with
+// what they were cut with, so the grid can be brought up to date after a
+// filter, order or settings change that cut the same sprites without sorting
+// thousands of names again.
+type nameCache struct {
+	key   nameCacheKey
+	names []string
+}
+
+// nameCacheKey is everything the names of a sheet's sprites depend on.
+type nameCacheKey struct {
+	file             string
+	width, height    int32
+	gridSize, margin int32
+	byCount          bool
+	columns, rows    int32
+	aseprite         bool
+	sprites          int
+}
+
+// naturalNames returns the names of the current sheet's sprites in natural
+// order, sorting them only if the sheet was cut differently since last time.
+// The slice is the caller's to reorder.
+func (s *UIState) naturalNames() []string {
+	key := nameCacheKey{
+		file:     s.currentFile,
+		width:    s.sheet.Texture.Width,
+		height:   s.sheet.Texture.Height,
+		gridSize: s.gridSize,
+		margin:   s.margin,
+		byCount:  s.sliceByCount,
+		aseprite: s.aseprite != nil,
+		sprites:  len(s.sheet.Sprites),
+	}
+	if s.sliceByCount {
+		key.columns, key.rows = s.columns, s.rows
+	}
+	if s.names.names == nil || s.names.key != key {
+		s.names = nameCache{key: key, names: sortedSpriteNames(s.sheet.Sprites)}
+	}
+	return slices.Clone(s.names.names)
+}
+
+// sortedSpriteNames returns the names of sprites in natural sort order.
+func sortedSpriteNames(sprites map[string]resources.Rectangle) []string {
+	names := make([]string, 0, len(sprites))
+	for name := range sprites {
+		names = append(names, name)
+	}
+	sortNames(names)
+	return names
+}
+
+// sortNames sorts sprite names in place in natural order. Each name is split
+// once up front rather than on every comparison, which keeps reloading
+// sheets with many thousands of sprites quick.
+func sortNames(names []string) {
+	keys := make([]sortKey, len(names))
+	for i, name := range names {
+		keys[i] = newSortKey(name)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].less(keys[j])
+	})
+	for i, k := range keys {
+		names[i] = k.name
+	}
+}
+
+func initConfig() Config {
+	cfg := Config{
+		displaySize:   32,
+		padding:       10,
+		startX:        50,
+		startY:        80,
+		headerHeight:  40,
+		labelFontSize: 10,
+		labelLines:    1,
+	}
+	cfg.resize(800, 600)
+	return cfg
+}
+
+// statusBarHeight is the height of the status line below the viewport.
+const statusBarHeight = 20
+
+// resize lays the viewer out for a drawing area of the given size.
+func (cfg *Config) resize(width, height int32) {
+	cfg.width = width
+	cfg.height = height
+	cfg.viewportHeight = height - cfg.startY - statusBarHeight
+}
+
+func initUI() *UIState {
+	return &UIState{
+		margin:             defaultMargin,
+		gridSize:           defaultGridSize,
+		theme:              &lightTheme,
+		selected:           make(map[string]bool),
+		selectionThickness: defaultSelectionThickness,
+		exportScale:        defaultExportScale,
+		fontSpacing:        defaultFontSpacing,
+		fontBaseline:       defaultFontBaseline,
+		stripSpacing:       defaultStripSpacing,
+		contactLabels:      true,
+		contactGrid:        true,
+		keyTolerance:       defaultKeyTolerance,
+		measurePitch:       defaultMeasurePitch,
+		uiScale:            defaultUIScale,
+		hover:              hoverTimer{cell: -1},
+		tooltipDelay:       defaultTooltipDelay,
+		messages:           lookupCatalog(envLanguage()),
+		anim:               animation{fps: defaultAnimFPS, setAll: defaultFrameMS, loopIn: noMarker, loopOut: noMarker},
+		lensZoom:           defaultLensZoom,
+		previewDownscale:   1,
+		mipmaps:            true,
+		textureWarnMB:      defaultTextureWarnMB,
+	}
+}
+
+// Close releases everything the viewer holds: it cancels a running export and
+// unloads the sheet, the comparison overlay, the recolored copy, the debug
+// shader and cached pixels, and removes the files sprites were dragged out
+// to. It is safe to call before any sheet was loaded, and more than once.
+func (s *UIState) Close() {
+	if s.export != nil {
+		s.export.cancel()
+		s.export = nil
+	}
+	s.exportPrompt = nil
+	s.subSheet, s.rename = nil, nil
+	s.closeDiff()
+	s.closeRecolor()
+	s.closeBackgroundKey()
+	s.unloadPreview()
+	s.unloadGamma()
+	s.removeDragOutFiles()
+	if s.alphaShader.ID != 0 {
+		rl.UnloadShader(s.alphaShader)
+		s.alphaShader = rl.Shader{}
+	}
+	if s.silhouetteShader.ID != 0 {
+		rl.UnloadShader(s.silhouetteShader)
+		s.silhouetteShader = rl.Shader{}
+	}
+	if s.rm != nil {
+		s.rm.Close()
+		s.rm = nil
+	}
+	s.sheet = nil
+	s.aseprite = nil
+	s.spriteNames = nil
+	s.sections = nil
+	s.pixels = nil
+	s.contentSizes = nil
+	s.report = nil
+	s.histogram = nil
+	s.filter = nil
+	unloadFonts(s.fonts)
+	s.fonts = nil
+}
+
+// handleInput processes keyboard and mouse input events.
+func (s *UIState) handleInput(cfg Config) {
+	s.widgets.beginFrame()
+	s.layers.beginFrame()
+
+	if s.palette != nil {
+		s.handlePaletteInput()
+	} else {
+		s.runShortcuts()
+		// Escape goes to the focused widget first, so it closes panels only
+		// once nothing has the keyboard, and then to the topmost of them.
+		if rl.IsKeyPressed(rl.KeyEscape) && !s.widgets.focused() {
+			s.escapeLayer()
+		}
+	}
+
+	mouse := mousePosition()
+	if mouse.X < 0 || mouse.Y < 0 || mouse.X >= float32(cfg.width) || mouse.Y >= float32(cfg.height) {
+		return
+	}
+	if s.widgets.wheelTaken {
+		return
+	}
+	// Panels over the grid don't scroll it; the inspector zooms instead.
+	if s.overLayer() && s.layers.pointer != layerInspector {
+		return
+	}
+	wheel := rl.GetMouseWheelMoveV()
+	shift := rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift)
+	if s.layers.pointer == layerInspector {
+		s.zoomInspector(wheel.Y)
+	} else if s.viewMode == sheetView {
+		// The sheet view always fits the viewport; the wheel only zooms
+		// the magnifier.
+		if s.lensActive() && wheel.Y != 0 {
+			s.zoomLens(int32(math.Copysign(1, float64(wheel.Y))))
+		}
+	} else if s.viewMode == stripView || shift {
+		s.scrollOffsetX -= (wheel.X + wheel.Y) * 30
+	} else if s.snapRows {
+		// Snapped scrolling moves a whole row per notch; rounding the
+		// usual 30px step would cancel it out.
+		s.scrollOffset -= wheel.Y * float32(cfg.rowHeight())
+		s.scrollOffsetX -= wheel.X * 30
+		if wheel.Y != 0 {
+			s.snapScroll(cfg)
+		}
+	} else {
+		s.scrollOffset -= wheel.Y * 30
+		s.scrollOffsetX -= wheel.X * 30
+	}
+}
+
+// keyboardFree reports whether plain keys are free for the grid's own use:
+// no widget has focus and the command palette is closed.
+func (s *UIState) keyboardFree() bool {
+	return !s.widgets.focused() && s.palette == nil
+}
+
+// snapScroll rounds the vertical scroll offset to the nearest row boundary
+// when snapping to rows is on, so the grid starts with a whole row. In a
+// grouped grid the section headers are boundaries too.
+func (s *UIState) snapScroll(cfg Config) {
+	if !s.snapRows || s.viewMode != gridView {
+		return
+	}
+	rowHeight := float32(cfg.rowHeight())
+	if !s.grouped() {
+		s.scrollOffset = float32(math.Round(float64(s.scrollOffset/rowHeight))) * rowHeight
+		return
+	}
+
+	s.layoutSections(cfg)
+	k := max(s.sectionAt(s.scrollOffset), 0)
+	top := float32(s.sections[k].top)
+	best := top
+	if rows := s.scrollOffset - top - sectionHeaderHeight; rows > 0 && !s.collapsed[s.sections[k].prefix] {
+		best = top + sectionHeaderHeight + float32(math.Round(float64(rows/rowHeight)))*rowHeight
+	}
+	if k+1 < len(s.sections) {
+		if next := float32(s.sections[k+1].top); next-s.scrollOffset < float32(math.Abs(float64(best-s.scrollOffset))) {
+			best = next
+		}
+	}
+	s.scrollOffset = best
+}
+
+// revealSprite scrolls the grid just far enough to show the sprite waiting
+// to be revealed, if any, once it is laid out.
+func (s *UIState) revealSprite(cfg Config) {
+	name := s.reveal
+	s.reveal = ""
+	if name == "" {
+		return
+	}
+	i := slices.Index(s.spriteNames, name)
+	if i < 0 || s.cellHidden(i) {
+		return
+	}
+	rect := s.cellRect(cfg, i)
+	if s.viewMode == stripView {
+		if rect.X < float32(cfg.startX) {
+			s.scrollOffsetX += rect.X - float32(cfg.startX)
+		} else if right := float32(cfg.width - cfg.startX); rect.X+rect.Width > right {
+			s.scrollOffsetX += rect.X + rect.Width - right
+		}
+		return
+	}
+	if rect.Y < float32(cfg.startY) {
+		s.scrollOffset += rect.Y - float32(cfg.startY)
+	} else if bottom := float32(cfg.startY + cfg.viewportHeight); rect.Y+rect.Height > bottom {
+		s.scrollOffset += rect.Y + rect.Height - bottom
+	}
+}
+
+// handleScrolling manages scroll state based on content height and viewport
+func (s *UIState) handleScrolling(contentHeight float32, viewportHeight int32) {
+	maxScroll := float32(0)
+	if contentHeight > float32(viewportHeight) {
+		maxScroll = contentHeight - float32(viewportHeight)
+	}
+	if s.scrollOffset < 0 {
+		s.scrollOffset = 0
+	}
+	if s.scrollOffset > maxScroll {
+		s.scrollOffset = maxScroll
+	}
+}
+
+// handleHorizontalScrolling clamps the horizontal scroll state. Layouts that
+// fit the viewport width never scroll sideways.
+func (s *UIState) handleHorizontalScrolling(contentWidth float32, viewportWidth int32) {
+	maxScroll := float32(0)
+	if contentWidth > float32(viewportWidth) {
+		maxScroll = contentWidth - float32(viewportWidth)
+	}
+	if s.scrollOffsetX < 0 {
+		s.scrollOffsetX = 0
+	}
+	if s.scrollOffsetX > maxScroll {
+		s.scrollOffsetX = maxScroll
+	}
+}
+
+// cellSize returns the size of a thumbnail cell: displaySize along the longer
+// side of the sheet's cells, and the other side scaled to their aspect ratio,
+// so 32x48 frames get tall cells rather than squashed square ones.
+func (cfg Config) cellSize() (w, h int32) {
+	switch {
+	case cfg.cellAspect > 1:
+		return cfg.displaySize, max(int32(float32(cfg.displaySize)/cfg.cellAspect+0.5), 1)
+	case cfg.cellAspect > 0 && cfg.cellAspect < 1:
+		return max(int32(float32(cfg.displaySize)*cfg.cellAspect+0.5), 1), cfg.displaySize
+	}
+	return cfg.displaySize, cfg.displaySize
+}
+
+// columnWidth returns the horizontal advance between thumbnail columns.
+func (cfg Config) columnWidth() int32 {
+	w, _ := cfg.cellSize()
+	return w + cfg.padding
+}
+
+// spritesPerRow returns how many thumbnails fit across the grid area.
+func (cfg Config) spritesPerRow() int {
+	perRow := int((cfg.width - cfg.startX*2) / cfg.columnWidth())
+	if perRow < 1 {
+		return 1
+	}
+	return perRow
+}
+
+// labelHeight returns the height of the name block under a thumbnail, sized
+// from the label font so labels never run into the next row. Overlaid labels
+// take no room.
+func (cfg Config) labelHeight() int32 {
+	if cfg.labelOverlay {
+		return 0
+	}
+	return labelGap + cfg.labelFontSize*cfg.labelLines + labelGap
+}
+
+// rowHeight returns the vertical advance between thumbnail rows, including
+// the label.
+func (cfg Config) rowHeight() int32 {
+	_, h := cfg.cellSize()
+	return h + cfg.labelHeight() + cfg.padding
+}
+
+// cellRect returns the on-screen thumbnail rectangle for the sprite at index i
+// of spriteNames, adjusted for the current scroll offset.
+func (s *UIState) cellRect(cfg Config, i int) rl.Rectangle {
+	if s.viewMode == nativeView {
+		return s.nativeCellRect(cfg, i)
+	}
+	w, h := cfg.cellSize()
+	if s.viewMode == stripView {
+		return rl.Rectangle{
+			X:      float32(cfg.startX+int32(i)*cfg.columnWidth()) - s.scrollOffsetX,
+			Y:      float32(cfg.startY),
+			Width:  float32(w),
+			Height: float32(h),
+		}
+	}
+
+	col, row, top := s.gridPosition(cfg, i)
+	return rl.Rectangle{
+		X:      float32(cfg.startX+int32(col)*cfg.columnWidth()) - s.scrollOffsetX,
+		Y:      float32(cfg.startY+top+int32(row)*cfg.rowHeight()) - s.scrollOffset,
+		Width:  float32(w),
+		Height: float32(h),
+	}
+}
+
+// hoveredCell returns the index of the thumbnail under the mouse cursor, or -1
+// if the cursor is outside the grid or between cells.
+func (s *UIState) hoveredCell(cfg Config) int {
+	if s.sheet == nil || len(s.spriteNames) == 0 {
+		return -1
+	}
+
+	mouse := mousePosition()
+	if mouse.Y < float32(cfg.headerHeight) || mouse.Y > float32(cfg.startY+cfg.viewportHeight) || s.overInspector(cfg) {
+		return -1
+	}
+
+	if s.viewMode == sheetView {
+		return s.sheetCellAt(cfg, mouse)
+	}
+	if s.viewMode == nativeView {
+		return s.nativeCellAt(cfg, mouse)
+	}
+
+	gridX := mouse.X + s.scrollOffsetX - float32(cfg.startX)
+	gridY := mouse.Y + s.scrollOffset - float32(cfg.startY)
+	if s.viewMode == stripView {
+		gridY = mouse.Y - float32(cfg.startY)
+	}
+	if gridX < 0 || gridY < 0 {
+		return -1
+	}
+
+	start, end := 0, len(s.spriteNames)
+	if s.grouped() {
+		k := s.sectionAt(gridY)
+		sec := s.sections[k]
+		gridY -= float32(sec.top + sectionHeaderHeight)
+		if gridY < 0 || s.collapsed[sec.prefix] {
+			return -1
+		}
+		start, end = sec.start, sec.end
+	}
+
+	col := int(gridX / float32(cfg.columnWidth()))
+	row := int(gridY / float32(cfg.rowHeight()))
+
+	var i int
+	if s.viewMode == stripView {
+		if row != 0 {
+			return -1
+		}
+		i = col
+	} else {
+		if col >= cfg.spritesPerRow() {
+			return -1
+		}
+		if s.rightToLeft {
+			col = cfg.spritesPerRow() - 1 - col
+		}
+		i = start + row*cfg.spritesPerRow() + col
+	}
+	if i >= end || !rl.CheckCollisionPointRec(mouse, s.cellRect(cfg, i)) {
+		return -1
+	}
+	return i
+}
+
+// renderSprites draws all visible sprites from the sprite sheet.
+func (s *UIState) renderSprites(cfg Config) {
+	start := time.Now()
+	defer func() { s.perf.sprites += time.Since(start) }()
+	if s.sheet == nil {
+		if s.lastError == nil {
+			drawText(trf(msgNoSheetLoaded, tr(msgOpenFile)), 50, cfg.startY, 20, s.theme.CellBorder)
+		}
+		return
+	}
+	if s.viewMode == sheetView {
+		s.renderSheetView(cfg)
+		return
+	}
+
+	var contentWidth, contentHeight float32
+	if s.viewMode == nativeView {
+		_, height := s.nativeCells(cfg)
+		contentWidth, contentHeight = float32(cfg.width), float32(height)
+	} else if s.viewMode == stripView {
+		contentWidth = float32(cfg.startX*2) + float32(len(s.spriteNames))*float32(cfg.columnWidth())
+		contentHeight = float32(cfg.startY + cfg.rowHeight())
+	} else {
+		perRow := cfg.spritesPerRow()
+		totalRows := len(s.spriteNames) / perRow
+		if len(s.spriteNames)%perRow != 0 {
+			totalRows++
+		}
+		contentWidth = float32(cfg.startX*2) + float32(perRow)*float32(cfg.columnWidth())
+		contentHeight = float32(cfg.startY) + float32(totalRows*int(cfg.rowHeight()))
+		if s.grouped() {
+			contentHeight = float32(cfg.startY + s.layoutSections(cfg))
+		}
+	}
+	s.revealSprite(cfg)
+	s.handleHorizontalScrolling(contentWidth, cfg.width)
+	s.handleScrolling(contentHeight, cfg.viewportHeight)
+
+	var visible []int
+	for i := range s.spriteNames {
+		if s.cellHidden(i) {
+			continue
+		}
+		dest := s.cellRect(cfg, i)
+		if dest.Y+dest.Height < 0 || dest.Y > float32(cfg.height) || dest.X+dest.Width < 0 || dest.X > float32(cfg.width) {
+			s.perf.culled++
+			continue
+		}
+		visible = append(visible, i)
+	}
+	s.perf.drawn += len(visible)
+
+	if s.zebra && s.viewMode == gridView {
+		for _, i := range visible {
+			if _, row, _ := s.gridPosition(cfg, i); row%2 == 1 {
+				rl.DrawRectangleRec(s.cellRect(cfg, i), s.theme.Stripe)
+			}
+		}
+	}
+
+	// Thumbnails are drawn in their own pass so a debug shader never touches
+	// the borders and labels drawn afterwards.
+	shader, useShader := s.thumbnailShader()
+	if s.silhouette && useShader {
+		for _, i := range visible {
+			rect := s.sheet.Sprites[s.spriteNames[i]]
+			rl.DrawRectangleRec(s.thumbnailRect(cfg, i, rect), s.silhouetteBackground())
+		}
+	}
+	if useShader {
+		rl.BeginShaderMode(shader)
+	}
+	for _, i := range visible {
+		rect := s.sheet.Sprites[s.spriteNames[i]]
+
+		source := rl.Rectangle{
+			X:      float32(rect.X),
+			Y:      float32(rect.Y),
+			Width:  float32(rect.Width),
+			Height: float32(rect.Height),
+		}
+		rl.DrawTexturePro(s.drawTexture(), s.texSource(source), s.thumbnailRect(cfg, i, rect), rl.Vector2{}, 0, rl.White)
+	}
+	if useShader {
+		rl.EndShaderMode()
+	}
+
+	playing := s.playingSprite()
+	for _, i := range visible {
+		name := s.spriteNames[i]
+		dest := s.cellRect(cfg, i)
+
+		rl.DrawRectangleLinesEx(dest, 1, s.theme.CellBorder)
+		if cfg.labelOverlay {
+			s.drawOverlayLabel(cfg, i, dest)
+		} else {
+			drawText(s.displayName(name), int32(dest.X), int32(dest.Y+dest.Height)+labelGap, cfg.labelFontSize, s.theme.MutedText)
+		}
+
+		if s.trueSize {
+			s.drawSizeLabel(name, dest)
+		}
+		if s.diff != nil {
+			s.drawDiffMarker(name, dest)
+		}
+		if group := s.report.group(name); group > 0 {
+			rl.DrawRectangleLinesEx(dest, 1, s.theme.Duplicate)
+			drawBadge(dest, fmt.Sprintf("#%d", group), s.theme.Duplicate)
+		}
+		if pair, _ := s.report.mirror(name); pair > 0 {
+			// The badge goes in the bottom corner so it never covers a
+			// duplicate group number.
+			rl.DrawRectangleLinesEx(dest, 1, s.theme.Mirror)
+			drawBadge(rl.Rectangle{X: dest.X, Y: dest.Y + dest.Height - 12, Width: dest.Width}, trf(msgMirrorBadge, pair), s.theme.Mirror)
+		}
+		if name == playing {
+			s.drawPlayingOutline(dest)
+		}
+		if s.selected[name] {
+			s.drawSelectionOutline(dest)
+		}
+	}
+
+	section := s.hoveredSection(cfg)
+	if s.grouped() {
+		s.drawSectionHeaders(cfg, section)
+	}
+
+	hovered := s.hoveredCell(cfg)
+	s.hover.update(hovered)
+	if hovered >= 0 {
+		mouse := mousePosition()
+		crosshair := rl.ColorAlpha(s.theme.CellBorder, 0.5)
+		rl.DrawLine(0, int32(mouse.Y), cfg.width, int32(mouse.Y), crosshair)
+		rl.DrawLine(int32(mouse.X), cfg.headerHeight, int32(mouse.X), cfg.startY+cfg.viewportHeight, crosshair)
+		rl.DrawRectangleLinesEx(s.cellRect(cfg, hovered), 1, s.theme.Text)
+	}
+
+	mouse := mousePosition()
+	if rl.IsMouseButtonPressed(rl.MouseLeftButton) && section >= 0 {
+		if rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift) {
+			s.selectSection(section)
+		} else {
+			s.toggleSection(s.sections[section].prefix)
+		}
+	} else if rl.IsMouseButtonPressed(rl.MouseLeftButton) && mouse.Y > float32(cfg.headerHeight) && mouse.Y < float32(cfg.startY+cfg.viewportHeight) && !s.overInspector(cfg) {
+		if s.pickingKey() {
+			s.pickKey(cfg)
+		} else {
+			s.clickCell(hovered)
+			if hovered < 0 {
+				s.beginBand()
+			}
+		}
+	}
+	s.updateDrag(cfg, hovered)
+	s.updateBand(cfg)
+
+	if contentHeight > float32(cfg.viewportHeight) {
+		x := float32(cfg.width - 20)
+		if s.scrollOffset > 0 {
+			rl.DrawTriangle(
+				rl.Vector2{X: x, Y: 50},
+				rl.Vector2{X: x + 10, Y: 60},
+				rl.Vector2{X: x - 10, Y: 60},
+				rl.Gray)
+		}
+		if s.scrollOffset < contentHeight-float32(cfg.viewportHeight) {
+			rl.DrawTriangle(
+				rl.Vector2{X: x, Y: float32(cfg.viewportHeight + cfg.startY - 10)},
+				rl.Vector2{X: x - 10, Y: float32(cfg.viewportHeight + cfg.startY - 20)},
+				rl.Vector2{X: x + 10, Y: float32(cfg.viewportHeight + cfg.startY - 20)},
+				rl.Gray)
+		}
+	}
+
+	if contentWidth > float32(cfg.width) {
+		s.drawHorizontalScrollbar(cfg, contentWidth)
+	}
+}
+
+// drawHorizontalScrollbar draws a thin scrollbar along the bottom of the
+// viewport showing which part of the content is in view.
+func (s *UIState) drawHorizontalScrollbar(cfg Config, contentWidth float32) {
+	width := float32(cfg.width)
+	track := rl.Rectangle{X: 0, Y: float32(cfg.startY+cfg.viewportHeight) - 6, Width: width, Height: 4}
+	thumbWidth := max(width*width/contentWidth, 20)
+	thumbX := s.scrollOffsetX / (contentWidth - width) * (width - thumbWidth)
+	rl.DrawRectangleRec(track, rl.ColorAlpha(s.theme.Panel, 0.6))
+	rl.DrawRectangleRec(rl.Rectangle{X: thumbX, Y: track.Y, Width: thumbWidth, Height: track.Height}, rl.Gray)
+}
+
+// thumbnailRect returns where the sprite at index i, with source rectangle
+// rect, is drawn. Thumbnails normally fill as much of their cell as their
+// aspect ratio allows, which for a grid cut sheet is all of it; in true size
+// mode they are drawn one screen pixel per sprite pixel, scaled down only if
+// larger than the cell. Either way they are centered in the cell.
+func (s *UIState) thumbnailRect(cfg Config, i int, rect resources.Rectangle) rl.Rectangle {
+	cell := s.cellRect(cfg, i)
+	if rect.Width == 0 || rect.Height == 0 {
+		return cell
+	}
+	w, h := float32(rect.Width), float32(rect.Height)
+	if scale := min(cell.Width/w, cell.Height/h); scale < 1 || !s.trueSize {
+		w, h = w*scale, h*scale
+	}
+	return rl.Rectangle{X: cell.X + (cell.Width-w)/2, Y: cell.Y + (cell.Height-h)/2, Width: w, Height: h}
+}
+
+// cellAspect returns the width to height ratio thumbnail cells keep for the
+// loaded sheet: that of its largest sprite extents, which for a grid is the
+// cell and for Aseprite frames of varying size fits the widest and the
+// tallest.
+func cellAspect(sprites map[string]resources.Rectangle) float32 {
+	var w, h int32
+	for _, rect := range sprites {
+		w, h = max(w, rect.Width), max(h, rect.Height)
+	}
+	if w == 0 || h == 0 {
+		return 0
+	}
+	return float32(w) / float32(h)
+}
+
+// drawSizeLabel prints the size of the drawn part of a sprite, ignoring
+// transparent borders, in the corner of its cell.
+func (s *UIState) drawSizeLabel(name string, dest rl.Rectangle) {
+	w, h, ok := s.contentSize(name)
+	if !ok {
+		return
+	}
+	text := fmt.Sprintf("%dx%d", w, h)
+	width := measureText(text, 10)
+	rl.DrawRectangle(int32(dest.X), int32(dest.Y), width+2, 10, rl.ColorAlpha(s.theme.Background, 0.7))
+	drawText(text, int32(dest.X)+1, int32(dest.Y), 10, s.theme.Text)
+}
+
+// drawSelectionOutline draws the selection highlight around a thumbnail. The
+// outline sits outside the cell so it never hides sprite pixels, with a thin
+// halo around it for contrast.
+func (s *UIState) drawSelectionOutline(dest rl.Rectangle) {
+	t := float32(s.selectionThickness)
+	outline := rl.Rectangle{X: dest.X - t, Y: dest.Y - t, Width: dest.Width + 2*t, Height: dest.Height + 2*t}
+	rl.DrawRectangleLinesEx(outline, t, s.selectionColor())
+	halo := rl.Rectangle{X: outline.X - 1, Y: outline.Y - 1, Width: outline.Width + 2, Height: outline.Height + 2}
+	rl.DrawRectangleLinesEx(halo, 1, s.theme.SelectionHalo)
+}
+
+// clickCell updates the selection for a click on the thumbnail at index i.
+// A plain click selects only that sprite, Ctrl+click toggles it, and clicking
+// empty space clears the selection.
+func (s *UIState) clickCell(i int) {
+	multi := rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl)
+	if i < 0 {
+		if !multi {
+			s.selected = make(map[string]bool)
+		}
+		return
+	}
+
+	name := s.spriteNames[i]
+	if multi {
+		if s.selected[name] {
+			delete(s.selected, name)
+		} else {
+			s.selected[name] = true
+		}
+		return
+	}
+	s.selected = map[string]bool{name: true}
+	if s.widgets.clicks.click("sprite:" + name) {
+		s.inspectSprite(name)
+	}
+}
+
+// hoverInfo describes the thumbnail under the cursor: its index in the grid,
+// its column and row in the source sheet, and its source rectangle.
+func (s *UIState) hoverInfo(cfg Config) string {
+	i := s.hoveredCell(cfg)
+	if i < 0 {
+		return ""
+	}
+
+	name := s.spriteNames[i]
+	rect := s.sheet.Sprites[name]
+	col, row := s.slicing.position(rect)
+	info := trf(msgHoverInfo, s.indices[name], i, col, row, s.coord(rect.X), s.coord(rect.Y), s.coord(rect.Width), s.coord(rect.Height))
+	if s.diff != nil && s.diff.changed[name] {
+		info += tr(msgHoverChanged)
+	}
+	return info
+}
+
+// renderStatusBar draws the status line below the grid viewport.
+func (s *UIState) renderStatusBar(cfg Config) {
+	top := cfg.startY + cfg.viewportHeight
+	rl.DrawRectangle(0, top, cfg.width, cfg.height-top, s.theme.Background)
+	rl.DrawLine(0, top, cfg.width, top, s.theme.Panel)
+
+	if info := s.hoverInfo(cfg); info != "" {
+		drawText(info, 10, top+5, 10, s.theme.MutedText)
+	} else if len(s.selected) > 0 {
+		drawText(trf(msgSelectedCount, len(s.selected)), 10, top+5, 10, s.theme.MutedText)
+	}
+
+	right := cfg.width - 10
+	if watch := s.watchStatus(); watch != "" {
+		right -= measureText(watch, 10)
+		col := s.theme.Text
+		if s.watch.paused {
+			col = s.theme.Warning
+		}
+		drawText(watch, right, top+5, 10, col)
+		right -= 15
+	}
+	if preset := s.presetStatus(); preset != "" {
+		right -= measureText(preset, 10)
+		drawText(preset, right, top+5, 10, s.selectionColor())
+		right -= 15
+	}
+	if filter := s.filterStatus(); filter != "" {
+		right -= measureText(filter, 10)
+		drawText(filter, right, top+5, 10, s.selectionColor())
+		right -= 15
+	}
+	if rate, off := s.rateStatus(); rate != "" {
+		right -= measureText(rate, 10)
+		col := s.theme.MutedText
+		if off {
+			col = s.theme.Warning
+		}
+		drawText(rate, right, top+5, 10, col)
+		right -= 15
+	}
+	if mem := s.memoryStatus(); mem != "" {
+		right -= measureText(mem, 10)
+		col := s.theme.MutedText
+		if s.textureMemory() > int64(s.textureWarnMB)<<20 {
+			col = s.theme.Warning
+		}
+		drawText(mem, right, top+5, 10, col)
+		right -= 15
+	}
+
+	if s.export != nil {
+		s.renderExportProgress(cfg, right)
+	} else if s.reloadPending() {
+		text := tr(msgReslicing)
+		drawText(text, right-measureText(text, 10), top+5, 10, s.theme.MutedText)
+	} else if s.debugInfo != "" {
+		info := s.debugInfo
+		if s.dirty {
+			info += trf(msgUnsaved, binding{key: rl.KeyS, ctrl: true})
+		}
+		drawText(info, right-measureText(info, 10), top+5, 10, s.theme.MutedText)
+	}
+}
+
+// drawOverlayLabel draws the name of sprite i centered on its thumbnail,
+// over a translucent backing so it reads on any art. A name too long for
+// the cell is replaced by the sprite's index, as the status bar shows it.
+func (s *UIState) drawOverlayLabel(cfg Config, i int, dest rl.Rectangle) {
+	name := s.spriteNames[i]
+	text := s.displayName(name)
+	if float32(measureText(text, cfg.labelFontSize)) > dest.Width-4 {
+		text = strconv.Itoa(s.indices[name])
+	}
+	width := measureText(text, cfg.labelFontSize) + 4
+	height := cfg.labelFontSize + 2
+	x := int32(dest.X+dest.Width/2) - width/2
+	y := int32(dest.Y+dest.Height/2) - height/2
+	rl.DrawRectangle(x, y, width, height, rl.ColorAlpha(s.theme.Background, 0.6))
+	drawText(text, x+2, y+1, cfg.labelFontSize, s.theme.Text)
+}
+
+// selectedNames returns the selected sprites in display order.
+func (s *UIState) selectedNames() []string {
+	var names []string
+	for _, name := range s.spriteNames {
+		if s.selected[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// exportSprites asks for a destination folder and exports the selected
+// sprites into it, or every sprite in the sheet when nothing is selected.
+func (s *UIState) exportSprites() {
+	if s.export != nil {
+		s.notify(msgExportRunning)
+		return
+	}
+	if s.sheet == nil {
+		s.notify(msgNothingToExport)
+		return
+	}
+	names := s.spriteNames
+	if len(s.selected) > 0 {
+		names = s.selectedNames()
+	}
+	if dir := openDirectoryDialog(); dir != "" {
+		s.startExport(names, dir)
+	}
+}
+
+// renderUI draws the application interface including header, buttons, and settings panel.
+func (s *UIState) renderUI(cfg Config) {
+	mouseDisabled = s.overLayer()
+	rl.DrawRectangle(0, 0, cfg.width, cfg.headerHeight, s.theme.Background)
+	rl.DrawLine(0, cfg.headerHeight, cfg.width, cfg.headerHeight, s.theme.Panel)
+	drawText(tr(msgTitle), 10, 10, 20, s.theme.Text)
+
+	// Header buttons are laid out from the right edge, each as wide as its
+	// label in the current language.
+	x := float32(cfg.width) - 30
+	headerButton := func(label string) bool {
+		width := buttonWidth(label, 80)
+		x -= width
+		clicked := drawButton(rl.Rectangle{X: x, Y: 8, Width: width, Height: 25}, label)
+		x -= 10
+		return clicked
+	}
+
+	if headerButton(tr(msgOpenFile)) {
+		if file := openFileDialog(); file != "" {
+			s.openChosenFile(file)
+		}
+	}
+
+	if headerButton(tr(msgSettings)) {
+		s.showSettings = !s.showSettings
+	}
+
+	if headerButton(tr(msgExport)) {
+		s.exportSprites()
+	}
+
+	if headerButton(tr(msgInfo)) {
+		s.toggleReport()
+	}
+
+	if headerButton(trf(msgEditIn, s.editorName())) {
+		s.editSheet()
+	}
+	mouseDisabled = false
+
+	// Layers are drawn bottom to top, and the mouse and Escape go to the
+	// topmost one they reach.
+	if s.diff != nil {
+		s.drawLayer(cfg, layerDiff, false, nil, s.renderDiffBar)
+	}
+	if s.recolor != nil {
+		s.drawLayer(cfg, layerRecolor, false, nil, s.renderRecolorBar)
+	}
+	if s.bgKey != nil {
+		s.drawLayer(cfg, layerKey, false, nil, s.renderKeyBar)
+	}
+
+	if s.lastError != nil {
+		s.drawLayer(cfg, layerError, false, nil, s.renderError)
+	}
+
+	if s.anim.visible {
+		s.drawLayer(cfg, layerAnimation, false, nil, s.renderAnimation)
+	}
+
+	s.drawLayer(cfg, layerPins, false, nil, s.renderPins)
+
+	if s.inspect.visible {
+		s.drawLayer(cfg, layerInspector, false, (*UIState).closeInspector, s.renderInspector)
+	}
+
+	var closeSettings func(*UIState)
+	if s.showSettings {
+		closeSettings = func(s *UIState) { s.showSettings = false }
+	}
+	s.drawLayer(cfg, layerSettings, false, closeSettings, s.renderSettingsTransition)
+
+	if s.showAbout {
+		s.drawLayer(cfg, layerAbout, false, func(s *UIState) { s.showAbout = false }, s.renderAbout)
+	}
+	if s.report != nil {
+		s.drawLayer(cfg, layerReport, false, func(s *UIState) { s.report = nil }, s.renderReport)
+	}
+
+	if s.usages != nil {
+		s.drawLayer(cfg, layerUsages, false, func(s *UIState) { s.usages = nil }, s.renderUsages)
+	}
+
+	if s.histogram != nil {
+		s.drawLayer(cfg, layerHistogram, false, func(s *UIState) { s.histogram = nil }, s.renderHistogram)
+	}
+
+	if s.palette != nil {
+		s.drawLayer(cfg, layerPalette, false, nil, s.renderPalette)
+	}
+
+	if s.exportPrompt != nil {
+		s.drawLayer(cfg, layerExportPrompt, true, func(s *UIState) { s.exportPrompt = nil }, s.renderExportPrompt)
+	}
+
+	if s.subSheet != nil {
+		s.drawLayer(cfg, layerSubSheet, true, func(s *UIState) { s.subSheet = nil }, s.renderSubSheetPrompt)
+	}
+
+	if s.rename != nil {
+		s.drawLayer(cfg, layerRename, true, func(s *UIState) { s.rename = nil }, s.renderRenamePrompt)
+	}
+
+	if s.confirmClose {
+		s.drawLayer(cfg, layerCloseConfirm, true, func(s *UIState) { s.confirmClose = false }, s.renderCloseConfirm)
+	}
+
+	if s.recovery != nil && !s.recoveryLater {
+		s.drawLayer(cfg, layerRecovery, true, (*UIState).askRecoveryLater, s.renderRecoveryPrompt)
+	}
+}
+
+// settingsColumns lists the labels of each column of the settings panel, so
+// the columns can be made wide enough for them.
+var settingsColumns = [3][]msgID{
+	{msgMargin, msgOutlinePx, msgAtlasTrim, msgZebraRows, msgFontSpacing, msgGroupPrefix, msgStripSpacing, msgExportNames, msgAlphaThreshold, msgContactLabels, msgBackground, msgSilhouetteColor, msgMeasureGrid, msgColumns, msgHighContrast},
+	{msgGridSize, msgOutlineColor, msgAlphaTest, msgTrueSize, msgFontBaseline, msgLabelOverlay, msgStripVertical, msgLockSlicing, msgContactGrid, msgKeyTolerance, msgSilhouetteBackground, msgMeasurePitch, msgRows, msgColorblindSafe},
+	{msgByCount, msgExportScale, msgResetOrder, msgSnapRows, msgTextScale, msgMipmaps, msgStripTrim, msgRightToLeft, msgIndexBase, msgSilhouettes, msgMeasureColor},
+}
+
+// settingsSectionGap is the extra space above the accessibility section of
+// the settings panel, which holds its heading.
+const settingsSectionGap = 20
+
+// settingsRowHeight is the distance between rows of the settings panel, and
+// how far one step of the mouse wheel scrolls it.
+const settingsRowHeight = 50
+
+// renderSettings draws the settings panel, applies any changes made in it and
+// returns the panel's bounds.
+// Fields are laid out in rows of three, with the accessibility options in a
+// section of their own at the bottom. Count mode adds a row for the column
+// and row count, which replace the grid size. Columns and the panel widen to
+// fit labels longer than the fields. A panel taller than the window is cut
+// off at its bottom, with a scrollbar to show there is more, and scrolls
+// with the mouse wheel, except over a numeric field, which takes the wheel
+// itself.
+func (s *UIState) renderSettings(cfg Config) rl.Rectangle {
+	rows := 14
+	if s.sliceByCount {
+		rows = 15
+	}
+	accessRow := rows - 1
+
+	inputWidth := float32(60)
+	inputHeight := float32(20)
+	spacing := float32(40)
+
+	var columnWidths [3]float32
+	for col, labels := range settingsColumns {
+		columnWidths[col] = inputWidth
+		for _, id := range labels {
+			columnWidths[col] = max(columnWidths[col], float32(measureText(tr(id), 10)))
+		}
+	}
+	resetWidth := buttonWidth(tr(msgResetOrder), inputWidth)
+	columnWidths[2] = max(columnWidths[2], resetWidth)
+	var namingWidth float32
+	for _, n := range exportNamings {
+		namingWidth = max(namingWidth, buttonWidth(tr(n.label), inputWidth))
+	}
+	columnWidths[0] = max(columnWidths[0], namingWidth)
+	for _, b := range indexBases {
+		columnWidths[2] = max(columnWidths[2], buttonWidth(tr(b.label), inputWidth))
+	}
+	var presetWidth float32
+	for _, name := range presetNames {
+		presetWidth = max(presetWidth, buttonWidth(trf(msgStorePreset, name), inputWidth))
+		presetWidth = max(presetWidth, float32(measureText(trf(msgPresetActiveLabel, name), 10)))
+	}
+	columnWidths[1] = max(columnWidths[1], presetWidth)
+	columnWidths[2] = max(columnWidths[2], presetWidth)
+	totalWidth := columnWidths[0] + columnWidths[1] + columnWidths[2] + 2*spacing
+
+	helpText := tr(msgSettingsHelp)
+	helpWidth := measureText(helpText, 10)
+	panelWidth := max(int32(300), int32(totalWidth)+40, helpWidth+20)
+	contentHeight := int32(45 + rows*settingsRowHeight + settingsSectionGap + 10)
+	if s.sheet != nil {
+		contentHeight += 25 + cfg.displaySize
+	}
+	top := cfg.headerHeight + 5
+	panelHeight := max(min(contentHeight, cfg.height-top-5), 0)
+	s.settingsScroll = max(min(s.settingsScroll, float32(contentHeight-panelHeight)), 0)
+
+	settingsRect := rl.Rectangle{X: float32(cfg.width/2 - panelWidth/2), Y: float32(top)}
+	view := rl.Rectangle{X: settingsRect.X, Y: settingsRect.Y, Width: float32(panelWidth), Height: float32(panelHeight)}
+
+	rl.DrawRectangleRec(view, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(view, 1, s.theme.Text)
+
+	// The rows scrolled out of view are neither drawn nor reachable by the
+	// mouse.
+	disabled := mouseDisabled
+	if !rl.CheckCollisionPointRec(mousePosition(), view) {
+		mouseDisabled = true
+	}
+	beginClip(view)
+	settingsRect.Y -= s.settingsScroll
+
+	oldMargin := s.margin
+	oldGridSize := s.gridSize
+	oldByCount, oldColumns, oldRows := s.sliceByCount, s.columns, s.rows
+	oldThickness := s.selectionThickness
+	oldScale := s.exportScale
+	oldSpacing, oldBaseline := s.fontSpacing, s.fontBaseline
+	oldStripSpacing := s.stripSpacing
+	oldUIScale := s.uiScale
+
+	titleText := tr(msgSettings)
+	titleWidth := measureText(titleText, 15)
+	drawText(titleText,
+		int32(settingsRect.X+float32(panelWidth/2)-float32(titleWidth)/2),
+		int32(settingsRect.Y+5),
+		15,
+		s.theme.Text)
+
+	var columnX [3]float32
+	columnX[0] = settingsRect.X + (float32(panelWidth)-totalWidth)/2
+	for col := 1; col < len(columnX); col++ {
+		columnX[col] = columnX[col-1] + columnWidths[col-1] + spacing
+	}
+
+	field := func(row, col int) rl.Rectangle {
+		y := settingsRect.Y + 45 + float32(row)*settingsRowHeight
+		if row >= accessRow {
+			y += settingsSectionGap
+		}
+		return rl.Rectangle{
+			X:      columnX[col],
+			Y:      y,
+			Width:  inputWidth,
+			Height: inputHeight,
+		}
+	}
+
+	if s.slicingLocked {
+		s.drawLockedField(field(0, 0), tr(msgMargin), s.margin)
+		if !s.sliceByCount {
+			s.drawLockedField(field(0, 1), tr(msgGridSize), s.gridSize)
+		}
+	} else {
+		s.margin = s.drawInputField(field(0, 0), tr(msgMargin), s.margin, 0, 10, defaultMargin)
+		if !s.sliceByCount {
+			maxGrid := gridSizeLimit
+			if s.sheet != nil {
+				maxGrid = s.gridCap
+			}
+			grid := field(0, 1)
+			s.gridSize = s.drawInputField(grid, tr(msgGridSize), s.gridSize, 1, maxGrid, min(defaultGridSize, maxGrid))
+			if maxGrid < gridSizeLimit {
+				drawText(trf(msgGridCapped, maxGrid, s.sheet.Texture.Width, s.sheet.Texture.Height), int32(grid.X), int32(grid.Y+grid.Height)+3, 10, s.theme.MutedText)
+			}
+		}
+	}
+	if byCount := drawCheckbox(field(0, 2), tr(msgByCount), s.sliceByCount); byCount != s.sliceByCount && !s.slicingLocked {
+		if byCount && s.columns == 0 {
+			s.columns, s.rows = max(s.slicing.cols, 1), max(s.slicing.rows, 1)
+		}
+		s.sliceByCount = byCount
+	}
+
+	s.selectionThickness = s.drawInputField(field(1, 0), tr(msgOutlinePx), s.selectionThickness, 1, 6, defaultSelectionThickness)
+	if drawSwatch(field(1, 1), tr(msgOutlineColor), s.selectionColor()) {
+		from := s.selectionAccent
+		s.selectionAccent = (s.selectionAccent + 1) % len(s.theme.Accents)
+		to := s.selectionAccent
+		s.record(edit{
+			desc: tr(msgOutlineColor),
+			undo: func(s *UIState) { s.selectionAccent = from },
+			redo: func(s *UIState) { s.selectionAccent = to },
+		})
+	}
+	s.exportScale = s.drawInputField(field(1, 2), tr(msgExportScale), s.exportScale, 1, 8, defaultExportScale)
+
+	s.atlasTrim = drawCheckbox(field(2, 0), tr(msgAtlasTrim), s.atlasTrim)
+	s.alphaTest = drawCheckbox(field(2, 1), tr(msgAlphaTest), s.alphaTest)
+	resetRect := field(2, 2)
+	resetRect.Width = resetWidth
+	if drawButton(resetRect, tr(msgResetOrder)) {
+		s.resetOrder()
+	}
+
+	s.zebra = drawCheckbox(field(3, 0), tr(msgZebraRows), s.zebra)
+	s.trueSize = drawCheckbox(field(3, 1), tr(msgTrueSize), s.trueSize)
+	if snap := drawCheckbox(field(3, 2), tr(msgSnapRows), s.snapRows); snap != s.snapRows {
+		s.snapRows = snap
+		s.snapScroll(cfg)
+	}
+
+	s.fontSpacing = s.drawInputField(field(4, 0), tr(msgFontSpacing), s.fontSpacing, 0, 16, defaultFontSpacing)
+	s.fontBaseline = s.drawInputField(field(4, 1), tr(msgFontBaseline), s.fontBaseline, 0, 64, defaultFontBaseline)
+	s.uiScale = s.drawInputField(field(4, 2), tr(msgTextScale), s.uiScale, 100, 200, defaultUIScale)
+
+	if group := drawCheckbox(field(5, 0), tr(msgGroupPrefix), s.groupPrefix); group != s.groupPrefix {
+		s.setGroupByPrefix(group)
+	}
+	s.labelOverlay = drawCheckbox(field(5, 1), tr(msgLabelOverlay), s.labelOverlay)
+	if mipmaps := drawCheckbox(field(5, 2), tr(msgMipmaps), s.mipmaps); mipmaps != s.mipmaps {
+		s.toggleMipmaps()
+	}
+
+	s.stripSpacing = s.drawInputField(field(6, 0), tr(msgStripSpacing), s.stripSpacing, 0, 64, defaultStripSpacing)
+	s.stripVertical = drawCheckbox(field(6, 1), tr(msgStripVertical), s.stripVertical)
+	s.stripTrim = drawCheckbox(field(6, 2), tr(msgStripTrim), s.stripTrim)
+
+	naming := field(7, 0)
+	naming.Width = namingWidth
+	drawText(tr(msgExportNames), int32(naming.X), int32(naming.Y-15), 10, s.theme.Text)
+	if drawButton(naming, s.exportNamingLabel()) {
+		s.cycleExportNaming()
+	}
+	for i := range s.presets {
+		preset := field(7, 1+i)
+		preset.Width = presetWidth
+		s.drawPresetButton(preset, i)
+	}
+
+	if alpha := s.drawInputField(field(8, 0), tr(msgAlphaThreshold), s.alphaThreshold, 0, 255, 0); alpha != s.alphaThreshold {
+		s.record(alphaThresholdEdit(s.alphaThreshold, alpha))
+		s.setAlphaThreshold(alpha)
+	}
+	if locked := drawCheckbox(field(8, 1), tr(msgLockSlicing), s.slicingLocked); locked != s.slicingLocked {
+		s.toggleSlicingLock()
+	}
+	if rtl := drawCheckbox(field(8, 2), tr(msgRightToLeft), s.rightToLeft); rtl != s.rightToLeft {
+		s.toggleRightToLeft()
+	}
+
+	s.contactLabels = drawCheckbox(field(9, 0), tr(msgContactLabels), s.contactLabels)
+	s.contactGrid = drawCheckbox(field(9, 1), tr(msgContactGrid), s.contactGrid)
+	index := field(9, 2)
+	drawText(tr(msgIndexBase), int32(index.X), int32(index.Y-15), 10, s.theme.Text)
+	if drawButton(index, s.indexBaseLabel()) {
+		s.cycleIndexBase()
+	}
+
+	guess := field(10, 0)
+	drawText(tr(msgBackground), int32(guess.X), int32(guess.Y-15), 10, s.theme.Text)
+	if drawButton(guess, tr(msgGuessBackground)) {
+		s.guessBackground()
+	}
+	if tolerance := s.drawInputField(field(10, 1), tr(msgKeyTolerance), s.keyTolerance, 0, 128, defaultKeyTolerance); tolerance != s.keyTolerance {
+		s.keyTolerance = tolerance
+		s.refreshBackgroundKey()
+	}
+	s.silhouette = drawCheckbox(field(10, 2), tr(msgSilhouettes), s.silhouette)
+
+	if drawSwatch(field(11, 0), tr(msgSilhouetteColor), s.silhouetteColor()) {
+		s.silhouetteInk = (s.silhouetteInk + 1) % len(silhouetteColors)
+	}
+	if drawSwatch(field(11, 1), tr(msgSilhouetteBackground), s.silhouetteBackground()) {
+		s.silhouettePaper = (s.silhouettePaper + 1) % len(silhouetteBackgrounds)
+	}
+
+	s.measureGrid = drawCheckbox(field(12, 0), tr(msgMeasureGrid), s.measureGrid)
+	s.measurePitch = s.drawInputField(field(12, 1), tr(msgMeasurePitch), s.measurePitch, 1, 256, defaultMeasurePitch)
+	if drawSwatch(field(12, 2), tr(msgMeasureColor), s.measureColor()) {
+		s.measureInk = (s.measureInk + 1) % len(measureColors)
+	}
+
+	if s.sliceByCount && s.slicingLocked {
+		s.drawLockedField(field(13, 0), tr(msgColumns), s.columns)
+		s.drawLockedField(field(13, 1), tr(msgRows), s.rows)
+	} else if s.sliceByCount {
+		maxColumns, maxRows := int32(512), int32(512)
+		defColumns, defRows := int32(1), int32(1)
+		if s.sheet != nil {
+			maxColumns, maxRows = s.sheet.Texture.Width, s.sheet.Texture.Height
+			def := newSlicing(maxColumns, maxRows, defaultGridSize, defaultMargin)
+			defColumns, defRows = max(def.cols, 1), max(def.rows, 1)
+		}
+		s.columns = s.drawInputField(field(13, 0), tr(msgColumns), s.columns, 1, maxColumns, defColumns)
+		s.rows = s.drawInputField(field(13, 1), tr(msgRows), s.rows, 1, maxRows, defRows)
+	}
+
+	access := field(accessRow, 0)
+	drawText(tr(msgAccessibility), int32(access.X), int32(access.Y)-35, 10, s.theme.Text)
+	highContrast := drawCheckbox(access, tr(msgHighContrast), s.highContrast)
+	colorblind := drawCheckbox(field(accessRow, 1), tr(msgColorblindSafe), s.colorblind)
+	if highContrast != s.highContrast || colorblind != s.colorblind {
+		s.setTheme(highContrast, colorblind)
+	}
+
+	helpX := settingsRect.X + float32(panelWidth/2) - float32(helpWidth)/2
+	drawText(helpText, int32(helpX), int32(field(rows-1, 0).Y+30), 10, s.theme.MutedText)
+
+	if s.sheet != nil {
+		s.renderSlicingPreview(cfg, rl.Rectangle{
+			X:      settingsRect.X + 10,
+			Y:      field(rows-1, 0).Y + 65,
+			Width:  float32(panelWidth) - 20,
+			Height: float32(cfg.displaySize),
+		})
+	}
+
+	endClip()
+	mouseDisabled = disabled
+	if contentHeight > panelHeight {
+		s.drawSettingsScrollbar(view, float32(contentHeight))
+	}
+	if rl.CheckCollisionPointRec(mousePosition(), view) && !s.widgets.overField {
+		s.settingsScroll -= rl.GetMouseWheelMove() * settingsRowHeight
+		s.settingsScroll = max(min(s.settingsScroll, float32(contentHeight-panelHeight)), 0)
+	}
+
+	if oldMargin != s.margin {
+		s.record(settingEdit(msgMargin, func(s *UIState) *int32 { return &s.margin }, oldMargin, s.margin, true))
+	}
+	if oldGridSize != s.gridSize {
+		s.record(settingEdit(msgGridSize, func(s *UIState) *int32 { return &s.gridSize }, oldGridSize, s.gridSize, true))
+	}
+	if oldByCount != s.sliceByCount {
+		s.record(sliceModeEdit(oldByCount, s.sliceByCount, oldColumns, oldRows, s.columns, s.rows))
+	} else {
+		if oldColumns != s.columns {
+			s.record(settingEdit(msgColumns, func(s *UIState) *int32 { return &s.columns }, oldColumns, s.columns, true))
+		}
+		if oldRows != s.rows {
+			s.record(settingEdit(msgRows, func(s *UIState) *int32 { return &s.rows }, oldRows, s.rows, true))
+		}
+	}
+	if oldThickness != s.selectionThickness {
+		s.record(settingEdit(msgOutlinePx, func(s *UIState) *int32 { return &s.selectionThickness }, oldThickness, s.selectionThickness, false))
+	}
+	if oldScale != s.exportScale {
+		s.record(settingEdit(msgExportScale, func(s *UIState) *int32 { return &s.exportScale }, oldScale, s.exportScale, false))
+	}
+	if oldUIScale != s.uiScale {
+		s.record(settingEdit(msgTextScale, func(s *UIState) *int32 { return &s.uiScale }, oldUIScale, s.uiScale, false))
+	}
+	if oldSpacing != s.fontSpacing {
+		s.record(settingEdit(msgFontSpacing, func(s *UIState) *int32 { return &s.fontSpacing }, oldSpacing, s.fontSpacing, false))
+	}
+	if oldBaseline != s.fontBaseline {
+		s.record(settingEdit(msgFontBaseline, func(s *UIState) *int32 { return &s.fontBaseline }, oldBaseline, s.fontBaseline, false))
+	}
+	if oldStripSpacing != s.stripSpacing {
+		s.record(settingEdit(msgStripSpacing, func(s *UIState) *int32 { return &s.stripSpacing }, oldStripSpacing, s.stripSpacing, false))
+	}
+
+	if oldMargin != s.margin || oldGridSize != s.gridSize ||
+		oldByCount != s.sliceByCount || oldColumns != s.columns || oldRows != s.rows {
+		s.dirty = true
+		s.scheduleReload()
+	}
+	return view
+}
+
+// drawSettingsScrollbar draws a thin scrollbar down the right edge of view,
+// the visible part of the settings panel, showing how far it is scrolled.
+func (s *UIState) drawSettingsScrollbar(view rl.Rectangle, contentHeight float32) {
+	track := rl.Rectangle{X: view.X + view.Width - 6, Y: view.Y + 2, Width: 4, Height: view.Height - 4}
+	thumbHeight := max(track.Height*view.Height/contentHeight, 20)
+	thumbY := track.Y + s.settingsScroll/(contentHeight-view.Height)*(track.Height-thumbHeight)
+	rl.DrawRectangleRec(track, rl.ColorAlpha(s.theme.Background, 0.6))
+	rl.DrawRectangleRec(rl.Rectangle{X: track.X, Y: thumbY, Width: track.Width, Height: thumbHeight}, s.theme.MutedText)
+}
+
+// Opening and closing the settings panel slides it down from settingsSlide
+// pixels above its place while fading it in, over settingsTransition
+// seconds.
+const (
+	settingsTransition = 0.15
+	settingsSlide      = 24
+)
+
+// renderSettingsTransition advances the settings panel towards shown or
+// hidden, draws it while it is at all visible and returns where it is. The
+// panel doesn't take input until it has fully appeared, so a click meant for
+// the grid can't land on a field sliding in under the mouse.
+func (s *UIState) renderSettingsTransition(cfg Config) rl.Rectangle {
+	step := rl.GetFrameTime() / settingsTransition
+	if s.showSettings {
+		s.settingsShown = min(s.settingsShown+step, 1)
+	} else {
+		s.settingsShown = max(s.settingsShown-step, 0)
+	}
+	if s.settingsShown == 0 {
+		return rl.Rectangle{}
+	}
+	if s.settingsShown == 1 {
+		return s.renderSettings(cfg)
+	}
+
+	eased := 1 - (1-s.settingsShown)*(1-s.settingsShown)
+	disabled := mouseDisabled
+	mouseDisabled = true
+	rl.PushMatrix()
+	rl.Translatef(0, -settingsSlide*(1-eased), 0)
+	panel := s.renderSettings(cfg)
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Background, 1-eased))
+	rl.PopMatrix()
+	mouseDisabled = disabled
+	panel.Y -= settingsSlide * (1 - eased)
+	return panel
+}
+
+// renderSlicingPreview draws the first row of cells as they would be sliced
+// with the current margin and grid size, straight from the loaded texture, so
+// the effect of a change is visible without waiting on a reload.
+func (s *UIState) renderSlicingPreview(cfg Config, bounds rl.Rectangle) {
+	drawText(tr(msgPreview), int32(bounds.X), int32(bounds.Y-15), 10, s.theme.Text)
+
+	tex := s.sheet.Texture
+	slicing, _ := s.slicingFor(tex.Width, tex.Height)
+	if slicing.cols == 0 || slicing.rows == 0 {
+		drawText(tr(msgNoCellsFit), int32(bounds.X), int32(bounds.Y)+5, 10, s.theme.Error)
+		return
+	}
+
+	step := float32(cfg.displaySize + 4)
+	count := int32(bounds.Width / step)
+	if slicing.cols < count {
+		count = slicing.cols
+	}
+	for col := int32(0); col < count; col++ {
+		rect := slicing.cell(col, 0)
+		source := rl.Rectangle{X: float32(rect.X), Y: float32(rect.Y), Width: float32(rect.Width), Height: float32(rect.Height)}
+		dest := rl.Rectangle{X: bounds.X + float32(col)*step, Y: bounds.Y, Width: float32(cfg.displaySize), Height: float32(cfg.displaySize)}
+		if rect.Width != rect.Height {
+			// Non-square cells are fitted into the square rather than
+			// squashed.
+			scale := float32(cfg.displaySize) / float32(max(rect.Width, rect.Height))
+			w, h := float32(rect.Width)*scale, float32(rect.Height)*scale
+			dest = rl.Rectangle{X: dest.X + (dest.Width-w)/2, Y: dest.Y + (dest.Height-h)/2, Width: w, Height: h}
+		}
+		rl.DrawTexturePro(s.drawTexture(), s.texSource(source), dest, rl.Vector2{}, 0, rl.White)
+		rl.DrawRectangleLinesEx(dest, 1, s.theme.CellBorder)
+	}
+}
+
+func openFileDialog() string {
+	var cmd *exec.Cmd
+	sep := dialogSeparator
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", `POSIX path of (choose file with prompt "Choose a sprite sheet:" of type {"png","jpg","jpeg"})`)
+	case "linux":
+		cmd, sep = linuxDialog(
+			[]string{"--file-selection", "--file-filter=Images (*.png *.jpg *.jpeg)"},
+			[]string{"--getopenfilename", ".", "*.png *.jpg *.jpeg"})
+	default:
+		return ""
+	}
+
+	return runSingleDialog(cmd, sep)
+}
+
+// openFilesDialog asks for one or more sprite sheets.
+func openFilesDialog() []string {
+	var cmd *exec.Cmd
+	sep := dialogSeparator
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript",
+			"-e", `set chosen to ""`,
+			"-e", `repeat with f in (choose file with prompt "Choose sprite sheets:" of type {"png","jpg","jpeg"} with multiple selections allowed)`,
+			"-e", `set chosen to chosen & POSIX path of f & (ASCII character 30)`,
+			"-e", `end repeat`,
+			"-e", `chosen`)
+	case "linux":
+		cmd, sep = linuxDialog(
+			[]string{"--file-selection", "--multiple", "--separator=" + dialogSeparator, "--file-filter=Images (*.png *.jpg *.jpeg)"},
+			[]string{"--getopenfilename", ".", "*.png *.jpg *.jpeg", "--multiple", "--separate-output"})
+	default:
+		return nil
+	}
+
+	return runDialog(cmd, sep)
+}
+
+// nameParts splits a sprite name at its underscores.
+func nameParts(name string) []string {
+	return strings.Split(name, "_")
+}
+
+// namePart is one underscore separated part of a sprite name, with its
+// value if it is a number.
+type namePart struct {
+	text    string
+	num     int
+	numeric bool
+}
+
+// sortKey is a sprite name split into the parts sortNames compares.
+type sortKey struct {
+	name  string
+	parts []namePart
+}
+
+func newSortKey(name string) sortKey {
+	split := nameParts(name)
+	k := sortKey{name: name, parts: make([]namePart, len(split))}
+	for i, text := range split {
+		num, err := strconv.Atoi(text)
+		k.parts[i] = namePart{text: text, num: num, numeric: err == nil}
+	}
+	return k
+}
+
+// less reports whether k sorts before other. Parts are compared in turn,
+// as numbers when both are numeric and as text otherwise, and a name that
+// runs out of parts first sorts first.
+func (k sortKey) less(other sortKey) bool {
+	for i := 0; i < min(len(k.parts), len(other.parts)); i++ {
+		a, b := k.parts[i], other.parts[i]
+		if a.numeric && b.numeric {
+			if a.num != b.num {
+				return a.num < b.num
+			}
+		} else if a.text != b.text {
+			return a.text < b.text
+		}
+	}
+	return len(k.parts) < len(other.parts)
+}
@@ -0,0 +1,82 @@
+package viewer
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/ztkent/beam/resources"
+)
+
+// syntheticNames returns n sprite names in no particular order, mixing grid
+// cell names, animation frames with numeric suffixes and names without any.
+func syntheticNames(n int) []string {
+	r := rand.New(rand.NewPCG(1, 2))
+	names := make([]string, n)
+	for i := range names {
+		switch i % 4 {
+		case 0:
+			names[i] = fmt.Sprintf("%d_%d", i/100, i%100)
+		case 1:
+			names[i] = fmt.Sprintf("hero_walk_%d", i)
+		case 2:
+			names[i] = fmt.Sprintf("tile_%d_v%d", i%37, i)
+		default:
+			names[i] = fmt.Sprintf("icon%d", i)
+		}
+	}
+	r.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+	return names
+}
+
+func BenchmarkSortNames(b *testing.B) {
+	names := syntheticNames(10000)
+
+	b.Run("keys", func(b *testing.B) {
+		for b.Loop() {
+			sortNames(slices.Clone(names))
+		}
+	})
+	// Splitting both names on every comparison, as sorting did before the
+	// keys were worked out up front.
+	b.Run("split per comparison", func(b *testing.B) {
+		for b.Loop() {
+			sorted := slices.Clone(names)
+			sort.Slice(sorted, func(i, j int) bool {
+				return newSortKey(sorted[i]).less(newSortKey(sorted[j]))
+			})
+		}
+	})
+}
+
+// BenchmarkUpdateSpriteNames brings the grid of a 10k sprite sheet up to
+// date after a filter change, with the sorted names cached from the last
+// update and with the cache emptied first, as on a sheet cut differently.
+func BenchmarkUpdateSpriteNames(b *testing.B) {
+	names := syntheticNames(10000)
+	s := initUI()
+	s.currentFile = "bench.png"
+	s.sheet = &resources.SpriteSheet{Sprites: make(map[string]resources.Rectangle, len(names))}
+	s.filter = &spriteFilter{names: make(map[string]bool, len(names)/2)}
+	for i, name := range names {
+		s.sheet.Sprites[name] = resources.Rectangle{X: int32(i%100) * 16, Y: int32(i/100) * 16, Width: 16, Height: 16}
+		if i%2 == 0 {
+			s.filter.names[name] = true
+		}
+	}
+
+	b.Run("warm", func(b *testing.B) {
+		s.updateSpriteNames()
+		for b.Loop() {
+			s.updateSpriteNames()
+		}
+	})
+	b.Run("cold", func(b *testing.B) {
+		for b.Loop() {
+			s.names = nameCache{}
+			s.updateSpriteNames()
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package viewer
+
+import "fmt"
+
+// maxUndo caps how many edits the undo history keeps.
+const maxUndo = 100
+
+// edit is a reversible change to the viewer state. It stores only the small
+// diff needed to move the state back and forth.
+type edit struct {
+	desc string
+	undo func(s *UIState)
+	redo func(s *UIState)
+}
+
+// history holds the undo and redo stacks. It is cleared whenever a different
+// file is opened, since edits only make sense against the sheet they were
+// made on.
+type history struct {
+	done   []edit
+	undone []edit
+}
+
+// record pushes an edit that has already been applied.
+func (s *UIState) record(e edit) {
+	s.history.done = append(s.history.done, e)
+	if len(s.history.done) > maxUndo {
+		s.history.done = s.history.done[len(s.history.done)-maxUndo:]
+	}
+	s.history.undone = nil
+}
+
+// undo reverts the most recent edit.
+func (s *UIState) undo() {
+	n := len(s.history.done)
+	if n == 0 {
+		s.notify(msgNothingToUndo)
+		return
+	}
+
+	e := s.history.done[n-1]
+	s.history.done = s.history.done[:n-1]
+	e.undo(s)
+	s.history.undone = append(s.history.undone, e)
+	s.notify(msgUndone, e.desc)
+}
+
+// redo re-applies the most recently undone edit.
+func (s *UIState) redo() {
+	n := len(s.history.undone)
+	if n == 0 {
+		s.notify(msgNothingToRedo)
+		return
+	}
+
+	e := s.history.undone[n-1]
+	s.history.undone = s.history.undone[:n-1]
+	e.redo(s)
+	s.history.done = append(s.history.done, e)
+	s.notify(msgRedone, e.desc)
+}
+
+// sliceModeEdit records switching between slicing by grid size and by column
+// and row count, along with the counts filled in when count mode was entered.
+func sliceModeEdit(from, to bool, fromColumns, fromRows, toColumns, toRows int32) edit {
+	apply := func(byCount bool, columns, rows int32) func(s *UIState) {
+		return func(s *UIState) {
+			s.sliceByCount, s.columns, s.rows = byCount, columns, rows
+			s.dirty = true
+			s.reslice()
+		}
+	}
+	desc := tr(msgSliceByGrid)
+	if to {
+		desc = tr(msgSliceByCount)
+	}
+	return edit{
+		desc: desc,
+		undo: apply(from, fromColumns, fromRows),
+		redo: apply(to, toColumns, toRows),
+	}
+}
+
+// settingEdit records a change to a numeric setting, described by the
+// setting's label. Settings that affect slicing reslice the sheet when the
+// edit is undone or redone.
+func settingEdit(label msgID, field func(s *UIState) *int32, from, to int32, reslice bool) edit {
+	apply := func(value int32) func(s *UIState) {
+		return func(s *UIState) {
+			*field(s) = value
+			if reslice {
+				s.dirty = true
+				s.reslice()
+			}
+		}
+	}
+	return edit{
+		desc: fmt.Sprintf("%s %d → %d", tr(label), from, to),
+		undo: apply(from),
+		redo: apply(to),
+	}
+}
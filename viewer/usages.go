@@ -0,0 +1,267 @@
+package viewer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// usageRowHeight is the height of one name in the usage report's columns.
+const usageRowHeight = 16
+
+// quotedString matches Go style string literals: interpreted, raw and rune
+// quoted, the last being how some languages write strings.
+var quotedString = regexp.MustCompile("\"(?:[^\"\\\\\\n]|\\\\.)*\"|`[^`]*`|'(?:[^'\\\\\\n]|\\\\.)*'")
+
+// usageReport is the result of checking the sprite names a source file or
+// list refers to against the sheet. found and missing are in the order they
+// are first referenced, unused in display order.
+type usageReport struct {
+	source  string
+	found   []string
+	missing []string
+	unused  []string
+}
+
+// extractQuoted returns the contents of the string literals in text.
+func extractQuoted(text string) []string {
+	var refs []string
+	for _, lit := range quotedString.FindAllString(text, -1) {
+		value := lit[1 : len(lit)-1]
+		if lit[0] != '`' {
+			if unquoted, err := strconv.Unquote(`"` + value + `"`); err == nil {
+				value = unquoted
+			}
+		}
+		refs = append(refs, value)
+	}
+	return refs
+}
+
+// extractList splits a pasted list of names at whitespace, commas and
+// semicolons, dropping quotes around each name.
+func extractList(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	for i, f := range fields {
+		fields[i] = strings.Trim(f, "\"'`")
+	}
+	return fields
+}
+
+// checkUsages sorts refs into names the sheet has and names it is missing,
+// and lists the sheet's names that are never referenced. A source file has
+// plenty of strings that aren't sprite names, so unless strict is set, a
+// string only counts as a missing sprite when it shares its prefix with a
+// sprite of the sheet, as "walk_8" does with "walk_7".
+func (s *UIState) checkUsages(source string, refs []string, strict bool) *usageReport {
+	prefixes := make(map[string]bool)
+	for _, name := range s.spriteNames {
+		prefixes[namePrefix(name)] = true
+	}
+
+	r := &usageReport{source: source}
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if ref == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		_, ok := s.sheet.Sprites[ref]
+		switch {
+		case ok:
+			r.found = append(r.found, ref)
+		case strict || (!strings.ContainsAny(ref, " \t\n") && prefixes[namePrefix(ref)]):
+			r.missing = append(r.missing, ref)
+		}
+	}
+	for _, name := range s.spriteNames {
+		if !seen[name] {
+			r.unused = append(r.unused, name)
+		}
+	}
+	return r
+}
+
+// checkUsagesInFile asks for a source file and checks the sprite names
+// quoted in it.
+func (s *UIState) checkUsagesInFile() {
+	if s.sheet == nil {
+		s.notify(msgOpenSheetForUsages)
+		return
+	}
+	path := openTextFileDialog()
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		s.notify(msgUsagesReadFailed, err)
+		return
+	}
+	s.usages = s.checkUsages(filepath.Base(path), extractQuoted(string(data)), false)
+}
+
+// checkUsagesInClipboard checks a list of names pasted to the clipboard.
+// Quoted strings are taken if there are any, such as when a piece of code
+// was copied; otherwise every word is a name.
+func (s *UIState) checkUsagesInClipboard() {
+	if s.sheet == nil {
+		s.notify(msgOpenSheetForUsages)
+		return
+	}
+	text := rl.GetClipboardText()
+	if refs := extractQuoted(text); len(refs) > 0 {
+		s.usages = s.checkUsages(tr(msgClipboard), refs, false)
+		return
+	}
+	s.usages = s.checkUsages(tr(msgClipboard), extractList(text), true)
+}
+
+// String formats the report as text, one name per line under a heading for
+// each list.
+func (r *usageReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", r.source)
+	for _, list := range []struct {
+		heading string
+		names   []string
+	}{
+		{"found", r.found},
+		{"missing", r.missing},
+		{"unused", r.unused},
+	} {
+		fmt.Fprintf(&b, "\n# %s (%d)\n", list.heading, len(list.names))
+		for _, name := range list.names {
+			fmt.Fprintln(&b, name)
+		}
+	}
+	return b.String()
+}
+
+// exportUsages asks for a folder and writes the usage report there as text.
+func (s *UIState) exportUsages() {
+	dir := openDirectoryDialog()
+	if dir == "" {
+		return
+	}
+	base := strings.TrimSuffix(filepath.Base(s.currentFile), filepath.Ext(s.currentFile))
+	path := filepath.Join(dir, base+"-usages.txt")
+	if _, err := os.Stat(path); err == nil {
+		path = uniquePath(path)
+	}
+	if err := os.WriteFile(path, []byte(s.usages.String()), 0o644); err != nil {
+		s.notify(msgExportFailed, err)
+		return
+	}
+	s.notify(msgWroteUsages, path)
+}
+
+// renderUsages draws the usage report: the found, missing and unused names
+// side by side. Clicking a found name selects its sprite, or adds it to the
+// selection with Ctrl held.
+func (s *UIState) renderUsages(cfg Config) rl.Rectangle {
+	r := s.usages
+	panel := rl.Rectangle{
+		X:      20,
+		Y:      float32(cfg.headerHeight + 30),
+		Width:  float32(cfg.width - 40),
+		Height: float32(cfg.startY+cfg.viewportHeight-cfg.headerHeight) - 50,
+	}
+	rl.DrawRectangleRec(panel, rl.ColorAlpha(s.theme.Panel, 0.95))
+	rl.DrawRectangleLinesEx(panel, 1, s.theme.Text)
+	drawText(trf(msgUsagesIn, r.source), int32(panel.X)+10, int32(panel.Y)+10, 15, s.theme.Text)
+
+	columnWidth := (panel.Width - 20) / 3
+	top := panel.Y + 40
+	rows := max(int((panel.Height-40-60)/usageRowHeight), 1)
+	mouse := mousePosition()
+	columns := []struct {
+		heading msgID
+		names   []string
+		col     rl.Color
+	}{
+		{msgUsagesFound, r.found, s.theme.Text},
+		{msgUsagesMissing, r.missing, s.theme.Error},
+		{msgUsagesUnused, r.unused, s.theme.MutedText},
+	}
+	for c, column := range columns {
+		x := panel.X + 10 + float32(c)*columnWidth
+		drawText(trf(column.heading, len(column.names)), int32(x), int32(top), 10, s.theme.Text)
+		for i, name := range column.names {
+			y := top + float32(i+1)*usageRowHeight
+			if i == rows-1 && len(column.names) > rows {
+				drawText(trf(msgUsagesMore, len(column.names)-i), int32(x), int32(y), 10, s.theme.MutedText)
+				break
+			}
+			row := rl.Rectangle{X: x, Y: y - 2, Width: columnWidth - 10, Height: usageRowHeight}
+			col := column.col
+			if c == 0 && s.selected[name] {
+				col = s.selectionColor()
+			}
+			if c == 0 && rl.CheckCollisionPointRec(mouse, row) {
+				rl.DrawRectangleRec(row, rl.ColorAlpha(s.theme.CellBorder, 0.3))
+				if i := s.nameIndex(name); i >= 0 && rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+					s.clickCell(i)
+				}
+			}
+			drawText(elide(name, 10, columnWidth-10), int32(x), int32(y), 10, col)
+		}
+	}
+
+	buttonY := panel.Y + panel.Height - 35
+	closeWidth := buttonWidth(tr(msgClose), 90)
+	closeX := panel.X + panel.Width - 10 - closeWidth
+	exportWidth := buttonWidth(tr(msgExportList), 90)
+	selectWidth := buttonWidth(tr(msgSelectFound), 90)
+	if drawButton(rl.Rectangle{X: closeX - 20 - exportWidth - selectWidth, Y: buttonY, Width: selectWidth, Height: 25}, tr(msgSelectFound)) {
+		s.selected = make(map[string]bool, len(r.found))
+		for _, name := range r.found {
+			s.selected[name] = true
+		}
+	}
+	if drawButton(rl.Rectangle{X: closeX - 10 - exportWidth, Y: buttonY, Width: exportWidth, Height: 25}, tr(msgExportList)) {
+		s.exportUsages()
+	}
+	if drawButton(rl.Rectangle{X: closeX, Y: buttonY, Width: closeWidth, Height: 25}, tr(msgClose)) {
+		s.usages = nil
+	}
+	return panel
+}
+
+// nameIndex returns the index of the named sprite in display order, or -1.
+func (s *UIState) nameIndex(name string) int {
+	for i, n := range s.spriteNames {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// openTextFileDialog asks for a source or text file to read.
+func openTextFileDialog() string {
+	var cmd *exec.Cmd
+	sep := dialogSeparator
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", `POSIX path of (choose file with prompt "Choose a source file:")`)
+	case "linux":
+		cmd, sep = linuxDialog(
+			[]string{"--file-selection"},
+			[]string{"--getopenfilename", "."})
+	default:
+		return ""
+	}
+
+	return runSingleDialog(cmd, sep)
+}
@@ -0,0 +1,342 @@
+// Package viewer implements the sprite sheet viewer as a component that can be
+// embedded in any raylib program. The host owns the window and, once per
+// frame, calls Update to process input and Draw to render the viewer into a
+// rectangle of its choosing:
+//
+//	v := viewer.New(viewer.Options{})
+//	defer v.Close()
+//	if err := v.Load("sheet.png", viewer.Options{GridSize: 16}); err != nil {
+//		log.Println(err)
+//	}
+//	for !rl.WindowShouldClose() {
+//		v.Update()
+//		rl.BeginDrawing()
+//		v.Draw(rl.Rectangle{X: 0, Y: 0, Width: 800, Height: 600})
+//		rl.EndDrawing()
+//	}
+package viewer
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Options controls how sprite sheets are sliced and how the viewer behaves.
+// Zero fields keep the viewer's current value.
+type Options struct {
+	Margin   int32
+	GridSize int32
+	// TooltipDelay is how long the mouse rests on a thumbnail before its
+	// tooltip appears. The default is 400ms.
+	TooltipDelay time.Duration
+	// UIScale scales all text, from 1.0 to 2.0. The user can change it in
+	// the settings panel.
+	UIScale float32
+	// Font is a TTF or OTF font to draw text with, such as one embedded in
+	// the host program. FontFile names a font file to use instead. Without
+	// either, a common system font is looked for, and the bundled Fira Sans
+	// is used when none is found or the chosen font can't be loaded. They
+	// are only read by New.
+	Font     []byte
+	FontFile string
+	// Language selects the language of the interface, as an ISO 639-1 code
+	// such as "de" or a locale name such as "de_DE.UTF-8". By default it
+	// comes from the LC_ALL, LC_MESSAGES or LANG environment variables.
+	// Untranslated languages and strings fall back to English.
+	Language string
+	// Editor is the program "Edit in" opens the sheet with. By default the
+	// system's default application for the image is used.
+	Editor string
+	// Version is the host's version, shown in the About panel and copied
+	// with bug reports. By default the version Go embedded in the binary is
+	// used.
+	Version string
+	// WatchDir is a folder to watch: whenever a new image appears in it,
+	// the most recently modified one is opened.
+	WatchDir string
+	// TextureWarnMB is the texture size, in megabytes, above which loading
+	// a sheet is warned about. The default is 256.
+	TextureWarnMB int32
+	// PreviewDownscale shows sheets reduced by this factor, 2 or 4, to save
+	// GPU memory. Coordinates and exports still use the full-size sheet.
+	PreviewDownscale int32
+	// NoMipmaps turns off the mipmaps that keep large sheets from shimmering
+	// when shown small, which take time to build on weak GPUs.
+	NoMipmaps bool
+	// RecoveryFile is where the metadata of sheets with unsaved changes is
+	// written every few seconds, so it survives a crash. If a session that
+	// didn't close cleanly left one behind, restoring it is offered. It is
+	// removed by Close. Empty turns recovery off. It is only read by New.
+	RecoveryFile string
+	// SettingsFile is where the slicing and view settings last used with
+	// each sheet are kept, so a sheet without a sidecar opens the way it
+	// was left. Sidecars take precedence, and sheets not found in it get
+	// the folder default or the current settings as before. It is read by
+	// New and written by Close. Empty turns this off.
+	SettingsFile string
+	// Verbose logs how long each phase of loading or reslicing a sheet
+	// took, with the standard log package.
+	Verbose bool
+}
+
+// Viewer is an embeddable sprite sheet viewer. Its methods must be called
+// from the goroutine that owns the raylib window.
+type Viewer struct {
+	// state is the current tab, tabs[active].
+	state   *UIState
+	tabs    []*UIState
+	active  int
+	closing bool
+	compact bool
+	cfg     Config
+	bounds  rl.Rectangle
+	// recoveryFile is Options.RecoveryFile. recoveryAt is when it is next
+	// written, recoveryData what was last written to it and recoveryOnDisk
+	// whether there is one to remove.
+	recoveryFile   string
+	recoveryAt     float64
+	recoveryData   []byte
+	recoveryOnDisk bool
+}
+
+// New returns a viewer with no sheet loaded. The raylib window must already
+// be open.
+func New(opts Options) *Viewer {
+	v := &Viewer{state: initUI(), cfg: initConfig()}
+	v.tabs = []*UIState{v.state}
+	switch {
+	case opts.Font != nil:
+		v.state.fontSource = fontSource{data: opts.Font}
+	case opts.FontFile != "":
+		v.state.fontSource = fontSource{path: opts.FontFile}
+	default:
+		v.state.fontSource = systemFont()
+	}
+	v.state.updateFonts()
+	v.apply(opts)
+	if opts.SettingsFile != "" {
+		v.state.memory = loadSheetMemory(opts.SettingsFile)
+	}
+	if opts.RecoveryFile != "" {
+		v.recoveryFile = opts.RecoveryFile
+		v.state.recovery, v.recoveryOnDisk = loadRecovery(opts.RecoveryFile)
+	}
+	return v
+}
+
+func (v *Viewer) apply(opts Options) {
+	if opts.Margin != 0 {
+		v.state.margin = opts.Margin
+	}
+	if opts.GridSize != 0 {
+		v.state.gridSize = opts.GridSize
+	}
+	if opts.TooltipDelay != 0 {
+		v.state.tooltipDelay = float32(opts.TooltipDelay.Seconds())
+	}
+	if opts.UIScale != 0 {
+		v.state.uiScale = int32(rl.Clamp(opts.UIScale, 1, 2) * 100)
+	}
+	if opts.Language != "" {
+		v.state.messages = lookupCatalog(opts.Language)
+	}
+	if opts.Editor != "" {
+		v.state.editor = opts.Editor
+	}
+	if opts.Version != "" {
+		v.state.version = opts.Version
+	}
+	if opts.TextureWarnMB != 0 {
+		v.state.textureWarnMB = opts.TextureWarnMB
+	}
+	if opts.PreviewDownscale != 0 {
+		v.state.previewDownscale = opts.PreviewDownscale
+	}
+	if opts.NoMipmaps {
+		v.state.mipmaps = false
+	}
+	if opts.Verbose {
+		v.state.verbose = true
+	}
+	if opts.WatchDir != "" {
+		v.begin(v.bounds)
+		v.state.watchFolder(opts.WatchDir)
+		v.end()
+	}
+}
+
+// Load opens the sprite sheet at path in the current tab. A sidecar saved
+// next to the sheet takes precedence over opts. On failure the previously
+// loaded sheet stays current.
+func (v *Viewer) Load(path string, opts Options) error {
+	v.apply(opts)
+	v.begin(v.bounds)
+	defer v.end()
+	v.state.openFile(path)
+	if v.state.loadError != "" {
+		return errors.New(v.state.loadError)
+	}
+	return nil
+}
+
+// Update processes keyboard input and mouse wheel scrolling over the area the
+// viewer was last drawn into, and collects the results of background work.
+// Keyboard shortcuts are handled whenever Update runs, so a host that shares
+// the keyboard with other widgets should only call it while the viewer has
+// focus.
+//
+// Sheets in other tabs keep being watched and exported in the background.
+func (v *Viewer) Update() {
+	start := time.Now()
+	for _, tab := range v.tabs {
+		tab.perf = frameStats{}
+	}
+	v.state.updateFonts()
+	v.cfg.labelOverlay = v.state.labelOverlay
+	v.cfg.cellAspect = v.state.thumbAspect
+	v.begin(v.contentBounds())
+	v.state.handleInput(v.layout())
+	v.handleTabRequest()
+	v.updateClosing()
+	v.end()
+
+	v.begin(v.contentBounds())
+	v.state.pollReload()
+	for _, tab := range v.tabs {
+		tab.pollWatch(false)
+		tab.pollEditWatch()
+		tab.pollExport()
+	}
+	v.end()
+
+	v.state.tabsMemory = 0
+	for _, tab := range v.tabs {
+		v.state.tabsMemory += tab.textureMemory()
+	}
+	v.state.tabCount = len(v.tabs)
+	v.updateRecovery()
+	v.state.perf.update = time.Since(start)
+}
+
+// contentBounds returns the part of the viewer's area the current tab is
+// drawn into, below the tab strip when there is one.
+func (v *Viewer) contentBounds() rl.Rectangle {
+	bounds := v.bounds
+	if len(v.tabs) > 1 && !v.compact {
+		bounds.Y += tabBarHeight
+		bounds.Height -= tabBarHeight
+	}
+	return bounds
+}
+
+// Draw renders the viewer into bounds. Clicks and hovering are only handled
+// inside bounds, and nothing is drawn outside it. It must be called between
+// rl.BeginDrawing and rl.EndDrawing.
+func (v *Viewer) Draw(bounds rl.Rectangle) {
+	v.bounds = bounds
+	rl.BeginScissorMode(int32(bounds.X), int32(bounds.Y), int32(bounds.Width), int32(bounds.Height))
+	if len(v.tabs) > 1 && !v.compact {
+		v.begin(bounds)
+		rl.PushMatrix()
+		rl.Translatef(bounds.X, bounds.Y, 0)
+		v.renderTabs(bounds.Width)
+		rl.PopMatrix()
+		v.end()
+	}
+
+	content := v.contentBounds()
+	v.cfg.resize(int32(content.Width), int32(content.Height))
+	s := v.state
+	v.cfg.labelOverlay = s.labelOverlay
+	v.cfg.cellAspect = s.thumbAspect
+
+	v.begin(content)
+	rl.PushMatrix()
+	rl.Translatef(content.X, content.Y, 0)
+
+	rl.DrawRectangle(0, 0, v.cfg.width, v.cfg.height, s.theme.Background)
+	if v.compact {
+		s.renderCompact(v.cfg)
+	} else {
+		// The grid and status bar don't see the mouse while it is over a
+		// panel drawn on top of them.
+		mouseDisabled = s.overLayer()
+		s.renderSprites(v.cfg)
+		s.renderStatusBar(v.cfg)
+		mouseDisabled = false
+		s.renderUI(v.cfg)
+		s.renderToasts(v.cfg)
+		s.renderTooltip(v.cfg)
+	}
+	if s.showPerf {
+		s.renderPerf(v.cfg)
+	}
+
+	rl.PopMatrix()
+	rl.EndScissorMode()
+	v.end()
+}
+
+// begin makes the viewer's area, text style and language current for
+// widgets.
+func (v *Viewer) begin(bounds rl.Rectangle) {
+	mouseOrigin = rl.Vector2{X: bounds.X, Y: bounds.Y}
+	clipBounds = bounds
+	uiText = textStyle{fonts: v.state.fonts, scale: float32(v.state.uiScale) / 100}
+	uiMessages = v.state.messages
+}
+
+// end restores the defaults set aside by begin.
+func (v *Viewer) end() {
+	mouseOrigin = rl.Vector2{}
+	clipBounds = rl.Rectangle{}
+	uiText = textStyle{scale: 1}
+	uiMessages = english
+}
+
+// RequestClose asks the viewer to close, as when the window's close button
+// is pressed. With unsaved changes the viewer asks the user first; Done
+// reports when it is ready to go.
+//
+// Each tab with unsaved changes is asked about in turn.
+func (v *Viewer) RequestClose() {
+	v.closing = true
+	v.closeNext()
+}
+
+// File returns the path of the sheet shown in the current tab, or "" if none
+// is loaded. Hosts can use it to title their window.
+func (v *Viewer) File() string {
+	if v.state.sheet == nil {
+		return ""
+	}
+	return v.state.currentFile
+}
+
+// Done reports whether the user has confirmed closing the viewer.
+func (v *Viewer) Done() bool {
+	for _, tab := range v.tabs {
+		if !tab.quit {
+			return false
+		}
+	}
+	return true
+}
+
+// Close cancels any running export and releases the loaded sheets and every
+// GPU resource the viewer created. Call it before closing the window.
+func (v *Viewer) Close() {
+	v.clearRecovery()
+	for _, tab := range v.tabs {
+		tab.rememberSheet()
+	}
+	if err := v.state.memory.save(); err != nil {
+		log.Println(err)
+	}
+	for _, tab := range v.tabs {
+		tab.Close()
+	}
+}
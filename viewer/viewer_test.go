@@ -0,0 +1,38 @@
+package viewer
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+// Quitting before any sheet was loaded used to close a resource manager
+// that was never created, and left the rest of what the viewer held behind.
+func TestCloseBeforeLoad(t *testing.T) {
+	v := &Viewer{state: initUI(), cfg: initConfig()}
+	v.tabs = []*UIState{v.state}
+	s := v.state
+	if s.rm != nil {
+		t.Fatal("resource manager created before a sheet was loaded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.export = &exportJob{cancel: cancel, progress: &exportProgress{}}
+	dragOutDir := t.TempDir()
+	s.dragOutDir = dragOutDir
+
+	v.Close()
+	if ctx.Err() == nil {
+		t.Error("running export not cancelled")
+	}
+	if s.export != nil {
+		t.Error("export still set after Close")
+	}
+	if _, err := os.Stat(dragOutDir); !os.IsNotExist(err) {
+		t.Error("drag-out files not removed")
+	}
+
+	// Closing again, as a host deferring Close after closing by hand does,
+	// must be safe too.
+	v.Close()
+}
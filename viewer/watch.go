@@ -0,0 +1,203 @@
+package viewer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// watchInterval is how often, in seconds, a watched folder is checked for
+// new sheets.
+const watchInterval = 1.0
+
+// folderWatch is the state of watch folder mode, in which the newest image
+// in dir is opened whenever one appears. latest is the modification time of
+// the newest image opened so far; only images newer than it are opened. A
+// paused watch opens nothing until it is resumed.
+type folderWatch struct {
+	dir       string
+	paused    bool
+	latest    time.Time
+	checkedAt float64
+	// held is the newest image, left unopened while the current sheet has
+	// unsaved changes. The user is told about each such image once.
+	held string
+}
+
+// isSheetFile reports whether name has the extension of an image the viewer
+// opens.
+func isSheetFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png", ".jpg", ".jpeg":
+		return true
+	}
+	return false
+}
+
+// newestSheet returns the most recently modified image in dir and its
+// modification time, or "" if there is none.
+func newestSheet(dir string) (string, time.Time, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var newest string
+	var newestTime time.Time
+	for _, entry := range entries {
+		if entry.IsDir() || !isSheetFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest, newestTime = filepath.Join(dir, entry.Name()), info.ModTime()
+		}
+	}
+	return newest, newestTime, nil
+}
+
+// watchFolder starts watching dir and opens the newest image already in it.
+func (s *UIState) watchFolder(dir string) {
+	if _, _, err := newestSheet(dir); err != nil {
+		s.notify(msgWatchFailed, err)
+		return
+	}
+	s.watch = &folderWatch{dir: dir}
+	s.notify(msgWatching, dir)
+	s.pollWatch(true)
+}
+
+// chooseWatchFolder asks for a folder to watch, or resumes a paused watch.
+func (s *UIState) chooseWatchFolder() {
+	if s.watch != nil && s.watch.paused {
+		s.resumeWatch()
+		return
+	}
+	if dir := openDirectoryDialog(); dir != "" {
+		s.watchFolder(dir)
+	}
+}
+
+// stopWatch leaves watch folder mode.
+func (s *UIState) stopWatch() {
+	if s.watch != nil {
+		s.watch = nil
+		s.notify(msgWatchStopped)
+	}
+}
+
+// pauseWatch stops a watch from opening new images, as when a sheet is
+// opened by hand and shouldn't be replaced behind the user's back.
+func (s *UIState) pauseWatch() {
+	if s.watch != nil && !s.watch.paused {
+		s.watch.paused = true
+		s.notify(msgWatchPaused)
+	}
+}
+
+// resumeWatch lets a paused watch open images again, starting with the
+// newest one if it appeared while paused.
+func (s *UIState) resumeWatch() {
+	if s.watch == nil || !s.watch.paused {
+		return
+	}
+	s.watch.paused = false
+	s.notify(msgWatching, s.watch.dir)
+	s.pollWatch(true)
+}
+
+// pollWatch opens the newest image of the watched folder if it is newer than
+// any opened before. The folder is read at most every watchInterval unless
+// now is set. An image that fails to load, as one still being written might,
+// is tried again on the next check. While the current sheet has unsaved
+// changes nothing is opened, so they are never thrown away unasked; the
+// newest image is opened once they are saved.
+func (s *UIState) pollWatch(now bool) {
+	w := s.watch
+	if w == nil || w.paused || (!now && rl.GetTime() < w.checkedAt+watchInterval) {
+		return
+	}
+	w.checkedAt = rl.GetTime()
+
+	path, modTime, err := newestSheet(w.dir)
+	if err != nil || path == "" || !modTime.After(w.latest) {
+		return
+	}
+	if s.dirty {
+		if w.held != path {
+			w.held = path
+			s.notify(msgWatchHeld, filepath.Base(path))
+		}
+		return
+	}
+	w.held = ""
+	s.openFile(path)
+	if s.currentFile == path && s.loadError == "" {
+		w.latest = modTime
+	}
+}
+
+// openChosenFile opens a sheet the user picked by hand, pausing watch folder
+// mode.
+func (s *UIState) openChosenFile(path string) {
+	s.pauseWatch()
+	s.openFile(path)
+}
+
+// folderMetaPath returns the file holding the default slicing settings for
+// sheets in dir that have no sidecar of their own.
+func folderMetaPath(dir string) string {
+	return filepath.Join(dir, ".viewer.json")
+}
+
+// loadFolderMeta reads the default slicing settings of dir. Only the grid
+// settings are used from it.
+func loadFolderMeta(dir string) (sheetMeta, bool) {
+	data, err := os.ReadFile(folderMetaPath(dir))
+	if err != nil {
+		return sheetMeta{}, false
+	}
+	var meta sheetMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return sheetMeta{}, false
+	}
+	return sheetMeta{Margin: meta.Margin, GridSize: meta.GridSize, Columns: meta.Columns, Rows: meta.Rows}, true
+}
+
+// saveFolderDefaults writes the current slicing settings as the defaults
+// for the current sheet's folder.
+func (s *UIState) saveFolderDefaults() {
+	if s.currentFile == "" {
+		s.notify(msgNothingToSave)
+		return
+	}
+	meta := s.currentMeta()
+	meta.Order, meta.Durations = nil, nil
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err == nil {
+		err = os.WriteFile(folderMetaPath(filepath.Dir(s.currentFile)), data, 0o644)
+	}
+	if err != nil {
+		s.notify(msgSaveFolderFailed, err)
+		return
+	}
+	s.notify(msgSavedFolderDefaults, filepath.Dir(s.currentFile))
+}
+
+// watchStatus returns the status bar text of watch folder mode, or "".
+func (s *UIState) watchStatus() string {
+	switch {
+	case s.watch == nil:
+		return ""
+	case s.watch.paused:
+		return trf(msgWatchPausedStatus, filepath.Base(s.watch.dir))
+	default:
+		return trf(msgWatchingStatus, filepath.Base(s.watch.dir))
+	}
+}
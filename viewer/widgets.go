@@ -0,0 +1,420 @@
+package viewer
+
+import (
+	"image/color"
+	"strconv"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// doubleClickTime is the longest gap between two clicks on the same target
+// that still counts as a double click, in seconds.
+const doubleClickTime = 0.35
+
+// flashDuration is how long a widget stays highlighted after being reset.
+const flashDuration = 0.3
+
+// scrubPixels is how far the mouse has to travel horizontally while dragging
+// on a numeric field to change its value by one step. Shift makes scrubbing
+// finer and Ctrl makes each step larger.
+const (
+	scrubPixels     = 6
+	scrubPixelsFine = 20
+	scrubCoarseStep = 5
+)
+
+// mouseOrigin is the screen position of the top-left corner of the area the
+// viewer is currently drawing into.
+var mouseOrigin rl.Vector2
+
+// clipBounds is the screen area the viewer is currently drawing into, which
+// nothing it draws may leave.
+var clipBounds rl.Rectangle
+
+// beginClip limits drawing to r, given relative to the viewer's area like the
+// mouse, and to the area itself.
+func beginClip(r rl.Rectangle) {
+	r.X += mouseOrigin.X
+	r.Y += mouseOrigin.Y
+	r = rl.GetCollisionRec(r, clipBounds)
+	rl.BeginScissorMode(int32(r.X), int32(r.Y), int32(r.Width), int32(r.Height))
+}
+
+// endClip ends the limit set by beginClip, leaving drawing clipped to the
+// viewer's area alone.
+func endClip() {
+	rl.BeginScissorMode(int32(clipBounds.X), int32(clipBounds.Y), int32(clipBounds.Width), int32(clipBounds.Height))
+}
+
+// mouseDisabled hides the mouse from widgets drawn while it is set, which
+// places it far outside any of them.
+var mouseDisabled bool
+
+// mousePosition returns the mouse position relative to the viewer's area, so
+// widgets can hit-test the same coordinates they draw at.
+func mousePosition() rl.Vector2 {
+	if mouseDisabled {
+		return rl.Vector2{X: -1e6, Y: -1e6}
+	}
+	m := rl.GetMousePosition()
+	return rl.Vector2{X: m.X - mouseOrigin.X, Y: m.Y - mouseOrigin.Y}
+}
+
+// clickTracker detects double clicks. Targets are identified by a key so
+// a click on one widget followed by a click on another is never a double.
+type clickTracker struct {
+	key string
+	at  float64
+}
+
+// click registers a click on key and reports whether it completes a double
+// click. A completed double click is consumed, so a third click starts over.
+func (c *clickTracker) click(key string) bool {
+	now := rl.GetTime()
+	if c.key == key && now-c.at <= doubleClickTime {
+		c.key = ""
+		return true
+	}
+	c.key, c.at = key, now
+	return false
+}
+
+// widgetState is the interaction state immediate-mode widgets keep between
+// frames.
+type widgetState struct {
+	clicks     clickTracker
+	flashLabel string
+	flashUntil float64
+
+	// scrubLabel names the numeric field being drag-scrubbed, if any.
+	scrubLabel      string
+	scrubStartX     float32
+	scrubStartValue int32
+	scrubMoved      bool
+
+	// focus names the widget that has the keyboard, if any: the numeric
+	// field last clicked, whether or not it is being typed into. The keys it
+	// handles go to it alone, and shortcuts on plain keys are held back
+	// while it has focus. A click elsewhere or Escape takes focus away, as
+	// does the widget no longer being drawn, such as when its panel closes.
+	focus      string
+	focusDrawn bool
+
+	// overField is set while a numeric field is drawn under the mouse, which
+	// takes the mouse wheel. wheelTaken keeps last frame's answer for input
+	// handled before widgets are drawn, so the grid doesn't scroll as well.
+	overField  bool
+	wheelTaken bool
+
+	// editLabel names the numeric field being typed into, if any. A click
+	// anywhere moves it to commitLabel so the field commits its text when it
+	// is next drawn, regardless of which widget took the click.
+	editLabel   string
+	editText    string
+	commitLabel string
+}
+
+// beginFrame settles widget interactions that end outside the widget itself:
+// a drag-scrub ends wherever the mouse is released, and a click anywhere
+// takes focus away and commits a field being typed into. The clicked widget
+// takes focus again when it is drawn.
+func (w *widgetState) beginFrame() {
+	if !rl.IsMouseButtonDown(rl.MouseLeftButton) {
+		w.scrubLabel = ""
+	}
+	if !w.focusDrawn {
+		w.blur()
+	}
+	w.focusDrawn = false
+	w.wheelTaken, w.overField = w.overField, false
+
+	w.commitLabel = ""
+	if rl.IsMouseButtonPressed(rl.MouseLeftButton) && w.focus != "" {
+		w.commitLabel = w.editLabel
+		w.blur()
+	}
+}
+
+// blur takes focus away from the focused widget, abandoning typed input.
+func (w *widgetState) blur() {
+	w.focus = ""
+	w.editLabel = ""
+}
+
+// focused reports whether a widget has the keyboard.
+func (w *widgetState) focused() bool {
+	return w.focus != ""
+}
+
+func drawButton(bounds rl.Rectangle, text string) bool {
+	mousePoint := mousePosition()
+	btnState := rl.ColorAlpha(rl.Gray, 0.6)
+	isHovered := rl.CheckCollisionPointRec(mousePoint, bounds)
+	isClicked := isHovered && rl.IsMouseButtonPressed(rl.MouseLeftButton)
+
+	if isHovered {
+		btnState = rl.ColorAlpha(rl.DarkGray, 0.6)
+	}
+
+	rl.DrawRectangleRec(bounds, btnState)
+	drawText(text, int32(bounds.X+bounds.Width/2-float32(measureText(text, 10))/2),
+		int32(bounds.Y+bounds.Height/2-5*uiText.scale), 10, rl.Black)
+
+	return isClicked
+}
+
+// buttonWidth returns the width of a button labelled text: the label plus
+// padding, but never narrower than minWidth, so buttons only grow for labels
+// that need it.
+func buttonWidth(text string, minWidth float32) float32 {
+	return max(minWidth, float32(measureText(text, 10)+20))
+}
+
+// drawBadge draws a short label such as a group number in the top-right
+// corner of bounds, on a box of the given color.
+func drawBadge(bounds rl.Rectangle, text string, col color.RGBA) {
+	width := measureText(text, 10) + 4
+	x := int32(bounds.X+bounds.Width) - width
+	rl.DrawRectangle(x, int32(bounds.Y), width, 12, col)
+	drawText(text, x+2, int32(bounds.Y)+1, 10, rl.White)
+}
+
+// drawSwatch draws a labelled color swatch and reports whether it was clicked.
+func drawSwatch(bounds rl.Rectangle, label string, col color.RGBA) bool {
+	drawText(label, int32(bounds.X), int32(bounds.Y-15), 10, rl.Black)
+	rl.DrawRectangleRec(bounds, col)
+	rl.DrawRectangleLinesEx(bounds, 1, rl.Gray)
+
+	return rl.CheckCollisionPointRec(mousePosition(), bounds) && rl.IsMouseButtonPressed(rl.MouseLeftButton)
+}
+
+// drawCheckbox draws a labelled toggle and returns its new value.
+func drawCheckbox(bounds rl.Rectangle, label string, value bool) bool {
+	drawText(label, int32(bounds.X), int32(bounds.Y-15), 10, rl.Black)
+
+	box := rl.Rectangle{X: bounds.X, Y: bounds.Y, Width: bounds.Height, Height: bounds.Height}
+	rl.DrawRectangleRec(box, rl.White)
+	rl.DrawRectangleLinesEx(box, 1, rl.Gray)
+	if value {
+		rl.DrawRectangleRec(rl.Rectangle{X: box.X + 4, Y: box.Y + 4, Width: box.Width - 8, Height: box.Height - 8}, rl.DarkGray)
+	}
+
+	if rl.CheckCollisionPointRec(mousePosition(), box) && rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+		value = !value
+	}
+	return value
+}
+
+// drawLockIcon draws a small padlock with its top-left corner at x, y.
+func drawLockIcon(x, y float32, col color.RGBA) {
+	rl.DrawRing(rl.Vector2{X: x + 5, Y: y + 5}, 2.5, 4, 180, 360, 8, col)
+	rl.DrawRectangleRec(rl.Rectangle{X: x + 1, Y: y + 5, Width: 8, Height: 6}, col)
+}
+
+// drawLockedField draws a numeric field that can't be edited, greyed out and
+// marked with a padlock. It still takes the mouse wheel while under the mouse,
+// so scrolling over it doesn't scroll the grid instead.
+func (s *UIState) drawLockedField(bounds rl.Rectangle, label string, value int32) {
+	w := &s.widgets
+	if w.focus == label {
+		w.blur()
+	}
+	drawText(label, int32(bounds.X), int32(bounds.Y-15), 10, rl.Black)
+	rl.DrawRectangleRec(bounds, rl.LightGray)
+	rl.DrawRectangleLinesEx(bounds, 1, rl.Gray)
+	drawText(strconv.Itoa(int(value)), int32(bounds.X+5), int32(bounds.Y+bounds.Height/2-5), 10, rl.DarkGray)
+	drawLockIcon(bounds.X+bounds.Width-14, bounds.Y+bounds.Height/2-6, rl.DarkGray)
+	if rl.CheckCollisionPointRec(mousePosition(), bounds) {
+		w.overField = true
+	}
+}
+
+// drawInputField draws a numeric field that can be scrubbed by pressing on it
+// and dragging sideways. Clicking the field focuses it for typed entry,
+// committed with Enter or by clicking elsewhere and abandoned with Escape;
+// the Up/Down keys adjust it for as long as it has focus, and a further
+// Escape lets go of the keyboard. The mouse wheel adjusts the field under
+// the mouse, in steps of scrubCoarseStep with Ctrl held. Double-clicking the
+// field resets it to def, and the field flashes briefly to confirm the reset.
+func (s *UIState) drawInputField(bounds rl.Rectangle, label string, value, min, max, def int32) int32 {
+	w := &s.widgets
+	clamp := func(v int32) int32 {
+		return int32(rl.Clamp(float32(v), float32(min), float32(max)))
+	}
+
+	if w.commitLabel == label {
+		if n, err := strconv.Atoi(w.editText); err == nil {
+			value = clamp(int32(n))
+		}
+		w.commitLabel = ""
+	}
+
+	drawText(label, int32(bounds.X), int32(bounds.Y-15), 10, rl.Black)
+
+	background := rl.White
+	if w.flashLabel == label && rl.GetTime() < w.flashUntil {
+		background = rl.Yellow
+	}
+	rl.DrawRectangleRec(bounds, background)
+
+	focused := w.focus == label
+	if focused {
+		w.focusDrawn = true
+	}
+	// The command palette takes the keyboard while it is open.
+	keys := focused && s.palette == nil
+	editing := w.editLabel == label
+	border := rl.Gray
+	if focused {
+		border = rl.Black
+	}
+	rl.DrawRectangleLinesEx(bounds, 1, border)
+
+	valueText := strconv.Itoa(int(value))
+	if editing {
+		valueText = w.editText
+		if int(rl.GetTime()*2)%2 == 0 {
+			valueText += "_"
+		}
+	}
+	textX := int32(bounds.X + 5)
+	textY := int32(bounds.Y + bounds.Height/2 - 5)
+	drawText(valueText, textX, textY, 10, rl.Black)
+
+	if keys && editing {
+		for ch := rl.GetCharPressed(); ch > 0; ch = rl.GetCharPressed() {
+			if ch >= '0' && ch <= '9' && len(w.editText) < 6 {
+				w.editText += string(ch)
+			}
+		}
+		if rl.IsKeyPressed(rl.KeyBackspace) && len(w.editText) > 0 {
+			w.editText = w.editText[:len(w.editText)-1]
+		}
+		if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) {
+			if n, err := strconv.Atoi(w.editText); err == nil {
+				value = clamp(int32(n))
+			}
+			w.editLabel = ""
+		} else if rl.IsKeyPressed(rl.KeyEscape) {
+			w.editLabel = ""
+		}
+	} else if keys && rl.IsKeyPressed(rl.KeyEscape) {
+		w.blur()
+	}
+	if keys {
+		step := int32(0)
+		if rl.IsKeyPressed(rl.KeyUp) {
+			step = 1
+		} else if rl.IsKeyPressed(rl.KeyDown) {
+			step = -1
+		}
+		if step != 0 {
+			value = clamp(value + step)
+			if editing {
+				w.editText = strconv.Itoa(int(value))
+			}
+		}
+	}
+
+	mousePoint := mousePosition()
+	if rl.CheckCollisionPointRec(mousePoint, bounds) {
+		w.overField = true
+		if wheel := rl.GetMouseWheelMove(); wheel != 0 {
+			step := int32(1)
+			if rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl) {
+				step = scrubCoarseStep
+			}
+			if wheel < 0 {
+				step = -step
+			}
+			value = clamp(value + step)
+			if editing {
+				w.editText = strconv.Itoa(int(value))
+			}
+		}
+
+		if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
+			w.focus = label
+			w.focusDrawn = true
+			if w.clicks.click(label) {
+				value = def
+				w.flashLabel = label
+				w.flashUntil = rl.GetTime() + flashDuration
+				w.editLabel = ""
+			} else {
+				w.editLabel = label
+				w.editText = strconv.Itoa(int(value))
+			}
+			w.scrubLabel = label
+			w.scrubStartX = mousePoint.X
+			w.scrubStartValue = value
+			w.scrubMoved = false
+		}
+	}
+
+	if w.scrubLabel == label && rl.IsMouseButtonDown(rl.MouseLeftButton) {
+		pixels, step := float32(scrubPixels), int32(1)
+		if rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift) {
+			pixels = scrubPixelsFine
+		} else if rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl) {
+			step = scrubCoarseStep
+		}
+		delta := int32((mousePoint.X - w.scrubStartX) / pixels)
+		if delta != 0 {
+			w.scrubMoved = true
+			w.editLabel = ""
+		}
+		if w.scrubMoved {
+			value = clamp(w.scrubStartValue + delta*step)
+		}
+	}
+
+	return value
+}
+
+// drawTextField draws a field holding a line of text. Clicking the field
+// focuses it for typing, and Escape lets go of the keyboard. The text is
+// limited to characters the interface font can show.
+func (s *UIState) drawTextField(bounds rl.Rectangle, label, value string) string {
+	w := &s.widgets
+	drawText(label, int32(bounds.X), int32(bounds.Y-15), 10, rl.Black)
+	rl.DrawRectangleRec(bounds, rl.White)
+
+	focused := w.focus == label
+	if focused {
+		w.focusDrawn = true
+	}
+	border := rl.Gray
+	if focused {
+		border = rl.Black
+	}
+	rl.DrawRectangleLinesEx(bounds, 1, border)
+
+	if focused && s.palette == nil {
+		for ch := rl.GetCharPressed(); ch > 0; ch = rl.GetCharPressed() {
+			if ch >= ' ' && ch <= 0xff && ch != 0x7f && len([]rune(value)) < 40 {
+				value += string(ch)
+			}
+		}
+		if rl.IsKeyPressed(rl.KeyBackspace) && value != "" {
+			r := []rune(value)
+			value = string(r[:len(r)-1])
+		}
+		if rl.IsKeyPressed(rl.KeyEscape) || rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) {
+			w.blur()
+		}
+	}
+
+	text := value
+	if focused && int(rl.GetTime()*2)%2 == 0 {
+		text += "_"
+	}
+	drawText(text, int32(bounds.X+5), int32(bounds.Y+bounds.Height/2-5), 10, rl.Black)
+
+	if rl.IsMouseButtonPressed(rl.MouseLeftButton) && rl.CheckCollisionPointRec(mousePosition(), bounds) {
+		w.focus = label
+		w.focusDrawn = true
+		w.editLabel = ""
+	}
+	return value
+}
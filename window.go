@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/ztkent/spritesheet-viewer/viewer"
+)
+
+// appTitle is the window title while no sheet is loaded.
+const appTitle = "Sprite Sheet Viewer"
+
+// windowTitle returns the window title for the sheet at path, such as
+// "hero.png — Sprite Sheet Viewer", so windows and screen recordings show
+// what is open.
+func windowTitle(path string) string {
+	if path == "" {
+		return appTitle
+	}
+	return filepath.Base(path) + " — " + appTitle
+}
+
+// moveToMonitor centers the window on monitor n, counted from 1 in the
+// order the system lists them.
+func moveToMonitor(n int) error {
+	count := rl.GetMonitorCount()
+	if n < 1 || n > count {
+		return fmt.Errorf("monitor %d not found, %d connected", n, count)
+	}
+	m := n - 1
+	pos := rl.GetMonitorPosition(m)
+	x := int(pos.X) + (rl.GetMonitorWidth(m)-rl.GetScreenWidth())/2
+	y := int(pos.Y) + (rl.GetMonitorHeight(m)-rl.GetScreenHeight())/2
+	rl.SetWindowPosition(max(x, int(pos.X)), max(y, int(pos.Y)))
+	return nil
+}
+
+// windowedGeometry is where the window was and how large it was before it
+// went fullscreen, so leaving fullscreen puts it back.
+type windowedGeometry struct {
+	x, y          int
+	width, height int
+}
+
+// toggleFullscreen switches between the window and borderless fullscreen on
+// the monitor the window is on. Borderless fullscreen keeps the monitor's
+// video mode, so switching is instant and works on any monitor, not just the
+// primary one.
+func (g *windowedGeometry) toggleFullscreen() {
+	if rl.IsWindowState(rl.FlagBorderlessWindowedMode) {
+		rl.ToggleBorderlessWindowed()
+		rl.SetWindowSize(g.width, g.height)
+		rl.SetWindowPosition(g.x, g.y)
+		return
+	}
+	pos := rl.GetWindowPosition()
+	g.x, g.y = int(pos.X), int(pos.Y)
+	g.width, g.height = rl.GetScreenWidth(), rl.GetScreenHeight()
+	rl.ToggleBorderlessWindowed()
+}
+
+// overlayFlags are the window states of overlay mode: no title bar or
+// borders, and kept above other windows.
+const overlayFlags = rl.FlagWindowUndecorated | rl.FlagWindowTopmost
+
+// overlayOpacities are the window opacities Shift+F10 steps through in
+// overlay mode.
+var overlayOpacities = []float32{1, 0.8, 0.6, 0.4}
+
+// overlay turns the window into a reference overlay floating over other
+// programs: borderless, always on top, see-through and showing the viewer
+// in compact mode. Without a title bar, the window is moved by dragging it
+// with the right mouse button.
+type overlay struct {
+	on      bool
+	opacity float32
+	// dragging is set while the window is dragged, and grab is where in it
+	// the mouse took hold of it.
+	dragging bool
+	grab     rl.Vector2
+}
+
+// toggle switches overlay mode on or off.
+func (o *overlay) toggle(v *viewer.Viewer) {
+	o.on = !o.on
+	if o.on {
+		rl.SetWindowState(overlayFlags)
+		rl.SetWindowOpacity(o.opacity)
+	} else {
+		rl.ClearWindowState(overlayFlags)
+		rl.SetWindowOpacity(1)
+		o.dragging = false
+	}
+	v.SetCompact(o.on)
+}
+
+// cycleOpacity makes the overlay window more see-through, wrapping around to
+// opaque.
+func (o *overlay) cycleOpacity() {
+	next := overlayOpacities[0]
+	for _, a := range overlayOpacities {
+		if a < o.opacity {
+			next = a
+			break
+		}
+	}
+	o.opacity = next
+	if o.on {
+		rl.SetWindowOpacity(o.opacity)
+	}
+}
+
+// update moves the window while it is dragged with the right mouse button.
+func (o *overlay) update() {
+	if !o.on {
+		return
+	}
+	switch {
+	case rl.IsMouseButtonPressed(rl.MouseRightButton):
+		o.dragging, o.grab = true, rl.GetMousePosition()
+	case !rl.IsMouseButtonDown(rl.MouseRightButton):
+		o.dragging = false
+	case o.dragging:
+		pos, m := rl.GetWindowPosition(), rl.GetMousePosition()
+		rl.SetWindowPosition(int(pos.X+m.X-o.grab.X), int(pos.Y+m.Y-o.grab.Y))
+	}
+}